@@ -33,6 +33,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/amp"
@@ -40,11 +42,16 @@ import (
 	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/help"
 	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/metrics"
 	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/route"
+	"github.com/cisco/arc/pkg/trace"
+	"github.com/cisco/arc/pkg/users"
 )
 
 func main() {
 	appName := path.Base(os.Args[0])
+	os.Args = metrics.ParseFlags(trace.ParseFlags(log.ParseFlags(os.Args)))
 
 	if len(os.Args) > 1 && os.Args[1] == "version" {
 		fmt.Printf("%s %s\n", appName, version)
@@ -68,6 +75,19 @@ func main() {
 		os.Exit(1)
 	}
 	defer log.Fini()
+	trace.Init(appName)
+	metrics.Init(appName)
+
+	aaa.InitAuditLog(filepath.Join(filepath.Dir(env.Lookup(strings.ToUpper(appName))), "audit.log"))
+	if os.Args[1] == "audit-log" {
+		if err := aaa.AuditLog(os.Args[2:]); err != nil {
+			msg.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	metrics.SetLabel("account", os.Args[1])
 
 	cfg, err := config.NewAmp(os.Args[1])
 	if err != nil {
@@ -80,6 +100,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	err = users.Init(env.Lookup("ROOT") + "/etc/arc/users.json")
+	if err != nil {
+		exit(err)
+	}
+
+	// Read only requests, such as the audits run by cron, don't need an
+	// ssh-agent. Without one they run as the unauthenticated os user.
+	req := route.NewRequest(os.Args[1], "", "")
+	req.Parse(os.Args[2:])
+	err = users.Authenticate(env.Lookup("SSH_USER"))
+	if err != nil {
+		if !aaa.ReadOnly(req) {
+			exit(err)
+		}
+		log.Info("%s, running the read only request as %s", err.Error(), aaa.UserId())
+	}
+
+	err = aaa.InitPolicy(env.Lookup("ROOT")+"/etc/arc/policy.json", users.TeamsOf)
+	if err != nil {
+		exit(err)
+	}
+
 	aaa.PreAccounting(os.Args)
 	a, err := amp.New(cfg)
 	if err != nil {
@@ -87,18 +129,32 @@ func main() {
 	}
 
 	result, err := a.Run()
+	log.Timing()
 	if err != nil {
 		exit(err)
 	}
 	aaa.PostAccounting(result)
 	if result != 0 {
+		aaa.CountAudits()
+		writeMetrics(result)
 		os.Exit(result)
 	}
 	aaa.PostAudit(appName)
+	writeMetrics(0)
 }
 
 func exit(err error) {
 	msg.Error(err.Error())
+	log.Timing()
+	aaa.CountAudits()
+	writeMetrics(1)
 	aaa.PostAccounting(1)
 	os.Exit(1)
 }
+
+// writeMetrics writes the metrics of the run when a metrics directory is given.
+func writeMetrics(result int) {
+	if err := metrics.Fini(result); err != nil {
+		msg.Warn("Unable to write the metrics: %s", err.Error())
+	}
+}
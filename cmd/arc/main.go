@@ -33,6 +33,8 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/arc"
@@ -40,13 +42,18 @@ import (
 	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/help"
 	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/metrics"
+	"github.com/cisco/arc/pkg/mirror"
 	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/route"
 	"github.com/cisco/arc/pkg/servertypes"
+	"github.com/cisco/arc/pkg/trace"
 	"github.com/cisco/arc/pkg/users"
 )
 
 func main() {
 	appname := path.Base(os.Args[0])
+	os.Args = metrics.ParseFlags(trace.ParseFlags(log.ParseFlags(os.Args)))
 
 	if len(os.Args) > 1 && os.Args[1] == "version" {
 		fmt.Printf("%s %s\n", appname, version)
@@ -70,8 +77,33 @@ func main() {
 		os.Exit(1)
 	}
 	defer log.Fini()
+	trace.Init(appname)
+	metrics.Init(appname)
+
+	aaa.InitAuditLog(filepath.Join(filepath.Dir(env.Lookup(strings.ToUpper(appname))), "audit.log"))
+	if os.Args[1] == "audit-log" {
+		if err := aaa.AuditLog(os.Args[2:]); err != nil {
+			msg.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// "arc approve 'token'" approves a request made in the token's datacenter.
+	datacenter := os.Args[1]
+	approving := datacenter == "approve"
+	if approving {
+		var err error
+		if datacenter, err = aaa.ApprovalDataCenter(os.Args[2]); err != nil {
+			fmt.Printf(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	log.SetDataCenter(datacenter)
+	metrics.SetLabel("datacenter", datacenter)
 
-	cfg, err := config.NewArc(os.Args[1])
+	cfg, err := config.NewArc(datacenter)
 	if err != nil {
 		fmt.Printf(err.Error())
 		os.Exit(1)
@@ -87,11 +119,43 @@ func main() {
 		exit(err)
 	}
 
+	// Read only requests, such as the audits run by cron, don't need an
+	// ssh-agent. Without one they run as the unauthenticated os user.
+	req := route.NewRequest(datacenter, "", "")
+	req.Parse(os.Args[2:])
+	err = users.Authenticate(env.Lookup("SSH_USER"))
+	if err != nil {
+		if approving || !aaa.ReadOnly(req) {
+			exit(err)
+		}
+		log.Info("%s, running the read only request as %s", err.Error(), aaa.UserId())
+	}
+
+	err = aaa.InitPolicy(env.Lookup("ROOT")+"/etc/arc/policy.json", users.TeamsOf)
+	if err != nil {
+		exit(err)
+	}
+	aaa.InitApproval(users.KeysOf)
+
+	if approving {
+		aaa.PreAccounting(os.Args)
+		if err := aaa.Approve(os.Args[2]); err != nil {
+			exit(err)
+		}
+		aaa.PostAccounting(0)
+		return
+	}
+
 	err = servertypes.Init()
 	if err != nil {
 		exit(err)
 	}
 
+	err = mirror.Init(cfg.Mirror)
+	if err != nil {
+		exit(err)
+	}
+
 	aaa.PreAccounting(os.Args)
 	a, err := arc.New(cfg)
 	if err != nil {
@@ -99,18 +163,32 @@ func main() {
 	}
 
 	result, err := a.Run()
+	log.Timing()
 	if err != nil {
 		exit(err)
 	}
 	aaa.PostAccounting(result)
 	if result != 0 {
+		aaa.CountAudits()
+		writeMetrics(result)
 		os.Exit(result)
 	}
 	aaa.PostAudit(appname)
+	writeMetrics(0)
 }
 
 func exit(err error) {
 	msg.Error(err.Error())
+	log.Timing()
+	aaa.CountAudits()
+	writeMetrics(1)
 	aaa.PostAccounting(1)
 	os.Exit(1)
 }
+
+// writeMetrics writes the metrics of the run when a metrics directory is given.
+func writeMetrics(result int) {
+	if err := metrics.Fini(result); err != nil {
+		msg.Warn("Unable to write the metrics: %s", err.Error())
+	}
+}
@@ -28,8 +28,15 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/metrics"
 	"github.com/cisco/arc/pkg/msg"
 	"github.com/cisco/arc/pkg/users"
 )
@@ -38,15 +45,32 @@ type Audit struct {
 	orphanedUsers []*users.User
 	orphanedTeams []*users.Team
 	rogueTeams    []*users.TeamAudit
+	expiringKeys  []*users.KeyExpiry
 }
 
+// defaultExpiringDays is how far ahead expiring ssh keys are reported.
+const defaultExpiringDays = 30
+
 func NewAudit() *Audit {
 	return &Audit{}
 }
 
-func (a *Audit) Run(arg string) error {
-	switch arg {
+func (a *Audit) Run(args []string) error {
+	switch args[0] {
 	case "users":
+		if len(args) > 1 && args[1] == "live" {
+			if len(args) < 3 {
+				return fmt.Errorf("Expected arguments: users live 'datacenter'")
+			}
+			metrics.SetLabel("command", "users live")
+			metrics.SetLabel("datacenter", args[2])
+			return a.live(args[2])
+		}
+		if len(args) > 1 && args[1] == "sync-ldap" {
+			metrics.SetLabel("command", "users sync-ldap")
+			return syncLDAP(args[2:])
+		}
+		metrics.SetLabel("command", "users")
 		var err error
 		err = aaa.NewAudit("User")
 		if err != nil {
@@ -57,6 +81,7 @@ func (a *Audit) Run(arg string) error {
 		if err != nil {
 			return nil
 		}
+		aaa.AuditBuffer["User"].CountFindings("orphaned", "user", len(a.orphanedUsers))
 		if a.orphanedUsers != nil {
 			for _, v := range a.orphanedUsers {
 				if v.Remove {
@@ -77,6 +102,7 @@ func (a *Audit) Run(arg string) error {
 		if err != nil {
 			return err
 		}
+		aaa.AuditBuffer["User"].CountFindings("orphaned", "team", len(a.orphanedTeams))
 		if a.orphanedTeams != nil {
 			for _, v := range a.orphanedTeams {
 				msg.Detail("- team: %s", v.Name)
@@ -87,16 +113,113 @@ func (a *Audit) Run(arg string) error {
 		if err != nil {
 			return err
 		}
+		aaa.AuditBuffer["User"].CountFindings("rogue", "team", len(a.rogueTeams))
 		if a.rogueTeams != nil {
 			for _, v := range a.rogueTeams {
 				msg.Detail("- team: %s, pod: %s", v.RogueTeam, v.RogueTeamPod)
 				aaa.AuditBuffer["User"].FreeFormAudit("- team: %s, pod: %s\n", v.RogueTeam, v.RogueTeamPod)
 			}
 		}
+
+		// Key Expiry Auditing
+		days, err := expiringDays(args[1:])
+		if err != nil {
+			return err
+		}
+		msg.Info("Find Keys Expiring Within %d Days", days)
+		aaa.AuditBuffer["User"].FreeFormAudit("\n> Find Keys Expiring Within %d Days\n", days)
+		a.expiringKeys = users.ExpiringKeys(time.Now(), days)
+		aaa.AuditBuffer["User"].CountFindings("expiring", "key", len(a.expiringKeys))
+		warnings := map[string][]string{}
+		for _, v := range a.expiringKeys {
+			msg.Detail("- %s", v)
+			aaa.AuditBuffer["User"].FreeFormAudit("- %s\n", v)
+			warnings[v.User] = append(warnings[v.User], v.String())
+		}
+		for user, lines := range warnings {
+			if err := aaa.Warning(user, "ssh keys of "+user+" expire soon, please rotate them", lines...); err != nil {
+				msg.Warn(err.Error())
+			}
+		}
 	}
 	return nil
 }
 
+// expiringDays parses the "expiring='days'" argument of the users audit.
+func expiringDays(args []string) (int, error) {
+	days := defaultExpiringDays
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "expiring=") {
+			continue
+		}
+		d, err := strconv.Atoi(strings.TrimPrefix(arg, "expiring="))
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("Expected expiring='days', got %q", arg)
+		}
+		days = d
+	}
+	return days, nil
+}
+
+// lint reports the problems found in the users file, failing if there are any.
+func lint(name string) error {
+	msg.Info("Lint %s", name)
+	problems, err := users.Lint(name)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		msg.Detail("- %s", p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problems found in %s", len(problems), name)
+	}
+	return nil
+}
+
+// syncLDAP writes the users.json proposed by syncing the current one from
+// LDAP, reporting the changes it makes. The "out=file" argument names the
+// proposed users.json, which defaults to the run directory.
+func syncLDAP(args []string) error {
+	root := env.Lookup("ROOT")
+	usersFile := root + "/etc/arc/users.json"
+	out := filepath.Join(env.Lookup("AUDIT"), "users.json")
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "out="):
+			out = strings.TrimPrefix(arg, "out=")
+		default:
+			return fmt.Errorf("Unknown sync-ldap argument %q", arg)
+		}
+	}
+
+	cfg, err := users.LoadLDAPConfig(root + "/etc/arc/ldap.json")
+	if err != nil {
+		return err
+	}
+	dir := users.NewLDAPDirectory(cfg)
+	msg.Info("Sync %s from LDAP", usersFile)
+	p, err := users.SyncLDAP(usersFile, cfg, dir)
+	if err != nil {
+		return err
+	}
+	for _, w := range p.Warnings {
+		msg.Warn("%s", w)
+	}
+	if len(p.Changes) == 0 {
+		msg.Detail("%s is in sync with LDAP", usersFile)
+		return nil
+	}
+	for _, c := range p.Changes {
+		msg.Detail("%s", c)
+	}
+	if err := ioutil.WriteFile(out, p.Data, 0644); err != nil {
+		return err
+	}
+	msg.Info("Proposed users.json with %d changes: %s", len(p.Changes), out)
+	return nil
+}
+
 func Help() {
 	help := `
   With the Audit Command Line tool you can audit the users for all the datacenters specified in 
@@ -107,8 +230,26 @@ func Help() {
   TOOL USAGE
     The options of how to use the tool are as follows:
   ----------------------------------------------------
-  > Auditing Users
-      audit users
+  > Auditing Users, including the ssh keys that have expired or expire within
+    the given number of days, 30 by default. The owners of the keys are warned
+    through the warning notification sinks configured in
+    /etc/arc/notifications.json, or the audit spark room without it.
+      audit users [expiring='days']
+
+  > Auditing the users on the running instances of a datacenter, reporting
+    accounts that aren't in the pod's teams, missing users, stale keys and
+    wrong sudo grants
+      audit users live 'datacenter'
+
+  > Linting the users file, reporting duplicate users, groups or teams,
+    malformed, weak or shared ssh keys, undefined groups, users or teams and
+    circular team nesting. Exits non-zero if any problem is found.
+      audit users lint
+
+  > Syncing the users file from the LDAP server configured in
+    /etc/arc/ldap.json, writing the proposed users file to the run directory,
+    or to out, and listing its changes.
+      audit users sync-ldap [out='file']
 `
 	fmt.Println(help)
 }
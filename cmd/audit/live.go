@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/arc"
+	"github.com/cisco/arc/pkg/command"
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/mirror"
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/resource"
+	"github.com/cisco/arc/pkg/route"
+	"github.com/cisco/arc/pkg/servertypes"
+	"github.com/cisco/arc/pkg/users"
+)
+
+// liveAudit is the name of the aaa audit the live users findings are sent to.
+const liveAudit = "Live User"
+
+// live reads the accounts of every running instance in the datacenter and
+// compares them with the users of the teams of the instance's pod.
+func (a *Audit) live(dc string) error {
+	cfg, err := config.NewArc(dc)
+	if err != nil {
+		return err
+	}
+	if err := servertypes.Init(); err != nil {
+		return err
+	}
+	if err := mirror.Init(cfg.Mirror); err != nil {
+		return err
+	}
+	ar, err := arc.New(cfg)
+	if err != nil {
+		return err
+	}
+	req := route.NewRequest(dc, aaa.UserId(), time.Now().UTC().String())
+	if resp := ar.Route(req.Clone(route.Load)); resp != route.OK {
+		return fmt.Errorf("Failed to load datacenter %s", dc)
+	}
+	if ar.DataCenter() == nil || cfg.DataCenter == nil || cfg.DataCenter.Compute == nil || cfg.DataCenter.Compute.Clusters == nil {
+		return fmt.Errorf("Datacenter %s has no compute", dc)
+	}
+	if err := aaa.NewAudit(liveAudit); err != nil {
+		return err
+	}
+	audit := aaa.AuditBuffer[liveAudit]
+	grants, err := users.LoadGrants(grantsFile(dc))
+	if err != nil {
+		return err
+	}
+
+	clusters := ar.DataCenter().Compute().Clusters()
+	failed := 0
+	for _, c := range *cfg.DataCenter.Compute.Clusters {
+		for _, pc := range *c.Pods {
+			p := clusters.FindPod(pc.Name())
+			if p == nil {
+				continue
+			}
+			for _, i := range p.Instances().GetInstances() {
+				if i.State() != "running" {
+					continue
+				}
+				if err := auditInstance(audit, p, grants.ActiveFor(p.Name(), time.Now()), i); err != nil {
+					msg.Error(err.Error())
+					failed++
+				}
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Unable to audit the users of %d instances", failed)
+	}
+	return nil
+}
+
+// grantsFile returns the name of the datacenter's grant store, which arc keeps
+// with its run directories.
+func grantsFile(dc string) string {
+	dir := os.Getenv("ARC")
+	if dir == "" {
+		dir = os.Getenv("HOME") + "/.arc"
+	}
+	return filepath.Join(dir, "grants", dc+".json")
+}
+
+// auditInstance reads the accounts of the instance and records the
+// differences from the users of the pod's teams and grants.
+func auditInstance(audit *aaa.Audit, p resource.Pod, grants []*users.Grant, i resource.Instance) error {
+	msg.Info("Live Users Audit: %s", i.Name())
+	output, err := command.RunRemoteWithOutput(command.Command{
+		Instance: i,
+		Desc:     "read accounts",
+		Src:      "/usr/lib/arc/users/read_accounts",
+		Dest:     "/tmp/arc_read_accounts",
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to read the accounts of %s: %s\n%s", i.Name(), err.Error(), output)
+	}
+	h, err := users.ParseHost(i.Name(), output)
+	if err != nil {
+		return err
+	}
+	findings, err := users.AuditHost(p.Teams(), grants, i.RootUser(), h)
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		msg.Detail("No Differences Found")
+	}
+	for _, f := range findings {
+		msg.Detail("- %s: %s", f.Kind, f.Detail)
+		t := aaa.Mismatched
+		switch f.Kind {
+		case users.RogueAccount:
+			t = aaa.Deployed
+		case users.MissingUser:
+			t = aaa.Configured
+		}
+		audit.Record(t, &aaa.Finding{
+			ResourceType: "user",
+			Name:         f.User,
+			Id:           i.Name(),
+			Attribute:    f.Kind,
+			Message:      f.Detail,
+		})
+	}
+	return nil
+}
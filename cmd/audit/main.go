@@ -33,17 +33,22 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
 	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/metrics"
 	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/trace"
 	"github.com/cisco/arc/pkg/users"
 )
 
 func main() {
 	appname := path.Base(os.Args[0])
+	os.Args = metrics.ParseFlags(trace.ParseFlags(log.ParseFlags(os.Args)))
 
 	if len(os.Args) > 1 && os.Args[1] == "version" {
 		fmt.Printf("%s %s\n", appname, version)
@@ -64,33 +69,76 @@ func main() {
 		os.Exit(1)
 	}
 	defer log.Fini()
+	trace.Init(appname)
+	metrics.Init(appname)
 
-	notifications := &config.Notifications{
-		Spark: &config.Spark{
-			Rooms: map[string]string{
-				"accounting": "44a5ce00-6d69-11e7-ba3f-671fa0a6f5e1",
-				"audit":      "e6bb8e90-881d-11e7-a4c0-8f2917788fe6",
-			},
-		},
+	usersFile := env.Lookup("ROOT") + "/etc/arc/users.json"
+
+	// Linting checks the users file as written, before it is loaded, and
+	// neither accounts nor notifies so it can gate changes to the file.
+	if len(os.Args) > 2 && os.Args[1] == "users" && os.Args[2] == "lint" {
+		if err := lint(usersFile); err != nil {
+			msg.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	aaa.InitAuditLog(filepath.Join(filepath.Dir(env.Lookup(strings.ToUpper(appname))), "audit.log"))
+
+	notifications, err := loadNotifications(env.Lookup("ROOT") + "/etc/arc/notifications.json")
+	if err != nil {
+		exit(err)
 	}
 	if err := aaa.Init(notifications); err != nil {
-		msg.Error(err.Error())
-		os.Exit(1)
+		exit(err)
 	}
-	err := users.Init(env.Lookup("ROOT") + "/etc/arc/users.json")
-	if err != nil {
-		msg.Error(err.Error())
-		os.Exit(1)
+	if err := users.Init(usersFile); err != nil {
+		exit(err)
 	}
 
 	aaa.PreAccounting(os.Args)
 	a := NewAudit()
 
-	err = a.Run(os.Args[1])
+	err = a.Run(os.Args[1:])
+	log.Timing()
 	if err != nil {
-		msg.Error(err.Error())
-		os.Exit(1)
+		exit(err)
 	}
 	aaa.PostAccounting(0)
 	aaa.PostAudit(appname)
+	writeMetrics(0)
+}
+
+// loadNotifications reads the notification sinks from the named file. Without
+// the file accounting and audits go to the default spark rooms, and warnings
+// to the audit room.
+func loadNotifications(name string) (*config.Notifications, error) {
+	if _, err := os.Stat(name); os.IsNotExist(err) {
+		return &config.Notifications{
+			Spark: &config.Spark{
+				Rooms: map[string]string{
+					"accounting": "44a5ce00-6d69-11e7-ba3f-671fa0a6f5e1",
+					"audit":      "e6bb8e90-881d-11e7-a4c0-8f2917788fe6",
+					"warning":    "e6bb8e90-881d-11e7-a4c0-8f2917788fe6",
+				},
+			},
+		}, nil
+	}
+	return config.NewNotifications(name)
+}
+
+func exit(err error) {
+	msg.Error(err.Error())
+	log.Timing()
+	aaa.CountAudits()
+	writeMetrics(1)
+	os.Exit(1)
+}
+
+// writeMetrics writes the metrics of the run when a metrics directory is given.
+func writeMetrics(result int) {
+	if err := metrics.Fini(result); err != nil {
+		msg.Warn("Unable to write the metrics: %s", err.Error())
+	}
 }
@@ -31,40 +31,101 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/spark"
 )
 
 type Spark struct {
 	Token string            `json:"token"`
 	Rooms map[string]string `json:"rooms"`
+
+	file     string
+	fallback *string
 }
 
-// New fills the Spark struct with the information provided in the configuration file
+// New fills the Spark struct with the information provided in the configuration file.
+// A missing configuration file is allowed so the aliases can be created with the tool.
+// When the file has no token the token used by arc's notifications is used instead,
+// taken from SPARK_TOKEN or decrypted from the arc repo given by ARC_ROOT.
 func NewSpark() (*Spark, error) {
-	file := os.Getenv("HOME") + "/.spark"
-
-	data, err := ioutil.ReadFile(file)
-	if err != nil {
-		return nil, err
+	s := &Spark{
+		Rooms: map[string]string{},
+		file:  os.Getenv("HOME") + "/.spark",
 	}
 
-	s := &Spark{}
-	if err := json.Unmarshal(data, s); err != nil {
+	data, err := ioutil.ReadFile(s.file)
+	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, err
+		}
+	}
+	if s.Rooms == nil {
+		s.Rooms = map[string]string{}
+	}
 	return s, nil
 }
 
+// token returns the token of the configuration file, or the token used by
+// arc's notifications, which isn't saved to the file. The latter is only
+// looked up when it is needed, since it may have to be decrypted.
+func (s *Spark) token() string {
+	if s.Token != "" {
+		return s.Token
+	}
+	if s.fallback == nil {
+		token := env.SparkToken(os.Getenv("ARC_ROOT"))
+		s.fallback = &token
+	}
+	return *s.fallback
+}
+
+// Save writes the configuration file, readable and writeable only by the user
+// since it holds the token.
+func (s *Spark) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.file + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.file)
+}
+
 // Run returns a nonzero value when it fails. Run also is where the command is parsed to
 // what action to take whether it be sending or getting messages.
 func (s *Spark) Run(args []string) error {
-	room := s.Find(args[1])
-	if room == "" {
-		return fmt.Errorf("Room alias not found")
+	switch args[1] {
+	case "rooms":
+		return s.rooms()
+	case "alias":
+		return s.alias(args[2:])
+	case "send":
+		if len(args) < 3 {
+			return fmt.Errorf("Usage: spark send 'alias' [message...]")
+		}
+		return s.send(args[2], "", args[3:])
+	case "reply":
+		if len(args) < 4 {
+			return fmt.Errorf("Usage: spark reply 'alias' 'message id' [message...]")
+		}
+		return s.send(args[2], args[3], args[4:])
+	case "attach":
+		if len(args) < 4 {
+			return fmt.Errorf("Usage: spark attach 'alias' 'filename' [message...]")
+		}
+		return s.attach(args[2], args[3], args[4:])
 	}
-	client, err := spark.New(s.Token, room, spark.Html)
+
+	client, err := s.client(args[1])
 	if err != nil {
 		return err
 	}
@@ -114,6 +175,95 @@ func (s *Spark) Find(name string) string {
 	return s.Rooms[name]
 }
 
+func (s *Spark) client(alias string) (*spark.Client, error) {
+	room := s.Find(alias)
+	if room == "" {
+		return nil, fmt.Errorf("Room alias not found")
+	}
+	return spark.New(s.token(), room, spark.Html)
+}
+
+// send posts the message given by args, or read from stdin when there are no args,
+// optionally as a reply in the thread of the parent message. The id of the posted
+// message is printed so scripts can reply to it.
+func (s *Spark) send(alias, parent string, args []string) error {
+	client, err := s.client(alias)
+	if err != nil {
+		return err
+	}
+	message := strings.Join(args, " ")
+	if message == "" {
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		message = strings.TrimSpace(string(b))
+	}
+	id, err := client.Post(message, parent)
+	if err != nil {
+		return err
+	}
+	fmt.Println(id)
+	return nil
+}
+
+// attach posts the content read from stdin as a file attachment named filename,
+// with the optional message given by args.
+func (s *Spark) attach(alias, filename string, args []string) error {
+	client, err := s.client(alias)
+	if err != nil {
+		return err
+	}
+	id, err := client.Attach(filename, os.Stdin, strings.Join(args, " "), "")
+	if err != nil {
+		return err
+	}
+	fmt.Println(id)
+	return nil
+}
+
+// rooms lists the rooms the token can see, with the alias of each room that has one.
+func (s *Spark) rooms() error {
+	rooms, err := spark.Rooms(s.token())
+	if err != nil {
+		return err
+	}
+	aliases := map[string]string{}
+	for k, v := range s.Rooms {
+		aliases[v] = k
+	}
+	for _, r := range rooms {
+		fmt.Printf("%s  %-7s %-20s %s\n", r.Id, r.Type, aliases[r.Id], r.Title)
+	}
+	return nil
+}
+
+// alias lists, adds or removes the room aliases in the configuration file.
+func (s *Spark) alias(args []string) error {
+	switch {
+	case len(args) == 0 || args[0] == "list":
+		names := []string{}
+		for k := range s.Rooms {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			fmt.Printf("%-20s %s\n", k, s.Rooms[k])
+		}
+		return nil
+	case args[0] == "add" && len(args) == 3:
+		s.Rooms[args[1]] = args[2]
+	case args[0] == "remove" && len(args) == 2:
+		if s.Find(args[1]) == "" {
+			return fmt.Errorf("Room alias not found")
+		}
+		delete(s.Rooms, args[1])
+	default:
+		return fmt.Errorf("Usage: spark alias [list|add 'name' 'room id'|remove 'name']")
+	}
+	return s.Save()
+}
+
 // Help informs how to use the tool
 func Help() {
 	help := `
@@ -153,6 +303,30 @@ func Help() {
 
       A message over several arguments:
         spark roomAlias This is the text that gets sent to roomAlias
+
+      A message, printing the id of the message sent. The message is read from
+      stdin when none is given:
+        spark send roomAlias "This is the text that gets sent to roomAlias"
+        some_command | spark send roomAlias
+
+      A reply in the thread of a message, using the message id printed by send:
+        spark reply roomAlias messageId "This is the reply"
+
+      A file attachment read from stdin, with an optional message:
+        spark attach roomAlias report.txt "The nightly report" < report.txt
+
+  > ROOMS
+      List the rooms your token can see along with their aliases:
+        spark rooms
+
+  > ALIASES
+      List, add or remove the room aliases in .spark:
+        spark alias list
+        spark alias add roomAlias roomId
+        spark alias remove roomAlias
+
+  When .spark has no token, the token arc's notifications use is used; taken from
+  SPARK_TOKEN or decrypted from etc/arc/spark.gpg in the arc repo given by ARC_ROOT.
 `
 	fmt.Println(help)
 }
@@ -32,80 +32,85 @@ import (
 
 	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/msg"
-	"github.com/cisco/arc/pkg/spark"
+	"github.com/cisco/arc/pkg/notify"
 )
 
+var accountingSubject string
 var accountingBuffer []string
 
 func PreAccounting(args []string) {
-	if notification == nil {
-		return
-	}
+	accountingArgs = args
+	accountingSubject = subject(args)
+}
+
+// subject describes the user, the version and the command being run.
+func subject(args []string) string {
 	version := env.Lookup("VERSION")
-	sshUser := env.Lookup("SSH_USER")
+	authUser := UserId()
 	userId := env.Lookup("USER")
 
 	trimmedVersion := strings.Split(version, " ")
 	command := strings.Join(args, " ")
 	username := userId
-	if sshUser != "" && sshUser != userId {
-		username += "(" + sshUser + ")"
+	if authUser != "" && authUser != userId {
+		username += "(" + authUser + ")"
 	}
-	s := fmt.Sprintf("**%s | %s | %s**\n> ", username, trimmedVersion[0], command)
-	accountingBuffer = append(accountingBuffer, s)
+	return fmt.Sprintf("%s | %s | %s", username, trimmedVersion[0], command)
 }
 
+// Accounting records an event of this run. The events are appended to the
+// audit log and sent to the accounting notification sinks.
 func Accounting(format string, a ...interface{}) {
-	if notification == nil {
-		return
-	}
-	s := fmt.Sprintf(format, a...)
-	m := fmt.Sprintf("%s\n", s)
-	accountingBuffer = append(accountingBuffer, m)
+	accountingBuffer = append(accountingBuffer, fmt.Sprintf(format, a...))
 }
 
-func catErrors(errorList []string) string {
-	if len(errorList) >= 5 {
-		return "Too many errors, see log file"
+// errorLines returns the errors reported during this run without the
+// markdown they are recorded with.
+func errorLines(errorList []string) []string {
+	lines := []string{}
+	for _, e := range errorList {
+		e = strings.TrimSpace(e)
+		e = strings.TrimSpace(strings.TrimPrefix(e, ">"))
+		e = strings.TrimSpace(strings.TrimPrefix(e, "`Error:`"))
+		lines = append(lines, e)
 	}
-	m := ""
-	for _, v := range errorList {
-		m += v + "\n"
+	return lines
+}
+
+// Warning sends a warning addressed to the named user to the warning
+// notification sinks.
+func Warning(user, subject string, lines ...string) error {
+	if notifier == nil {
+		return nil
 	}
-	return m
+	return notifier.Send(&notify.Message{
+		Type:     notify.Warning,
+		Title:    "Warning",
+		Subject:  subject,
+		User:     user,
+		Sections: []*notify.Section{{Lines: lines}},
+	})
 }
 
 func PostAccounting(result int) {
-	if notification == nil {
-		return
-	}
-	token := env.Lookup("SPARK_TOKEN")
-	if token == "" {
-		msg.Warn("No spark token available")
-		return
+	if err := appendLog(result); err != nil {
+		msg.Warn("Unable to append to the audit log: %s", err.Error())
 	}
-	sparkClient, err := spark.New(token, notification.Spark.Rooms["accounting"], spark.Html)
-	if err != nil {
-		msg.Error(err.Error())
+	if notification == nil {
 		return
 	}
-	resultMessage := "\n\n**Success**"
-
-	m := ""
-	for _, b := range accountingBuffer {
-		m += b + "\n> "
+	m := &notify.Message{
+		Type:     notify.Accounting,
+		Title:    "Accounting",
+		Subject:  accountingSubject,
+		Sections: []*notify.Section{{Lines: accountingBuffer}},
+		Result:   "Success",
 	}
-	m += "\r\n"
 	if result != 0 {
-		m += catErrors(msg.LastError())
-		resultMessage = "\n\n**Failure**"
-	}
-	if len(m) > 7200 {
-		m = m[:7200]
+		m.Sections = append(m.Sections, &notify.Section{Title: "Errors", Lines: errorLines(msg.LastError())})
+		m.Result = "Failure"
 	}
-	m += resultMessage
-	_, err = fmt.Fprintf(sparkClient, m)
-	if err != nil {
-		msg.Error(err.Error())
+	if err := notifier.Send(m); err != nil {
+		msg.Warn(err.Error())
 	}
 }
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/notify"
+)
+
+func TestWarning(t *testing.T) {
+	dir, err := ioutil.TempDir("", "warning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, "warnings.log")
+	cfg := filepath.Join(dir, "notifications.json")
+	sinks := `{"sinks": [{"type": "file", "path": "` + log + `", "messages": ["warning"]}]}`
+	if err := ioutil.WriteFile(cfg, []byte(sinks), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := config.NewNotifications(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Init(n); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { notification, notifier = nil, nil }()
+
+	if err := Warning("alice", "ssh keys of alice expire soon, please rotate them", "key expires in 3 days"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 warning, got %d", len(lines))
+	}
+	m := &notify.Message{}
+	if err := json.Unmarshal([]byte(lines[0]), m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != notify.Warning || m.User != "alice" || len(m.Sections) != 1 || m.Sections[0].Lines[0] != "key expires in 3 days" {
+		t.Errorf("unexpected warning %+v", m)
+	}
+}
@@ -0,0 +1,397 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/route"
+)
+
+// Tokens are the prefix, the base64 encoded approval and the base64 encoded
+// ssh signature of the prefix and encoded approval, separated by periods.
+// A request token is signed by the requester, an approval token by the
+// approver.
+const (
+	requestPrefix  = "arc-request"
+	approvalPrefix = "arc-approval"
+)
+
+// approval describes a destructive request needing the approval of a second
+// user. The scope is the path from the top most resource to the resource
+// the request was made on, for example "cluster:web/pod:api".
+type approval struct {
+	Id         string    `json:"id"`
+	DataCenter string    `json:"datacenter"`
+	Scope      string    `json:"scope"`
+	Command    string    `json:"command"`
+	Flags      []string  `json:"flags,omitempty"`
+	Requester  string    `json:"requester"`
+	Approver   string    `json:"approver,omitempty"`
+	Expires    time.Time `json:"expires"`
+}
+
+// keysOf returns the ssh public keys listed for a user.
+var keysOf func(user string) []string
+
+// approvedIds holds the approvals already accepted by this run, so children
+// of an approved resource don't record the approval again. They are recorded
+// in the audit log as used, and rejected by later runs.
+var approvedIds = map[string]bool{}
+
+// usedApprovals returns the approvals accepted by this run, recorded in the
+// audit log.
+func usedApprovals() []string {
+	ids := []string{}
+	for id := range approvedIds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// InitApproval sets the function used to find the ssh public keys of the
+// users signing approval tokens.
+func InitApproval(keys func(user string) []string) {
+	keysOf = keys
+}
+
+// needsApproval returns true for destroy and replace requests in protected
+// datacenters.
+func needsApproval(r *route.Request) bool {
+	if authPolicy == nil || !authPolicy.protected(r.DataCenter()) {
+		return false
+	}
+	return r.Command() == route.Destroy || r.Command() == route.Replace
+}
+
+// Approved returns nil if the request doesn't need approval or carries an
+// approval token, given as a flag, matching the request. Otherwise a request
+// token is created and printed for the operator to pass to a second user,
+// who approves it with "arc approve 'token'". The operator then repeats the
+// request with the resulting approval token added before it expires.
+func Approved(r *route.Request, resource, name string, parents ...string) error {
+	if !needsApproval(r) {
+		return nil
+	}
+	scope := scopeOf(resource, name, parents)
+	for _, f := range r.Flags().Get() {
+		if !strings.HasPrefix(f, approvalPrefix+".") {
+			continue
+		}
+		a, err := verifyApproval(f, r, scope)
+		if err == nil && !approvedIds[a.Id] {
+			err = unusedApproval(a.Id)
+		}
+		if err != nil {
+			Accounting("Rejected approval: %s", err.Error())
+			return err
+		}
+		if !approvedIds[a.Id] {
+			approvedIds[a.Id] = true
+			log.Info("Request %s to %s %s approved by %s", a.Id, a.Command, a.Scope, a.Approver)
+			Accounting("Executing request %s to %s %s in %s, approved by %s", a.Id, a.Command, a.Scope, a.DataCenter, a.Approver)
+		}
+		return nil
+	}
+
+	conn, err := dialAgent()
+	if err != nil {
+		return aaaError{"Unable to create approval request, " + err.Error()}
+	}
+	defer conn.Close()
+	token, a, err := newRequest(agent.NewClient(conn), r, scope)
+	if err != nil {
+		return err
+	}
+	msg.Warn("%s %s in %s needs the approval of a second user. Have them run", a.Command, a.Scope, a.DataCenter)
+	msg.Raw("\n  arc approve %s\n\n", token)
+	msg.Info("and repeat this request with the approval token added before %s.", a.Expires.Local().Format(time.RFC1123))
+	Accounting("Approval requested: %s to %s %s in %s", a.Id, a.Command, a.Scope, a.DataCenter)
+	return aaaError{"Request " + a.Id + " is waiting for approval."}
+}
+
+// newRequest creates a request token for the request, signed by the
+// authenticated user.
+func newRequest(ag agent.Agent, r *route.Request, scope string) (string, *approval, error) {
+	if !Authenticated() {
+		return "", nil, aaaError{"Approval requests can only be made by authenticated users."}
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+	a := &approval{
+		Id:         hex.EncodeToString(id),
+		DataCenter: r.DataCenter(),
+		Scope:      scope,
+		Command:    r.Command().String(),
+		Flags:      requestFlags(r),
+		Requester:  UserId(),
+		Expires:    time.Now().Add(authPolicy.approvalExpiry()).UTC().Truncate(time.Second),
+	}
+	token, err := encodeToken(ag, requestPrefix, a)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, a, nil
+}
+
+// Approve approves a request token on behalf of the authenticated user and
+// prints the approval token to be returned to the requester. The approver
+// must be authorized to run the request themselves.
+func Approve(token string) error {
+	conn, err := dialAgent()
+	if err != nil {
+		return aaaError{"Unable to approve request, " + err.Error()}
+	}
+	defer conn.Close()
+	a, approved, err := approve(agent.NewClient(conn), token)
+	if err != nil {
+		return err
+	}
+	msg.Info("Approved request %s by %s to %s %s in %s", a.Id, a.Requester, a.Command, a.Scope, a.DataCenter)
+	msg.Raw("\n  %s\n\n", approved)
+	msg.Info("The approval expires %s.", a.Expires.Local().Format(time.RFC1123))
+	Accounting("Approved: %s by %s to %s %s in %s", a.Id, a.Requester, a.Command, a.Scope, a.DataCenter)
+	return nil
+}
+
+func approve(ag agent.Agent, token string) (*approval, string, error) {
+	a, err := decodeToken(token, requestPrefix, func(a *approval) string { return a.Requester })
+	if err != nil {
+		return nil, "", err
+	}
+	if time.Now().After(a.Expires) {
+		return nil, "", aaaError{"Request " + a.Id + " expired at " + a.Expires.String() + "."}
+	}
+	if !Authenticated() {
+		return nil, "", aaaError{"Requests can only be approved by authenticated users."}
+	}
+	if UserId() == a.Requester {
+		return nil, "", aaaError{"Request " + a.Id + " must be approved by a user other than " + a.Requester + "."}
+	}
+
+	// The approver needs to be authorized to make the request themselves.
+	if err := approverAuthorized(a, UserId()); err != nil {
+		return nil, "", err
+	}
+
+	a.Approver = UserId()
+	approved, err := encodeToken(ag, approvalPrefix, a)
+	if err != nil {
+		return nil, "", err
+	}
+	return a, approved, nil
+}
+
+// approverAuthorized returns an error if the approver isn't authorized to
+// make the request of the approval themselves.
+func approverAuthorized(a *approval, approver string) error {
+	r := route.NewRequest(a.DataCenter, approver, time.Now().UTC().String())
+	r.Parse(append([]string{a.Command}, a.Flags...))
+	elements := strings.Split(a.Scope, "/")
+	parents := []string{}
+	for i := len(elements) - 2; i >= 0; i-- {
+		parents = append(parents, elements[i])
+	}
+	resource := strings.SplitN(elements[len(elements)-1], ":", 2)
+	if len(resource) != 2 {
+		return aaaError{"Request " + a.Id + " has an invalid scope " + a.Scope + "."}
+	}
+	return Authorized(r, resource[0], resource[1], parents...)
+}
+
+// unusedApproval returns an error if the approval was used by an earlier run
+// recorded in the audit log.
+func unusedApproval(id string) error {
+	if auditLog == "" {
+		return nil
+	}
+	used := false
+	err := readLog(auditLog, func(n int, e *logEntry) {
+		for _, v := range e.Approvals {
+			if v == id {
+				used = true
+			}
+		}
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return aaaError{"Unable to check whether approval " + id + " was used: " + err.Error()}
+	}
+	if used {
+		return aaaError{"Approval " + id + " was already used."}
+	}
+	return nil
+}
+
+// verifyApproval checks that the approval token was signed by an approver
+// other than the requester, who is authorized to make the request, hasn't
+// expired, and matches the request made by the requester. An approval of a
+// resource also approves its children.
+func verifyApproval(token string, r *route.Request, scope string) (*approval, error) {
+	a, err := decodeToken(token, approvalPrefix, func(a *approval) string { return a.Approver })
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case a.Approver == "" || a.Approver == a.Requester:
+		return nil, aaaError{"Approval " + a.Id + " must be made by a user other than " + a.Requester + "."}
+	case a.Requester != r.UserId():
+		return nil, aaaError{"Approval " + a.Id + " was requested by " + a.Requester + ", not " + r.UserId() + "."}
+	case time.Now().After(a.Expires):
+		return nil, aaaError{"Approval " + a.Id + " expired at " + a.Expires.String() + "."}
+	case a.DataCenter != r.DataCenter() || a.Command != r.Command().String():
+		return nil, aaaError{fmt.Sprintf("Approval %s is for %s in %s, not %s in %s.", a.Id, a.Command, a.DataCenter, r.Command(), r.DataCenter())}
+	case scope != a.Scope && !strings.HasPrefix(scope, a.Scope+"/"):
+		return nil, aaaError{"Approval " + a.Id + " is for " + a.Scope + ", not " + scope + "."}
+	case strings.Join(a.Flags, " ") != strings.Join(requestFlags(r), " "):
+		return nil, aaaError{"Approval " + a.Id + " is for flags \"" + strings.Join(a.Flags, " ") + "\", not \"" + strings.Join(requestFlags(r), " ") + "\"."}
+	}
+	if err := approverAuthorized(a, a.Approver); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ApprovalDataCenter returns the datacenter of a request token without
+// verifying it, so the datacenter's configuration can be loaded before the
+// token is approved.
+func ApprovalDataCenter(token string) (string, error) {
+	a, _, _, err := splitToken(token, requestPrefix)
+	if err != nil {
+		return "", err
+	}
+	return a.DataCenter, nil
+}
+
+// scopeOf returns the path from the top most parent to the resource. The
+// parents are ordered nearest first.
+func scopeOf(resource, name string, parents []string) string {
+	elements := []string{}
+	for i := len(parents) - 1; i >= 0; i-- {
+		elements = append(elements, parents[i])
+	}
+	return strings.Join(append(elements, resource+":"+name), "/")
+}
+
+// requestFlags returns the request's flags without any tokens or the yes
+// flag, which only skips the confirmation prompt.
+func requestFlags(r *route.Request) []string {
+	flags := []string{}
+	for _, f := range r.Flags().Get() {
+		if f == "yes" || strings.HasPrefix(f, requestPrefix+".") || strings.HasPrefix(f, approvalPrefix+".") {
+			continue
+		}
+		flags = append(flags, f)
+	}
+	return flags
+}
+
+// encodeToken signs the approval with the authenticated user's agent key.
+func encodeToken(ag agent.Agent, prefix string, a *approval) (string, error) {
+	if authenticatedKey == nil {
+		return "", aaaError{"No authenticated ssh key available to sign the " + prefix + " token."}
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	signed := prefix + "." + base64.RawURLEncoding.EncodeToString(data)
+	sig, err := ag.Sign(authenticatedKey, []byte(signed))
+	if err != nil {
+		return "", aaaError{"Unable to sign the " + prefix + " token: " + err.Error()}
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(ssh.Marshal(sig)), nil
+}
+
+// decodeToken returns the approval if the token's signature verifies with
+// one of the keys of the user returned by signer.
+func decodeToken(token, prefix string, signer func(*approval) string) (*approval, error) {
+	a, signed, sig, err := splitToken(token, prefix)
+	if err != nil {
+		return nil, err
+	}
+	user := signer(a)
+	if keysOf == nil {
+		return nil, aaaError{"Unable to verify the " + prefix + " token, no users are configured."}
+	}
+	for _, pub := range parseKeys(user, keysOf(user)) {
+		if pub.Verify(signed, sig) == nil {
+			return a, nil
+		}
+	}
+	return nil, aaaError{"The " + prefix + " token isn't signed by any of " + user + "'s keys."}
+}
+
+func splitToken(token, prefix string) (*approval, []byte, *ssh.Signature, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 || parts[0] != prefix {
+		return nil, nil, nil, aaaError{"Invalid " + prefix + " token."}
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, nil, aaaError{"Invalid " + prefix + " token: " + err.Error()}
+	}
+	a := &approval{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, nil, nil, aaaError{"Invalid " + prefix + " token: " + err.Error()}
+	}
+	blob, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, nil, aaaError{"Invalid " + prefix + " token signature: " + err.Error()}
+	}
+	sig := &ssh.Signature{}
+	if err := ssh.Unmarshal(blob, sig); err != nil {
+		return nil, nil, nil, aaaError{"Invalid " + prefix + " token signature: " + err.Error()}
+	}
+	// Depending on the ssh version trailing bytes are ignored or kept in the
+	// signature's Rest, neither of which is part of the signed data. Require
+	// the token's signature to be exactly the format and blob.
+	exact := struct {
+		Format string
+		Blob   []byte
+	}{sig.Format, sig.Blob}
+	if !bytes.Equal(ssh.Marshal(exact), blob) {
+		return nil, nil, nil, aaaError{"Invalid " + prefix + " token signature."}
+	}
+	return a, []byte(parts[0] + "." + parts[1]), sig, nil
+}
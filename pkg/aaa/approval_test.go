@@ -0,0 +1,249 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/cisco/arc/pkg/route"
+)
+
+const approvalPolicy = `{
+  "protected": [ "prod-*" ],
+  "rules": [
+    { "teams": [ "ops" ], "commands": [ "*", "destroy", "replace" ] }
+  ]
+}`
+
+type testUser struct {
+	keyring agent.Agent
+	keys    []string
+}
+
+func setupApproval(t *testing.T) map[string]*testUser {
+	setupPolicy(t, approvalPolicy)
+	users := map[string]*testUser{}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		key, authorized := newTestKey(t)
+		keyring := agent.NewKeyring()
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+		users[name] = &testUser{keyring, []string{authorized}}
+	}
+	teamsOf = func(user string) []string {
+		if user == "carol" {
+			return nil
+		}
+		return []string{"ops"}
+	}
+	InitApproval(func(user string) []string {
+		if u := users[user]; u != nil {
+			return u.keys
+		}
+		return nil
+	})
+	return users
+}
+
+func login(t *testing.T, users map[string]*testUser, name string) *testUser {
+	u := users[name]
+	if err := authenticate(u.keyring, name, u.keys); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func podRequest(user, command string, flags ...string) *route.Request {
+	r := route.NewRequest("prod-1", user, time.Now().UTC().String())
+	r.Parse(append([]string{"pod", "api", command}, flags...))
+	return r
+}
+
+func TestApproval(t *testing.T) {
+	users := setupApproval(t)
+
+	// Requests that aren't destructive or outside protected datacenters
+	// don't need approval.
+	if err := Approved(podRequest("alice", "provision"), "pod", "api", "cluster:web"); err != nil {
+		t.Errorf("provision needs no approval: %v", err)
+	}
+	r := route.NewRequest("dev-1", "alice", time.Now().UTC().String())
+	r.Parse([]string{"destroy"})
+	if err := Approved(r, "pod", "api", "cluster:web"); err != nil {
+		t.Errorf("destroy in dev-1 needs no approval: %v", err)
+	}
+
+	alice := login(t, users, "alice")
+	request, a, err := newRequest(alice.keyring, podRequest("alice", "destroy", "force"), "cluster:web/pod:api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Requester != "alice" || a.Command != "destroy" || strings.Join(a.Flags, " ") != "force" {
+		t.Errorf("unexpected request %+v", a)
+	}
+	if dc, err := ApprovalDataCenter(request); err != nil || dc != "prod-1" {
+		t.Errorf("ApprovalDataCenter returned %q, %v", dc, err)
+	}
+
+	// The requester can't approve their own request.
+	if _, _, err := approve(alice.keyring, request); err == nil {
+		t.Error("alice approved their own request")
+	}
+
+	// An approver needs to be authorized to make the request.
+	carol := login(t, users, "carol")
+	if _, _, err := approve(carol.keyring, request); err == nil {
+		t.Error("carol approved a request they aren't authorized to make")
+	}
+
+	bob := login(t, users, "bob")
+	for _, tampered := range tamper(t, request) {
+		if _, _, err := approve(bob.keyring, tampered); err == nil {
+			t.Errorf("bob approved a tampered request %q", tampered)
+		}
+	}
+	_, approval, err := approve(bob.keyring, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login(t, users, "alice")
+	tests := []struct {
+		req    *route.Request
+		scope  []string
+		needOk bool
+	}{
+		{podRequest("alice", "destroy", "force", approval), []string{"pod", "api", "cluster:web"}, true},
+		{podRequest("alice", "destroy", "force", approval), []string{"instance", "api-1", "pod:api", "cluster:web"}, true},
+		{podRequest("alice", "destroy", "force", approval, "yes"), []string{"pod", "api", "cluster:web"}, true},
+		{podRequest("alice", "destroy", approval), []string{"pod", "api", "cluster:web"}, false},
+		{podRequest("alice", "replace", "force", approval), []string{"pod", "api", "cluster:web"}, false},
+		{podRequest("alice", "destroy", "force", approval), []string{"pod", "db", "cluster:web"}, false},
+		{podRequest("alice", "destroy", "force", approval), []string{"cluster", "web"}, false},
+		{podRequest("bob", "destroy", "force", approval), []string{"pod", "api", "cluster:web"}, false},
+		{podRequest("alice", "destroy", "force", request), []string{"pod", "api", "cluster:web"}, false},
+	}
+	for i, test := range tests {
+		_, err := verify(test.req, test.scope)
+		if (err == nil) != test.needOk {
+			t.Errorf("test %d: verify returned %v, expected ok %v", i, err, test.needOk)
+		}
+	}
+
+	// An approval signed by a user who isn't authorized to make the request,
+	// without going through approve, is rejected.
+	login(t, users, "carol")
+	a.Approver = "carol"
+	forged, err := encodeToken(carol.keyring, approvalPrefix, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	login(t, users, "alice")
+	if _, err := verify(podRequest("alice", "destroy", "force", forged), []string{"pod", "api", "cluster:web"}); err == nil {
+		t.Error("an approval signed by carol was accepted")
+	}
+
+	// An approval is only used by one run, recorded in the audit log.
+	dir, err := ioutil.TempDir("", "approval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	InitAuditLog(filepath.Join(dir, "audit.log"))
+	defer InitAuditLog("")
+	approvedIds = map[string]bool{}
+	defer func() { approvedIds = map[string]bool{} }()
+	r = podRequest("alice", "destroy", "force", approval)
+	if err := Approved(r, "pod", "api", "cluster:web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Approved(r, "instance", "api-1", "pod:api", "cluster:web"); err != nil {
+		t.Errorf("the approval wasn't accepted for a child in the same run: %v", err)
+	}
+	if err := appendLog(0); err != nil {
+		t.Fatal(err)
+	}
+	accountingBuffer = nil
+	approvedIds = map[string]bool{}
+	if err := Approved(r, "pod", "api", "cluster:web"); err == nil {
+		t.Error("a used approval was accepted again")
+	}
+
+	// Expired approvals are rejected.
+	setupPolicy(t, strings.Replace(approvalPolicy, "{", `{ "approval_expiry": "-1m",`, 1))
+	login(t, users, "alice")
+	request, _, err = newRequest(alice.keyring, podRequest("alice", "destroy"), "cluster:web/pod:api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	login(t, users, "bob")
+	if _, _, err := approve(bob.keyring, request); err == nil {
+		t.Error("bob approved an expired request")
+	}
+}
+
+// verify checks the approval token flag of the request for the resource
+// given by the scope elements, the resource type, name and parents.
+func verify(r *route.Request, scope []string) (*approval, error) {
+	for _, f := range r.Flags().Get() {
+		if strings.HasPrefix(f, approvalPrefix+".") {
+			return verifyApproval(f, r, scopeOf(scope[0], scope[1], scope[2:]))
+		}
+	}
+	return nil, aaaError{"no approval token"}
+}
+
+// tamper returns copies of the token with bytes added to its signature and
+// with a changed payload.
+func tamper(t *testing.T, token string) []string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Unexpected token %q", token)
+	}
+	blob, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "destroy", "replace", 1))
+	return []string{
+		parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(append(blob, 'x')),
+		parts[0] + "." + base64.RawURLEncoding.EncodeToString(data) + "." + parts[2],
+	}
+}
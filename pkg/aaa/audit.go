@@ -29,23 +29,31 @@ package aaa
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
-	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/metrics"
 	"github.com/cisco/arc/pkg/msg"
-	"github.com/cisco/arc/pkg/spark"
+	"github.com/cisco/arc/pkg/notify"
 )
 
 type Audit struct {
-	name             string
-	printDeployed    bool
-	printConfigured  bool
-	printMismatched  bool
-	deployedBuffer   []string
-	configuredBuffer []string
-	mismatchedBuffer []string
-	message          []string
+	name            string
+	printDeployed   bool
+	printConfigured bool
+	printMismatched bool
+	findings        []*Finding
+	subject         string
+
+	// diffed is set when the audit is compared with the previous one,
+	// which gives the added and resolved findings.
+	diffed   bool
+	added    []*Finding
+	resolved []*Finding
+
+	// expired are the expired exceptions that matched findings.
+	expired []*auditException
 }
 type auditType uint
 
@@ -55,17 +63,31 @@ const (
 	Mismatched
 )
 
+var auditTypes = []auditType{Deployed, Configured, Mismatched}
+
+func (t auditType) String() string {
+	switch t {
+	case Deployed:
+		return "deployed"
+	case Configured:
+		return "configured"
+	case Mismatched:
+		return "mismatched"
+	}
+	return "unknown"
+}
+
 var freeFormAuditBuffer []string
 var AuditBuffer map[string]*Audit
 
+// auditsCounted is set once the findings have been added to the metrics.
+var auditsCounted bool
+
 func init() {
 	AuditBuffer = make(map[string]*Audit)
 }
 
 func NewAuditWithOptions(name string, deployed, configured, mismatched bool) error {
-	if notification == nil {
-		return nil
-	}
 	if name == "" {
 		return fmt.Errorf("No name given for the Audit")
 	}
@@ -85,161 +107,209 @@ func NewAudit(name string) error {
 }
 
 func (a *Audit) PreAudit(args []string) {
-	if notification == nil {
-		return
-	}
-	version := env.Lookup("VERSION")
-	sshUser := env.Lookup("SSH_USER")
-	userId := env.Lookup("USER")
-
-	trimmedVersion := strings.Split(version, " ")
-	command := strings.Join(args, " ")
-	username := userId
-	if sshUser != "" && sshUser != userId {
-		username += "(" + sshUser + ")"
-	}
-	s := fmt.Sprintf("**%s Audit Start**: %s | %s | %s", a.name, username, trimmedVersion[0], command)
-	a.message = append(a.message, s)
-	s = fmt.Sprintf("**%s Audit Complete**", a.name)
-	a.message = append(a.message, s)
+	a.subject = subject(args)
 }
 
+// Audit records a finding given only as text.
 func (a *Audit) Audit(t auditType, format string, b ...interface{}) {
-	if notification == nil {
+	a.Record(t, &Finding{Message: fmt.Sprintf(format, b...)})
+}
+
+// Record records the finding f of the given type. The message of the finding
+// is derived from its fields when it isn't set.
+func (a *Audit) Record(t auditType, f *Finding) {
+	f.Audit = a.name
+	f.Kind = t.String()
+	if f.Message == "" {
+		f.Message = f.message()
+	}
+	if a.excepted(f) {
 		return
 	}
-	s := fmt.Sprintf(format, b...)
-	log.Debug("%s Audit of %s", a.name, s)
+	log.Debug("%s Audit of %s", a.name, f.Message)
+	a.findings = append(a.findings, f)
+}
+
+// Findings returns the findings recorded by the audit.
+func (a *Audit) Findings() []*Finding {
+	return a.findings
+}
+
+// CountFindings adds n findings of the given kind and resource type to the
+// audit findings metric. Findings recorded by the audit are counted by
+// CountAudits, this is for audits that only record free form text.
+func (a *Audit) CountFindings(kind, resourceType string, n int) {
+	metrics.Add("audit_findings", "Number of audit findings by audit, kind and resource type.", float64(n),
+		metrics.Label{Name: "audit", Value: a.name},
+		metrics.Label{Name: "kind", Value: kind},
+		metrics.Label{Name: "resource_type", Value: resourceType})
+}
+
+func (a *Audit) FreeFormAudit(format string, b ...interface{}) {
+	freeFormAuditBuffer = append(freeFormAuditBuffer, fmt.Sprintf(format, b...))
+}
+
+// printed returns true if findings of type t are reported.
+func (a *Audit) printed(t auditType) bool {
 	switch t {
 	case Deployed:
-		a.deployedBuffer = append(a.deployedBuffer, s)
+		return a.printDeployed
 	case Configured:
-		a.configuredBuffer = append(a.configuredBuffer, s)
+		return a.printConfigured
 	case Mismatched:
-		a.mismatchedBuffer = append(a.mismatchedBuffer, s)
+		return a.printMismatched
 	}
+	return false
 }
 
-func (a *Audit) FreeFormAudit(format string, b ...interface{}) {
-	if notification == nil {
-		return
+// title returns the heading findings of type t are reported under.
+func (a *Audit) title(t auditType) string {
+	switch t {
+	case Deployed:
+		return fmt.Sprintf("Rogue %ss", a.name)
+	case Configured:
+		return "Configured but not created"
 	}
-	freeFormAuditBuffer = append(freeFormAuditBuffer, fmt.Sprintf(format, b...))
+	return "Mismatches"
 }
 
-func (a *Audit) auditFormatBuffer(t auditType, b []string, appName string) string {
-	m := ""
-	switch t {
-	case Deployed:
-		if !a.printDeployed {
-			return m
+// lines returns the messages of the findings of type t.
+func (a *Audit) lines(t auditType) []string {
+	l := []string{}
+	for _, f := range a.findings {
+		if f.Kind == t.String() {
+			l = append(l, f.Message)
 		}
-		m += fmt.Sprintf("**`Rogue %ss`**\r\n", a.name)
-	case Configured:
-		if !a.printConfigured {
-			return m
+	}
+	return l
+}
+
+// diffLines returns the messages of the given diffed findings, prefixed with
+// the heading of their type.
+func (a *Audit) diffLines(findings []*Finding) []string {
+	l := []string{}
+	for _, t := range auditTypes {
+		if !a.printed(t) {
+			continue
 		}
-		m += "**`Configured but not created`**\r\n"
-	case Mismatched:
-		if !a.printMismatched {
-			return m
+		for _, f := range findings {
+			if f.Kind == t.String() {
+				l = append(l, a.title(t)+" | "+f.Message)
+			}
 		}
-		m += "**`Mismatches`**\r\n"
 	}
-	if len(b) == 0 {
-		m += "\n> "
-		m += "No Differences Found\n"
-		return m
+	return l
+}
+
+// sections returns the titled lines the audit reports.
+func (a *Audit) sections() []*notify.Section {
+	secs := []*notify.Section{}
+	if a.diffed {
+		secs = append(secs,
+			&notify.Section{Title: "New findings", Lines: a.diffLines(a.added)},
+			&notify.Section{Title: "Resolved findings", Lines: a.diffLines(a.resolved)},
+		)
+	} else {
+		for _, t := range auditTypes {
+			if a.printed(t) {
+				secs = append(secs, &notify.Section{Title: a.title(t), Lines: a.lines(t)})
+			}
+		}
 	}
-	for _, v := range b {
-		m += "\t" + v + "\n"
+	for _, sec := range secs {
+		if len(sec.Lines) == 0 {
+			sec.Lines = []string{"No Differences Found"}
+		}
 	}
-	m += "\n"
-	return m
+	return secs
 }
 
-func (a *Audit) auditFormat(appName string) string {
-	m := ""
+// auditNotification returns the audit message sent to the notification sinks.
+func (a *Audit) auditNotification(appName string) *notify.Message {
+	m := &notify.Message{
+		Type:    notify.Audit,
+		Title:   a.name + " Audit",
+		Subject: a.subject,
+	}
 	switch appName {
 	case "arc", "amp":
-		m += a.message[0] + "\n> "
-		if len(a.deployedBuffer) == 0 && len(a.configuredBuffer) == 0 && len(a.mismatchedBuffer) == 0 {
-			m += "No Differences Found\n\n"
-			m += a.message[1]
+		if len(a.findings) == 0 && !a.diffed && len(a.expired) == 0 {
+			m.Sections = []*notify.Section{{Lines: []string{"No Differences Found"}}}
 			return m
 		}
-		m += a.auditFormatBuffer(Deployed, a.deployedBuffer, appName) + "\n> "
-		m += a.auditFormatBuffer(Configured, a.configuredBuffer, appName) + "\n> "
-		m += a.auditFormatBuffer(Mismatched, a.mismatchedBuffer, appName) + "\n"
-		if len(m) > 7000 {
-			m = m[:7000] + "...\n\n"
+		m.Sections = a.sections()
+		if len(a.expired) > 0 {
+			m.Sections = append(m.Sections, &notify.Section{Title: "Expired exceptions", Lines: a.expiredLines()})
 		}
-		m += a.message[1]
 	case "audit":
-		m += a.message[0] + "\n >"
-		for _, v := range freeFormAuditBuffer {
-			m += v
+		if len(freeFormAuditBuffer) == 0 {
+			m.Sections = a.sections()
+			return m
+		}
+		// The free form audit is markdown, split it into its lines.
+		sec := &notify.Section{}
+		for _, l := range strings.Split(strings.Join(freeFormAuditBuffer, ""), "\n") {
+			l = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), ">"))
+			if l != "" {
+				sec.Lines = append(sec.Lines, l)
+			}
 		}
-		m += "\n"
-		m += a.message[1]
+		m.Sections = []*notify.Section{sec}
 	}
 	return m
 }
 
-func (a *Audit) auditMessage(b []string) {
-	if len(b) == 0 {
-		msg.Detail("No Differences Found")
-		return
-	}
-	for _, v := range b {
-		msg.Detail(v)
+// audits returns the audits sorted by name.
+func audits() []*Audit {
+	l := []*Audit{}
+	for _, v := range AuditBuffer {
+		l = append(l, v)
 	}
+	sort.Slice(l, func(i, j int) bool { return l[i].name < l[j].name })
+	return l
 }
 
-func PostAudit(appName string) {
-	if notification == nil {
+// CountAudits adds the findings recorded by the audits to the audit findings
+// metric, once. PostAudit counts them, failed runs which don't reach
+// PostAudit count them before writing the metrics.
+func CountAudits() {
+	if auditsCounted {
 		return
 	}
-	token := env.Lookup("SPARK_TOKEN")
-	if token == "" {
-		msg.Warn("No spark token available")
-		return
-	}
-	sparkClient, err := spark.New(token, notification.Spark.Rooms["audit"], spark.Html)
-	if err != nil {
-		msg.Error(err.Error())
-		return
+	auditsCounted = true
+	for _, v := range audits() {
+		for _, f := range v.findings {
+			v.CountFindings(f.Kind, f.ResourceType, 1)
+		}
 	}
+}
+
+func PostAudit(appName string) {
+	CountAudits()
 	switch appName {
 	case "arc", "amp":
-		for _, v := range AuditBuffer {
-			if v.printDeployed {
-				msg.Info("Rogue %ss", v.name)
-				v.auditMessage(v.deployedBuffer)
-			}
-			if v.printConfigured {
-				msg.Info("Configured but not created")
-				v.auditMessage(v.configuredBuffer)
-			}
-			if v.printMismatched {
-				msg.Info("Mismatches")
-				v.auditMessage(v.mismatchedBuffer)
-			}
+		if err := reportAudits(appName); err != nil {
+			msg.Warn(err.Error())
 		}
-		// Sends the formatted audit information to the audit spark room
-		for _, v := range AuditBuffer {
-			_, err = fmt.Fprintf(sparkClient, v.auditFormat(appName))
-			if err != nil {
-				msg.Error(err.Error())
+		for _, v := range audits() {
+			for _, sec := range v.sections() {
+				msg.Info(sec.Title)
+				for _, l := range sec.Lines {
+					msg.Detail(l)
+				}
 			}
-		}
-	case "audit":
-		// Sends the formatted audit information to the audit spark room
-		for _, v := range AuditBuffer {
-			_, err = fmt.Fprintf(sparkClient, v.auditFormat(appName))
-			if err != nil {
-				msg.Error(err.Error())
+			for _, l := range v.expiredLines() {
+				msg.Warn(l)
 			}
 		}
 	}
+	if notifier == nil {
+		return
+	}
+	// Sends the audit information to the audit notification sinks.
+	for _, v := range audits() {
+		if err := notifier.Send(v.auditNotification(appName)); err != nil {
+			msg.Warn(err.Error())
+		}
+	}
 }
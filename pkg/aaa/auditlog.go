@@ -0,0 +1,365 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/msg"
+)
+
+// logEntry is a line of the audit log recording a run of arc. Each entry
+// holds the hash of the previous entry, and its own hash covers the entry
+// and the previous hash, so any change to the history breaks the chain.
+// The datacenter is only set for the arc and amp runs, the resources are the
+// provider ids named by the run's events, and the approvals are the ids of
+// the approval tokens used by the run, which can't be used again.
+type logEntry struct {
+	Time          time.Time `json:"time"`
+	User          string    `json:"user"`
+	SshUser       string    `json:"ssh_user"`
+	Authenticated string    `json:"authenticated,omitempty"`
+	Version       string    `json:"version"`
+	DataCenter    string    `json:"datacenter"`
+	Command       string    `json:"command"`
+	Events        []string  `json:"events"`
+	Resources     []string  `json:"resources,omitempty"`
+	Approvals     []string  `json:"approvals,omitempty"`
+	Result        string    `json:"result"`
+	Errors        []string  `json:"errors,omitempty"`
+	Prev          string    `json:"prev"`
+	Hash          string    `json:"hash"`
+}
+
+// datacenterApps are the applications whose first argument is a datacenter.
+var datacenterApps = map[string]bool{"arc": true, "amp": true}
+
+// resourceId matches provider resource ids such as i-0123456789abcdef0,
+// vol-01234567 or eipalloc-0123abcd in the accounting events.
+var resourceId = regexp.MustCompile(`\b[a-z]+-[0-9a-f]{8,17}\b`)
+
+// auditLog is the name of the audit log, and accountingArgs the command
+// line recorded by PreAccounting.
+var (
+	auditLog       string
+	accountingArgs []string
+)
+
+// InitAuditLog sets the name of the local audit log every run is appended to.
+func InitAuditLog(name string) {
+	auditLog = name
+}
+
+// sum returns the hash of the entry, computed with the hash field empty.
+func (e *logEntry) sum() string {
+	c := *e
+	c.Hash = ""
+	b, _ := json.Marshal(&c)
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// appendLog appends the accounting of this run to the audit log.
+func appendLog(result int) error {
+	if auditLog == "" {
+		return nil
+	}
+	// Runs failing before PreAccounting, for example when authentication
+	// fails, are recorded with the process arguments.
+	args := accountingArgs
+	if args == nil {
+		args = os.Args
+	}
+	e := &logEntry{
+		Time:          time.Now().UTC(),
+		User:          env.Lookup("USER"),
+		SshUser:       env.Lookup("SSH_USER"),
+		Authenticated: authenticatedUser,
+		Version:       strings.Split(env.Lookup("VERSION"), " ")[0],
+		Command:       strings.Join(args, " "),
+		Events:        accountingBuffer,
+		Resources:     resourceIds(accountingBuffer),
+		Approvals:     usedApprovals(),
+		Result:        "success",
+	}
+	if len(args) > 1 && datacenterApps[filepath.Base(args[0])] {
+		e.DataCenter = args[1]
+	}
+	if result != 0 {
+		e.Result = "failure"
+		e.Errors = errorLines(msg.LastError())
+	}
+
+	f, err := os.OpenFile(auditLog, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	last, err := lastLine(f)
+	if err != nil {
+		return err
+	}
+	if last != "" {
+		prev := &logEntry{}
+		if err := json.Unmarshal([]byte(last), prev); err != nil {
+			return aaaError{"Unable to read the last entry of " + auditLog + ": " + err.Error()}
+		}
+		e.Prev = prev.Hash
+	}
+	e.Hash = e.sum()
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// resourceIds returns the resource ids named by the events, in the order
+// they first appear.
+func resourceIds(events []string) []string {
+	ids, seen := []string{}, map[string]bool{}
+	for _, ev := range events {
+		for _, id := range resourceId.FindAllString(ev, -1) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// lastLine returns the last line of the file, reading backwards from the end.
+func lastLine(f *os.File) (string, error) {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", err
+	}
+	line := []byte{}
+	buf := make([]byte, 4096)
+	for pos := size; pos > 0; {
+		n := int64(len(buf))
+		if pos < n {
+			n = pos
+		}
+		pos -= n
+		if _, err := f.ReadAt(buf[:n], pos); err != nil {
+			return "", err
+		}
+		line = append(append([]byte{}, buf[:n]...), line...)
+		trimmed := strings.TrimRight(string(line), "\n")
+		if i := strings.LastIndex(trimmed, "\n"); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+	}
+	return strings.TrimRight(string(line), "\n"), nil
+}
+
+// readLog calls fn with each entry of the audit log and its line number.
+// It returns an error if an entry can't be read, if an entry's hash doesn't
+// match its content, or if it doesn't follow the previous entry.
+func readLog(name string, fn func(n int, e *logEntry)) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	prev := ""
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		e := &logEntry{}
+		if err := json.Unmarshal(line, e); err != nil {
+			return aaaError{fmt.Sprintf("%s:%d: unreadable entry: %s", name, n, err.Error())}
+		}
+		if e.Prev != prev {
+			return aaaError{fmt.Sprintf("%s:%d: entry doesn't follow the previous entry, the chain is broken", name, n)}
+		}
+		if e.Hash != e.sum() {
+			return aaaError{fmt.Sprintf("%s:%d: entry hash mismatch, the entry was modified", name, n)}
+		}
+		prev = e.Hash
+		fn(n, e)
+	}
+}
+
+// logFilter selects audit log entries by user, datacenter, resource and time
+// range.
+type logFilter struct {
+	user       string
+	datacenter string
+	resource   string
+	since      time.Time
+	until      time.Time
+}
+
+// parseFilter parses filters of the form user=name, datacenter=name,
+// resource=id, since=time and until=time. Times are RFC3339 timestamps, dates in the form
+// 2006-01-02, or durations before now such as 24h.
+func parseFilter(args []string) (*logFilter, error) {
+	f := &logFilter{}
+	for _, a := range args {
+		kv := strings.SplitN(a, "=", 2)
+		if len(kv) != 2 {
+			return nil, aaaError{"Unknown audit log filter " + a}
+		}
+		var err error
+		switch kv[0] {
+		case "user":
+			f.user = kv[1]
+		case "datacenter", "dc":
+			f.datacenter = kv[1]
+		case "resource":
+			f.resource = kv[1]
+		case "since":
+			f.since, err = parseTime(kv[1])
+		case "until":
+			f.until, err = parseTime(kv[1])
+		default:
+			return nil, aaaError{"Unknown audit log filter " + a}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, aaaError{"Unable to parse time " + s + ", use an RFC3339 time, a date or a duration"}
+}
+
+func (f *logFilter) match(e *logEntry) bool {
+	if f.user != "" && f.user != e.User && f.user != e.SshUser && f.user != e.Authenticated {
+		return false
+	}
+	if f.datacenter != "" && f.datacenter != e.DataCenter {
+		return false
+	}
+	if f.resource != "" && !contains(e.Resources, f.resource) {
+		return false
+	}
+	if !f.since.IsZero() && e.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && e.Time.After(f.until) {
+		return false
+	}
+	return true
+}
+
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditLog runs the audit-log command. "verify" validates the hash chain of
+// the audit log and "show" prints the entries matching the given filters.
+func AuditLog(args []string) error {
+	if auditLog == "" {
+		return aaaError{"No audit log configured"}
+	}
+	if len(args) == 0 {
+		return aaaError{"usage: audit-log verify|show [user=name] [datacenter=name] [resource=id] [since=time] [until=time]"}
+	}
+	switch args[0] {
+	case "verify":
+		count := 0
+		if err := readLog(auditLog, func(n int, e *logEntry) { count = n }); err != nil {
+			return err
+		}
+		msg.Info("Verified %d entries in %s", count, auditLog)
+		return nil
+	case "show":
+		f, err := parseFilter(args[1:])
+		if err != nil {
+			return err
+		}
+		return readLog(auditLog, func(n int, e *logEntry) {
+			if !f.match(e) {
+				return
+			}
+			user := e.User
+			if e.Authenticated != "" && e.Authenticated != e.User {
+				user += "(" + e.Authenticated + ")"
+			}
+			msg.Info("%s %s %s | %s | %s", e.Time.Local().Format(time.RFC3339), user, e.Result, e.Version, e.Command)
+			msg.IndentInc()
+			for _, ev := range e.Events {
+				msg.Detail("%s", ev)
+			}
+			if len(e.Resources) > 0 {
+				msg.Detail("resources: %s", strings.Join(e.Resources, ", "))
+			}
+			for _, err := range e.Errors {
+				msg.Detail("error: %s", err)
+			}
+			msg.IndentDec()
+		})
+	}
+	return aaaError{"Unknown audit-log command " + args[0] + ", use verify or show"}
+}
@@ -0,0 +1,136 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	setupPolicy(t, "{}")
+	dir, err := ioutil.TempDir("", "auditlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "audit.log")
+	InitAuditLog(name)
+	defer InitAuditLog("")
+
+	runs := [][]string{
+		{"arc", "dev-1", "cluster", "web", "provision"},
+		{"arc", "prod-1", "pod", "api", "destroy"},
+		{"/usr/local/bin/arc", "dev-1", "info"},
+		{"audit", "users", "live", "dev-1"},
+	}
+	for i, args := range runs {
+		PreAccounting(args)
+		Accounting("event %d", i)
+		if i == 1 {
+			Accounting("Instance destroyed: api-01, i-0123456789abcdef0")
+			Accounting("Volume destroyed: /dev/sdb/data, vol-0123abcd")
+		}
+		if err := appendLog(i % 2); err != nil {
+			t.Fatal(err)
+		}
+		accountingBuffer = nil
+	}
+
+	entries := []*logEntry{}
+	if err := readLog(name, func(n int, e *logEntry) { entries = append(entries, e) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+	if entries[0].Prev != "" || entries[1].Prev != entries[0].Hash || entries[2].Prev != entries[1].Hash {
+		t.Error("entries aren't chained")
+	}
+	if entries[1].DataCenter != "prod-1" || entries[1].Result != "failure" || entries[1].Events[0] != "event 1" {
+		t.Errorf("unexpected entry %+v", entries[1])
+	}
+	if strings.Join(entries[1].Resources, ",") != "i-0123456789abcdef0,vol-0123abcd" || len(entries[0].Resources) != 0 {
+		t.Errorf("unexpected resources %v, %v", entries[0].Resources, entries[1].Resources)
+	}
+	if entries[2].DataCenter != "dev-1" || entries[3].DataCenter != "" {
+		t.Errorf("expected the datacenter only for arc, got %q and %q", entries[2].DataCenter, entries[3].DataCenter)
+	}
+
+	tests := []struct {
+		filter []string
+		count  int
+	}{
+		{nil, 4},
+		{[]string{"datacenter=dev-1"}, 2},
+		{[]string{"resource=vol-0123abcd"}, 1},
+		{[]string{"user=" + entries[0].User}, 4},
+		{[]string{"user=nobody"}, 0},
+		{[]string{"since=1h"}, 4},
+		{[]string{"until=" + time.Now().Add(-time.Hour).Format(time.RFC3339)}, 0},
+	}
+	for i, test := range tests {
+		f, err := parseFilter(test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		readLog(name, func(n int, e *logEntry) {
+			if f.match(e) {
+				count++
+			}
+		})
+		if count != test.count {
+			t.Errorf("test %d: %v matched %d entries, expected %d", i, test.filter, count, test.count)
+		}
+	}
+	if _, err := parseFilter([]string{"host=x"}); err == nil {
+		t.Error("expected an error for an unknown filter")
+	}
+
+	// Modifying an entry or removing one breaks the chain.
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	for i, tampered := range []string{
+		strings.Replace(string(b), "prod-1", "prod-2", 1),
+		lines[0] + lines[2],
+	} {
+		if err := ioutil.WriteFile(name, []byte(tampered), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := AuditLog([]string{"verify"}); err == nil {
+			t.Errorf("tampering %d wasn't detected", i)
+		}
+	}
+}
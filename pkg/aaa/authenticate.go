@@ -27,9 +27,131 @@
 package aaa
 
 import (
-	"os/user"
+	"bytes"
+	"crypto/rand"
+	"net"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/route"
 )
 
-func Authenticate(u *user.User) error {
-	return nil
+// authenticatedUser is the user proven by Authenticate and authenticatedKey
+// is the public key of the agent key used to prove it.
+var (
+	authenticatedUser string
+	authenticatedKey  ssh.PublicKey
+)
+
+// challengePrefix separates arc authentication challenges from any other
+// data the agent may be asked to sign.
+const challengePrefix = "arc-authenticate:"
+
+// Authenticate proves that the operator holds a private key listed for the
+// named user. A random challenge is signed by the ssh-agent using each agent
+// key matching one of the user's keys, and the signature is verified against
+// the listed public key. On success the name is returned by UserId.
+func Authenticate(name string, keys []string) error {
+	conn, err := dialAgent()
+	if err != nil {
+		return aaaError{"Unable to authenticate " + name + ", " + err.Error()}
+	}
+	defer conn.Close()
+	return authenticate(agent.NewClient(conn), name, keys)
+}
+
+// dialAgent connects to the ssh-agent named by SSH_AUTH_SOCK.
+func dialAgent() (net.Conn, error) {
+	conn, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+	if err != nil {
+		return nil, aaaError{"no ssh-agent available: " + err.Error()}
+	}
+	return conn, nil
+}
+
+func authenticate(a agent.Agent, name string, keys []string) error {
+	authenticatedUser = ""
+	authenticatedKey = nil
+	authorized := parseKeys(name, keys)
+	if len(authorized) == 0 {
+		return aaaError{"Unable to authenticate " + name + ", no ssh keys are listed for the user"}
+	}
+
+	agentKeys, err := a.List()
+	if err != nil {
+		return aaaError{"Unable to authenticate " + name + ", cannot list ssh-agent keys: " + err.Error()}
+	}
+
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	challenge := append([]byte(challengePrefix+name+":"), nonce...)
+
+	for _, ak := range agentKeys {
+		for _, pub := range authorized {
+			if !bytes.Equal(ak.Marshal(), pub.Marshal()) {
+				continue
+			}
+			sig, err := a.Sign(ak, challenge)
+			if err != nil {
+				log.Warn("ssh-agent failed to sign with %s key: %s", pub.Type(), err.Error())
+				continue
+			}
+			if err := pub.Verify(challenge, sig); err != nil {
+				log.Warn("Signature from %s key failed to verify: %s", pub.Type(), err.Error())
+				continue
+			}
+			log.Info("Authenticated %s with %s key %s", name, pub.Type(), ssh.FingerprintSHA256(pub))
+			authenticatedUser = name
+			authenticatedKey = pub
+			return nil
+		}
+	}
+	return aaaError{"Unable to authenticate " + name + ", the ssh-agent holds none of the user's keys"}
+}
+
+// parseKeys parses the user's authorized keys, ignoring any that are invalid.
+func parseKeys(name string, keys []string) []ssh.PublicKey {
+	authorized := []ssh.PublicKey{}
+	for _, k := range keys {
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k))
+		if err != nil {
+			log.Warn("Ignoring unparsable ssh key for %s: %s", name, err.Error())
+			continue
+		}
+		authorized = append(authorized, pub)
+	}
+	return authorized
+}
+
+// UserId returns the user proven by Authenticate. If authentication hasn't
+// been performed it returns the operating system user name.
+func UserId() string {
+	if authenticatedUser != "" {
+		return authenticatedUser
+	}
+	return env.Lookup("USER")
+}
+
+// Authenticated returns true if the operator has been authenticated.
+func Authenticated() bool {
+	return authenticatedUser != ""
+}
+
+// ReadOnly returns true if the request only reads state, so it can be made
+// by an unauthenticated operator such as a cron job. Audits are read only
+// unless they fix their findings.
+func ReadOnly(r *route.Request) bool {
+	switch r.Command() {
+	case route.None, route.Load, route.Help, route.Config, route.Info:
+		return true
+	case route.Audit:
+		return !r.Flag("fix")
+	}
+	return false
 }
@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/cisco/arc/pkg/route"
+)
+
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(ssh.MarshalAuthorizedKey(pub))
+}
+
+func TestAuthenticate(t *testing.T) {
+	setupPolicy(t, "{}")
+	key, authorized := newTestKey(t)
+	_, other := newTestKey(t)
+
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		keys []string
+		ok   bool
+	}{
+		{[]string{authorized}, true},
+		{[]string{other, authorized}, true},
+		{[]string{other}, false},
+		{[]string{"not a key"}, false},
+		{nil, false},
+	}
+	for i, test := range tests {
+		err := authenticate(keyring, "alice", test.keys)
+		if (err == nil) != test.ok {
+			t.Errorf("test %d: authenticate returned %v, expected ok %v", i, err, test.ok)
+		}
+		if Authenticated() != test.ok {
+			t.Errorf("test %d: Authenticated is %v, expected %v", i, Authenticated(), test.ok)
+		}
+		if test.ok && UserId() != "alice" {
+			t.Errorf("test %d: UserId is %q, expected alice", i, UserId())
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := []struct {
+		args     []string
+		readOnly bool
+	}{
+		{[]string{"info"}, true},
+		{[]string{"cluster", "web", "show"}, true},
+		{[]string{"audit", "diff"}, true},
+		{[]string{"audit", "fix", "allow=create"}, false},
+		{[]string{"instance", "web-01", "stop"}, false},
+		{[]string{"cluster", "web", "destroy", "yes"}, false},
+	}
+	for _, test := range tests {
+		r := route.NewRequest("dc", "user", "now")
+		r.Parse(test.args)
+		if ReadOnly(r) != test.readOnly {
+			t.Errorf("%v: expected read only %v", test.args, test.readOnly)
+		}
+	}
+}
@@ -27,9 +27,51 @@
 package aaa
 
 import (
+	"fmt"
+
+	"github.com/cisco/arc/pkg/log"
 	"github.com/cisco/arc/pkg/route"
 )
 
-func Authorized(r *route.Request, resource, name string) error {
-	return nil
+// Authorized returns an error if the user making the request isn't allowed
+// to run the request's command on the named resource. The parents are the
+// resource's ancestors in the form "type:name", for example "cluster:web",
+// so rules granted on an ancestor also apply to the resource. Requests that
+// only read state are always authorized. Denials are recorded in accounting.
+func Authorized(r *route.Request, resource, name string, parents ...string) error {
+	if authPolicy == nil {
+		return nil
+	}
+	switch r.Command() {
+	case route.None, route.Load, route.Help, route.Config, route.Info:
+		return nil
+	}
+
+	user := r.UserId()
+	teams := []string{}
+	if teamsOf != nil {
+		teams = teamsOf(user)
+	}
+	command := r.Command().String()
+	scopes := append([]string{resource + ":" + name}, parents...)
+	protected := needsApproval(r)
+
+	for _, rl := range authPolicy.Rules {
+		if rl.allows(user, teams, r.DataCenter(), scopes, command, protected) {
+			log.Debug("Authorized %s to %s %s %s", user, command, resource, name)
+			return nil
+		}
+	}
+	if !protected && !authPolicy.deny() {
+		return nil
+	}
+
+	reason := "no policy rule grants it"
+	if protected {
+		reason = r.DataCenter() + " is protected"
+	}
+	err := aaaError{fmt.Sprintf("User %s is not authorized to %s %s %s in %s, %s.", user, command, resource, name, r.DataCenter(), reason)}
+	log.Info("%s", err.Error())
+	Accounting("Unauthorized: %s", err.Error())
+	return err
 }
@@ -0,0 +1,157 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/route"
+)
+
+const testPolicy = `{
+  "protected": [ "prod-*" ],
+  "rules": [
+    { "teams": [ "ops" ], "datacenters": [ "prod-*" ], "commands": [ "*", "destroy" ] },
+    { "users": [ "alice" ], "resources": [ "pod:web*" ], "commands": [ "*" ] }
+  ]
+}`
+
+func setupPolicy(t *testing.T, policy string) {
+	dir, err := ioutil.TempDir("", "aaa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Set("AAA", dir)
+	if err := log.Init("aaa"); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "policy.json")
+	if err := ioutil.WriteFile(name, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	teams := func(user string) []string {
+		if user == "bob" {
+			return []string{"ops"}
+		}
+		return nil
+	}
+	if err := InitPolicy(name, teams); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func request(datacenter, user string, params ...string) *route.Request {
+	r := route.NewRequest(datacenter, user, "")
+	r.Parse(params)
+	return r
+}
+
+func TestAuthorizedNoPolicy(t *testing.T) {
+	setupPolicy(t, testPolicy)
+	if err := InitPolicy(filepath.Join(os.TempDir(), "does-not-exist.json"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Authorized(request("prod-1", "eve", "destroy"), "pod", "web"); err != nil {
+		t.Errorf("Expected no policy to authorize, got %q", err)
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	setupPolicy(t, testPolicy)
+
+	tests := []struct {
+		datacenter, user, command, resource, name string
+		parents                                   []string
+		authorized                                bool
+	}{
+		// Read only commands are always authorized.
+		{"prod-1", "eve", "info", "pod", "web", nil, true},
+		// Unprotected commands fall through to the allow default.
+		{"prod-1", "eve", "provision", "pod", "db", nil, true},
+		// Destroy and replace in protected datacenters need an explicit rule.
+		{"prod-1", "eve", "destroy", "pod", "db", nil, false},
+		{"prod-1", "alice", "destroy", "pod", "web", nil, false},
+		{"prod-1", "alice", "replace", "instance", "web-01", []string{"pod:web"}, false},
+		{"prod-1", "bob", "destroy", "pod", "db", nil, true},
+		{"prod-1", "bob", "replace", "pod", "db", nil, false},
+		// Outside protected datacenters the default applies.
+		{"dev-1", "eve", "destroy", "pod", "db", nil, true},
+	}
+	for _, test := range tests {
+		err := Authorized(request(test.datacenter, test.user, test.command), test.resource, test.name, test.parents...)
+		if authorized := err == nil; authorized != test.authorized {
+			t.Errorf("%s %s %s:%s in %s, expected authorized %t, got %v",
+				test.user, test.command, test.resource, test.name, test.datacenter, test.authorized, err)
+		}
+	}
+}
+
+func TestAuthorizedDefaultDeny(t *testing.T) {
+	setupPolicy(t, `{
+  "default": "deny",
+  "rules": [ { "users": [ "alice" ], "resources": [ "cluster:web" ], "commands": [ "restart" ] } ]
+}`)
+
+	tests := []struct {
+		user, command, resource, name string
+		parents                       []string
+		authorized                    bool
+	}{
+		{"alice", "restart", "cluster", "web", nil, true},
+		{"alice", "restart", "instance", "web-01", []string{"pod:web", "cluster:web"}, true},
+		{"alice", "restart", "cluster", "db", nil, false},
+		{"alice", "stop", "cluster", "web", nil, false},
+		{"eve", "restart", "cluster", "web", nil, false},
+		{"eve", "help", "cluster", "web", nil, true},
+	}
+	for _, test := range tests {
+		err := Authorized(request("dev-1", test.user, test.command), test.resource, test.name, test.parents...)
+		if authorized := err == nil; authorized != test.authorized {
+			t.Errorf("%s %s %s:%s, expected authorized %t, got %v",
+				test.user, test.command, test.resource, test.name, test.authorized, err)
+		}
+	}
+}
+
+func TestInitPolicyBadDefault(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aaa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "policy.json")
+	if err := ioutil.WriteFile(name, []byte(`{"default": "maybe"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitPolicy(name, nil); err == nil {
+		t.Errorf("Expected an error for an unknown default")
+	}
+}
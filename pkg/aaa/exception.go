@@ -0,0 +1,177 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cisco/arc/pkg/log"
+)
+
+// auditException suppresses the audit findings it matches until it expires.
+// Findings are matched by resource type, a name pattern and the provider id,
+// those that are given all need to match.
+type auditException struct {
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name"`
+	Id           string `json:"id"`
+	Owner        string `json:"owner"`
+	Reason       string `json:"reason"`
+	Expires      string `json:"expires"`
+
+	expires time.Time
+}
+
+// auditExceptions are the exceptions of the audited datacenter.
+var auditExceptions []*auditException
+
+// loadAuditExceptions reads the audit exceptions of the datacenter from the
+// named file. A datacenter doesn't need to have exceptions.
+func loadAuditExceptions(name string) error {
+	auditExceptions = nil
+	data, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	l := []*auditException{}
+	if err := json.Unmarshal(data, &l); err != nil {
+		return fmt.Errorf("Unable to read the audit exceptions %s: %s", name, err.Error())
+	}
+	for i, e := range l {
+		if err := e.validate(); err != nil {
+			return fmt.Errorf("Audit exception %d in %s: %s", i+1, name, err.Error())
+		}
+	}
+	auditExceptions = l
+	return nil
+}
+
+func (e *auditException) validate() error {
+	if e.ResourceType == "" && e.Name == "" && e.Id == "" {
+		return fmt.Errorf("a resource_type, name or id is required")
+	}
+	if _, err := path.Match(e.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern %q", e.Name)
+	}
+	if e.Owner == "" || e.Reason == "" {
+		return fmt.Errorf("an owner and a reason are required")
+	}
+	// A date expires at the end of the day.
+	if t, err := time.Parse("2006-01-02", e.Expires); err == nil {
+		e.expires = t.AddDate(0, 0, 1)
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, e.Expires)
+	if err != nil {
+		return fmt.Errorf("invalid expiry %q, expected a date such as 2006-01-02", e.Expires)
+	}
+	e.expires = t
+	return nil
+}
+
+func (e *auditException) String() string {
+	return fmt.Sprintf("%s owned by %s, %s", e.match(), e.Owner, e.Reason)
+}
+
+// match describes the findings the exception matches.
+func (e *auditException) match() string {
+	l := []string{}
+	if e.ResourceType != "" {
+		l = append(l, "resource_type="+e.ResourceType)
+	}
+	if e.Name != "" {
+		l = append(l, "name="+e.Name)
+	}
+	if e.Id != "" {
+		l = append(l, "id="+e.Id)
+	}
+	return strings.Join(l, " ")
+}
+
+func (e *auditException) matches(f *Finding) bool {
+	if e.ResourceType != "" && !strings.EqualFold(e.ResourceType, f.ResourceType) {
+		return false
+	}
+	if e.Name != "" {
+		if ok, _ := path.Match(e.Name, f.Name); !ok {
+			return false
+		}
+	}
+	if e.Id != "" && e.Id != f.Id {
+		return false
+	}
+	return true
+}
+
+// expired returns true if the exception has expired by t.
+func (e *auditException) expired(t time.Time) bool {
+	return !t.Before(e.expires)
+}
+
+// excepted returns true if the finding is suppressed by an exception. The
+// findings of expired exceptions surface again, and the exception is noted
+// so the audit warns about it.
+func (a *Audit) excepted(f *Finding) bool {
+	now := time.Now()
+	for _, e := range auditExceptions {
+		if !e.matches(f) {
+			continue
+		}
+		if !e.expired(now) {
+			log.Debug("%s Audit of %s excepted by %s", a.name, f.Message, e)
+			return true
+		}
+		found := false
+		for _, x := range a.expired {
+			if x == e {
+				found = true
+			}
+		}
+		if !found {
+			a.expired = append(a.expired, e)
+		}
+	}
+	return false
+}
+
+// expiredLines returns the warnings for the expired exceptions of the audit.
+func (a *Audit) expiredLines() []string {
+	l := []string{}
+	for _, e := range a.expired {
+		l = append(l, fmt.Sprintf("Exception %s expired on %s", e, e.Expires))
+	}
+	return l
+}
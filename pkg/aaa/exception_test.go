@@ -0,0 +1,118 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/route"
+)
+
+func TestAuditExceptions(t *testing.T) {
+	setupPolicy(t, "{}")
+	root, err := ioutil.TempDir("", "exceptions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	env.Set("ROOT", root)
+	defer env.Set("ROOT", "")
+	defer func() { auditExceptions = nil }()
+
+	dir := filepath.Join(root, "etc", "arc", "audit_exceptions")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	exceptions := `[
+		{"resource_type": "instance", "name": "hedge-*", "owner": "alice", "reason": "load test", "expires": "` + tomorrow + `"},
+		{"id": "vol-1234", "owner": "bob", "reason": "forensics", "expires": "2017-01-01"}
+	]`
+	if err := ioutil.WriteFile(filepath.Join(dir, "dev-1.json"), []byte(exceptions), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	AuditBuffer = map[string]*Audit{}
+	req := route.NewRequest("dev-1", "alice", "")
+	req.Parse([]string{"audit"})
+	if err := AuditOptions(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(auditExceptions) != 2 {
+		t.Fatalf("expected 2 exceptions, got %d", len(auditExceptions))
+	}
+	if err := NewAudit("Instance"); err != nil {
+		t.Fatal(err)
+	}
+	a := AuditBuffer["Instance"]
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "hedge-01"})
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "web-01"})
+	a.Record(Deployed, &Finding{ResourceType: "volume", Id: "vol-1234"})
+	a.Record(Deployed, &Finding{ResourceType: "volume", Id: "vol-1234", Message: "again"})
+
+	if len(a.findings) != 3 || a.findings[0].Name != "web-01" {
+		t.Errorf("unexpected findings %+v", a.findings)
+	}
+	lines := a.expiredLines()
+	if len(lines) != 1 || !strings.Contains(lines[0], "owned by bob") || !strings.Contains(lines[0], "2017-01-01") {
+		t.Errorf("unexpected expired exceptions %v", lines)
+	}
+	m := a.auditNotification("arc")
+	if sec := m.Sections[len(m.Sections)-1]; sec.Title != "Expired exceptions" {
+		t.Errorf("expected an expired exceptions section, got %+v", sec)
+	}
+}
+
+func TestAuditExceptionValidation(t *testing.T) {
+	tests := []string{
+		`[{"owner": "alice", "reason": "test", "expires": "2030-01-01"}]`,
+		`[{"name": "web-*", "reason": "test", "expires": "2030-01-01"}]`,
+		`[{"name": "web-*", "owner": "alice", "reason": "test", "expires": "next week"}]`,
+		`[{"name": "[web", "owner": "alice", "reason": "test", "expires": "2030-01-01"}]`,
+	}
+	dir, err := ioutil.TempDir("", "exceptions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func() { auditExceptions = nil }()
+	name := filepath.Join(dir, "dev-1.json")
+	for i, test := range tests {
+		if err := ioutil.WriteFile(name, []byte(test), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := loadAuditExceptions(name); err == nil {
+			t.Errorf("test %d: expected an error", i)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Finding is a single result of an audit, comparing a configured resource
+// with what is deployed at the provider.
+type Finding struct {
+	Audit        string `json:"audit"`
+	Kind         string `json:"kind"`
+	Change       string `json:"change,omitempty"`
+	ResourceType string `json:"resource_type,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Id           string `json:"id,omitempty"`
+	Attribute    string `json:"attribute,omitempty"`
+	Expected     string `json:"expected,omitempty"`
+	Actual       string `json:"actual,omitempty"`
+	Message      string `json:"message"`
+	Category     string `json:"category,omitempty"`
+	Action       string `json:"action,omitempty"`
+
+	fix func() error
+}
+
+// Changes of a finding reported by an audit diff.
+const (
+	Added    = "new"
+	Resolved = "resolved"
+)
+
+// message returns the text used for the finding when none was given.
+func (f *Finding) message() string {
+	name := f.Name
+	if name == "" {
+		name = f.Id
+	}
+	if f.Attribute == "" {
+		return name
+	}
+	t := f.ResourceType
+	if t != "" {
+		t = strings.ToUpper(t[:1]) + t[1:] + " "
+	}
+	return fmt.Sprintf("%s%q | Configured %s: %q - Deployed %s: %q", t, name, f.Attribute, f.Expected, f.Attribute, f.Actual)
+}
+
+// Fix sets the action that fixes the finding. The category groups the
+// actions allowed together by an audit fix, such as terminate or create.
+func (f *Finding) Fix(category, action string, fix func() error) *Finding {
+	f.Category = category
+	f.Action = action
+	f.fix = fix
+	return f
+}
+
+// key identifies the finding when comparing audits.
+func (f *Finding) key() string {
+	return strings.Join([]string{f.Audit, f.Kind, f.ResourceType, f.Name, f.Id, f.Attribute, f.Expected, f.Actual, f.Message}, "\x00")
+}
+
+// diffFindings returns the findings in cur that aren't in prev, and the
+// findings in prev that are no longer in cur.
+func diffFindings(prev, cur []*Finding) (added, resolved []*Finding) {
+	seen := map[string]bool{}
+	for _, f := range prev {
+		seen[f.key()] = true
+	}
+	current := map[string]bool{}
+	for _, f := range cur {
+		current[f.key()] = true
+		if !seen[f.key()] {
+			seen[f.key()] = true
+			c := *f
+			c.Change = Added
+			added = append(added, &c)
+		}
+	}
+	for _, f := range prev {
+		if !current[f.key()] {
+			current[f.key()] = true
+			c := *f
+			c.Change = Resolved
+			resolved = append(resolved, &c)
+		}
+	}
+	return added, resolved
+}
@@ -0,0 +1,208 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/route"
+)
+
+// fixConfirm asks the user to confirm the fix. It is replaced by the tests.
+var fixConfirm = func(f *Finding) bool {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		msg.Detail("Unable to confirm, stdin isn't a terminal. Add allow=%s to fix without confirmation.", f.Category)
+		return false
+	}
+	return confirmFix(os.Stdin, os.Stdout, f.Action)
+}
+
+// confirmFix prompts for the fix on w and reads the answer from r.
+func confirmFix(r io.Reader, w io.Writer, action string) bool {
+	fmt.Fprintf(w, "Type yes to %s: ", action)
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
+
+// destroyConfirm asks the user to confirm the destructive fixes applied
+// without a confirmation of their own. It is replaced by the tests.
+var destroyConfirm = func(fixes []*Finding) bool {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		msg.Detail("Unable to confirm, stdin isn't a terminal. Add the yes flag to fix without confirmation.")
+		return false
+	}
+	return confirmFix(os.Stdin, os.Stdout, fmt.Sprintf("apply these %d destructive fixes", len(fixes)))
+}
+
+// destructive are the fix categories that destroy resources. They need the
+// user to be authorized to destroy in the datacenter, and approval when the
+// datacenter is protected.
+var destructive = map[string]bool{
+	"terminate": true,
+	"delete":    true,
+	"release":   true,
+}
+
+// FixRequest returns a request with the command c, made by the authenticated
+// user in the audited datacenter, for fixes that route to a resource.
+func FixRequest(c route.Command) *route.Request {
+	req := route.NewRequest(reportOptions.datacenter, UserId(), time.Now().UTC().String())
+	req.SetCommand(c)
+	return req
+}
+
+// Remedy sets the fix of the findings of type t for the named resource. It
+// lets a finding recorded by a provider be fixed by the resource above it.
+func (a *Audit) Remedy(t auditType, name, category, action string, fix func() error) {
+	if a == nil {
+		return
+	}
+	for _, f := range a.findings {
+		if f.Kind == t.String() && f.Name == name {
+			f.Fix(category, action, fix)
+		}
+	}
+}
+
+// FixAudits lists the proposed action for each finding of the audits when
+// the fix flag is given. The actions in an allowed category are applied, the
+// others once confirmed by the user. Destructive actions are only applied
+// once destroying is authorized and, in protected datacenters, approved.
+// Each action applied is accounted for.
+func FixAudits() error {
+	if !reportOptions.fix {
+		return nil
+	}
+	// Findings sharing an action, such as the rules of a secgroup, are fixed once.
+	fixes, manual, actions := []*Finding{}, 0, map[string]bool{}
+	for _, a := range audits() {
+		for _, f := range a.findings {
+			if f.fix == nil {
+				manual++
+				continue
+			}
+			if !actions[f.Action] {
+				actions[f.Action] = true
+				fixes = append(fixes, f)
+			}
+		}
+	}
+	msg.Info("Audit Fixes")
+	if len(fixes) == 0 {
+		msg.Detail("No fixes found")
+	}
+	for _, f := range fixes {
+		msg.Detail("%-10s %s", f.Category, f.Action)
+	}
+	if manual > 0 {
+		msg.Detail("%d findings have no fix and need to be resolved by hand", manual)
+	}
+
+	destroyable := destroyAllowed(fixes)
+
+	failed := 0
+	for _, f := range fixes {
+		if destructive[f.Category] && !destroyable {
+			msg.Detail("Skipped %s", f.Action)
+			continue
+		}
+		if !allowed(f) && !fixConfirm(f) {
+			msg.Detail("Skipped %s", f.Action)
+			continue
+		}
+		msg.Info("Audit Fix: %s", f.Action)
+		if err := f.fix(); err != nil {
+			msg.Error("Failed to %s: %s", f.Action, err.Error())
+			Accounting("Audit fix failed: %s: %s", f.Action, err.Error())
+			failed++
+			continue
+		}
+		msg.Detail("Fixed.")
+		Accounting("Audit fix: %s", f.Action)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d audit fixes failed", failed, len(fixes))
+	}
+	return nil
+}
+
+// allowed returns true if the fix's category is applied without confirmation.
+func allowed(f *Finding) bool {
+	return reportOptions.allow[f.Category] || reportOptions.allow["all"]
+}
+
+// destroyAllowed returns true if the destructive fixes may be applied. The
+// user needs to be authorized to destroy in the datacenter and, when it is
+// protected, the approval of a second user for the audit fix request. The
+// destructive fixes applied without a confirmation of their own are listed
+// and confirmed together unless the yes flag is given.
+func destroyAllowed(fixes []*Finding) bool {
+	unconfirmed, found := []*Finding{}, false
+	for _, f := range fixes {
+		if !destructive[f.Category] {
+			continue
+		}
+		found = true
+		if allowed(f) {
+			unconfirmed = append(unconfirmed, f)
+		}
+	}
+	if !found {
+		return true
+	}
+
+	req := FixRequest(route.Destroy)
+	req.Flags().Set(append([]string{}, reportOptions.flags...))
+	if err := Authorized(req, "datacenter", reportOptions.datacenter); err != nil {
+		msg.Error(err.Error())
+		return false
+	}
+	if err := Approved(req, "datacenter", reportOptions.datacenter); err != nil {
+		msg.Error(err.Error())
+		return false
+	}
+	if len(unconfirmed) == 0 || req.Flag("yes") {
+		return true
+	}
+	msg.Warn("Audit fixes destroy %d resources in %s:", len(unconfirmed), reportOptions.datacenter)
+	msg.IndentInc()
+	for _, f := range unconfirmed {
+		msg.Detail("%s", f.Action)
+	}
+	msg.IndentDec()
+	if !destroyConfirm(unconfirmed) {
+		msg.Info("Cancelled the destructive audit fixes")
+		return false
+	}
+	return true
+}
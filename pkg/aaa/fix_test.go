@@ -0,0 +1,166 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cisco/arc/pkg/route"
+)
+
+func TestFixAudits(t *testing.T) {
+	setupPolicy(t, "{}")
+	AuditBuffer = map[string]*Audit{}
+	accountingBuffer = nil
+	defer func() { accountingBuffer = nil }()
+
+	req := route.NewRequest("dev-1", "alice", "")
+	req.Parse([]string{"audit", "fix", "allow=release,delete"})
+	if err := AuditOptions(req); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { reportOptions.fix = false }()
+	if len(req.Flags().Get()) != 0 {
+		t.Errorf("fix flags weren't removed, got %v", req.Flags().Get())
+	}
+
+	destroyConfirmed := []string{}
+	defer func(c func([]*Finding) bool) { destroyConfirm = c }(destroyConfirm)
+	destroyConfirm = func(fixes []*Finding) bool {
+		for _, f := range fixes {
+			destroyConfirmed = append(destroyConfirmed, f.Action)
+		}
+		return true
+	}
+
+	confirmed := []string{}
+	defer func(c func(*Finding) bool) { fixConfirm = c }(fixConfirm)
+	fixConfirm = func(f *Finding) bool {
+		confirmed = append(confirmed, f.Action)
+		return f.Category == "terminate"
+	}
+
+	fixed := []string{}
+	fix := func(action string, err error) func() error {
+		return func() error {
+			fixed = append(fixed, action)
+			return err
+		}
+	}
+	if err := NewAudit("Compute"); err != nil {
+		t.Fatal(err)
+	}
+	a := AuditBuffer["Compute"]
+	a.Record(Deployed, (&Finding{Name: "web-01"}).Fix("terminate", "terminate web-01", fix("terminate web-01", nil)))
+	a.Record(Deployed, (&Finding{Name: "52.1.1.1"}).Fix("release", "release 52.1.1.1", fix("release 52.1.1.1", nil)))
+	a.Record(Deployed, (&Finding{Id: "vol-1"}).Fix("delete", "delete vol-1", fix("delete vol-1", fmt.Errorf("volume in use"))))
+	a.Record(Configured, &Finding{Name: "web-02"})
+	a.Record(Mismatched, &Finding{Name: "web", Attribute: "rule"})
+	a.Record(Mismatched, &Finding{Name: "web", Attribute: "rule", Expected: "22"})
+	a.Record(Mismatched, &Finding{Name: "db", Attribute: "rule"})
+	a.Remedy(Configured, "web-02", "create", "create web-02", fix("create web-02", nil))
+	a.Remedy(Mismatched, "web", "resync", "resync web", fix("resync web", nil))
+
+	err := FixAudits()
+	if err == nil || !strings.Contains(err.Error(), "1 of 5") {
+		t.Errorf("expected the delete to fail, got %v", err)
+	}
+	if strings.Join(confirmed, ",") != "terminate web-01,create web-02,resync web" {
+		t.Errorf("unexpected confirmations %v", confirmed)
+	}
+	if strings.Join(fixed, ",") != "terminate web-01,release 52.1.1.1,delete vol-1" {
+		t.Errorf("unexpected fixes %v", fixed)
+	}
+	if strings.Join(destroyConfirmed, ",") != "release 52.1.1.1,delete vol-1" {
+		t.Errorf("unexpected destructive confirmations %v", destroyConfirmed)
+	}
+	events := strings.Join(accountingBuffer, "\n")
+	for _, e := range []string{"Audit fix: terminate web-01", "Audit fix: release 52.1.1.1", "Audit fix failed: delete vol-1: volume in use"} {
+		if !strings.Contains(events, e) {
+			t.Errorf("accounting is missing %q, got %v", e, accountingBuffer)
+		}
+	}
+}
+
+func TestFixAuditsProtected(t *testing.T) {
+	setupPolicy(t, approvalPolicy)
+	AuditBuffer = map[string]*Audit{}
+	accountingBuffer = nil
+	defer func() { accountingBuffer = nil }()
+
+	req := route.NewRequest("prod-1", "alice", "")
+	req.Parse([]string{"audit", "fix", "allow=all", "yes"})
+	if err := AuditOptions(req); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { reportOptions.fix = false }()
+	defer func(c func([]*Finding) bool) { destroyConfirm = c }(destroyConfirm)
+	destroyConfirm = func(fixes []*Finding) bool {
+		t.Error("destructive fixes were confirmed before being approved")
+		return true
+	}
+
+	fixed := []string{}
+	fix := func(action string) func() error {
+		return func() error {
+			fixed = append(fixed, action)
+			return nil
+		}
+	}
+	if err := NewAudit("Compute"); err != nil {
+		t.Fatal(err)
+	}
+	a := AuditBuffer["Compute"]
+	a.Record(Deployed, (&Finding{Name: "web-01"}).Fix("terminate", "terminate web-01", fix("terminate web-01")))
+	a.Record(Deployed, (&Finding{Id: "vol-1"}).Fix("delete", "delete vol-1", fix("delete vol-1")))
+	a.Record(Configured, (&Finding{Name: "web-02"}).Fix("create", "create web-02", fix("create web-02")))
+
+	// Destroying in the protected datacenter needs approval, which can't be
+	// requested without an ssh agent, so only the create is applied.
+	if err := FixAudits(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(fixed, ",") != "create web-02" {
+		t.Errorf("unexpected fixes %v", fixed)
+	}
+}
+
+func TestConfirmFix(t *testing.T) {
+	out := &bytes.Buffer{}
+	if !confirmFix(strings.NewReader("yes\n"), out, "terminate web-01") {
+		t.Error("yes wasn't accepted")
+	}
+	if !strings.Contains(out.String(), "terminate web-01") {
+		t.Errorf("unexpected prompt %q", out.String())
+	}
+	if confirmFix(strings.NewReader("y\n"), out, "terminate web-01") {
+		t.Error("only yes should be accepted")
+	}
+}
@@ -27,24 +27,24 @@
 package aaa
 
 import (
-	"os/user"
-
 	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/notify"
 )
 
 var notification *config.Notifications
 
+// notifier sends the accounting and audit messages to the configured sinks.
+var notifier *notify.Notifier
+
 func Init(cfg *config.Notifications) error {
 	if cfg == nil {
 		return nil
 	}
-	notification = cfg
-	u, err := user.Current()
+	n, err := notify.New(cfg)
 	if err != nil {
 		return err
 	}
-	if err := Authenticate(u); err != nil {
-		return err
-	}
+	notification = cfg
+	notifier = n
 	return nil
 }
@@ -0,0 +1,183 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cisco/arc/pkg/log"
+)
+
+// policy is the authorization policy, read from policy.json. For example
+//
+//	{
+//	  "default": "allow",
+//	  "protected": [ "prod-*" ],
+//	  "approval_expiry": "1h",
+//	  "rules": [
+//	    { "teams": [ "ops" ], "datacenters": [ "prod-*" ], "commands": [ "*", "destroy", "replace" ] },
+//	    { "users": [ "alice" ], "resources": [ "pod:web*" ], "commands": [ "provision", "restart" ] }
+//	  ]
+//	}
+//
+// A request is authorized if a rule matches the user, datacenter, resource
+// and command. Unmatched requests are authorized when the default is allow,
+// except for destroy and replace requests in protected datacenters which
+// are only authorized by rules naming those commands explicitly. Those
+// requests also need the approval of a second user when made on a cluster
+// or pod, the approval expiry limits how long the approval remains valid.
+type policy struct {
+	Default_        string   `json:"default"`
+	Protected_      []string `json:"protected"`
+	ApprovalExpiry_ string   `json:"approval_expiry"`
+	Rules           []*rule  `json:"rules"`
+}
+
+// rule grants the listed users and members of the listed teams the listed
+// commands. Datacenters and resources are glob patterns, an empty list
+// matches everything. Resources take the form "type:name", for example
+// "pod:web*", and match the resource or any of its parents.
+type rule struct {
+	Users       []string `json:"users"`
+	Teams       []string `json:"teams"`
+	DataCenters []string `json:"datacenters"`
+	Resources   []string `json:"resources"`
+	Commands    []string `json:"commands"`
+}
+
+var authPolicy *policy
+
+// teamsOf returns the teams a user belongs to.
+var teamsOf func(user string) []string
+
+// InitPolicy loads the authorization policy from the named file. If the file
+// doesn't exist every request is authorized. The teams function returns the
+// names of the teams a user belongs to.
+func InitPolicy(name string, teams func(user string) []string) error {
+	authPolicy = nil
+	teamsOf = teams
+	file, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		log.Debug("No authorization policy %s", name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	p := &policy{}
+	if err := json.Unmarshal(file, p); err != nil {
+		return aaaError{"Unable to parse authorization policy " + name + ": " + err.Error()}
+	}
+	switch p.Default_ {
+	case "", "allow", "deny":
+	default:
+		return aaaError{"Unknown authorization policy default " + p.Default_}
+	}
+	if p.ApprovalExpiry_ != "" {
+		if _, err := time.ParseDuration(p.ApprovalExpiry_); err != nil {
+			return aaaError{"Unable to parse approval expiry " + p.ApprovalExpiry_ + ": " + err.Error()}
+		}
+	}
+	authPolicy = p
+	return nil
+}
+
+func (p *policy) deny() bool {
+	return p.Default_ == "deny"
+}
+
+func (p *policy) protected(datacenter string) bool {
+	return matchAny(p.Protected_, datacenter)
+}
+
+// approvalExpiry returns how long an approval remains valid, an hour unless
+// the policy says otherwise.
+func (p *policy) approvalExpiry() time.Duration {
+	d, err := time.ParseDuration(p.ApprovalExpiry_)
+	if err != nil {
+		return time.Hour
+	}
+	return d
+}
+
+// allows returns true if the rule grants the user the command on any of the
+// scopes. Wildcard commands don't grant protected commands.
+func (r *rule) allows(user string, teams []string, datacenter string, scopes []string, command string, protected bool) bool {
+	if !r.matchUser(user, teams) {
+		return false
+	}
+	if len(r.DataCenters) > 0 && !matchAny(r.DataCenters, datacenter) {
+		return false
+	}
+	if len(r.Resources) > 0 && !r.matchScope(scopes) {
+		return false
+	}
+	for _, c := range r.Commands {
+		if c == command || (c == "*" && !protected) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rule) matchUser(user string, teams []string) bool {
+	if matchAny(r.Users, user) {
+		return true
+	}
+	for _, t := range teams {
+		for _, rt := range r.Teams {
+			if t == rt {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *rule) matchScope(scopes []string) bool {
+	for _, scope := range scopes {
+		if matchAny(r.Resources, strings.ToLower(scope)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny returns true if s matches any of the glob patterns.
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(s)); ok {
+			return true
+		}
+	}
+	return false
+}
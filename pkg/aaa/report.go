@@ -0,0 +1,261 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/route"
+)
+
+// reportFormats are the formats the findings of an audit can be exported to.
+var reportFormats = map[string]func(io.Writer, []*Finding) error{
+	"json": writeJSON,
+	"csv":  writeCSV,
+	"html": writeHTML,
+}
+
+// reportOptions are the report formats, diff and fix modes given to the
+// audit command, and the datacenter and route path being audited. The fix categories
+// allowed are applied without confirmation.
+var reportOptions struct {
+	datacenter string
+	path       []string
+	formats    []string
+	diff       bool
+	fix        bool
+	allow      map[string]bool
+	flags      []string
+}
+
+// AuditOptions takes the report format, diff and fix flags from the audit
+// request, so they aren't passed to the resources being audited, and loads
+// the audit exceptions of the datacenter.
+func AuditOptions(req *route.Request) error {
+	reportOptions.datacenter = req.DataCenter()
+	reportOptions.path = append([]string{}, req.Path().Get()...)
+	reportOptions.flags = append([]string{}, req.Flags().Get()...)
+	reportOptions.formats = nil
+	reportOptions.diff = false
+	reportOptions.fix = false
+	reportOptions.allow = map[string]bool{}
+	for _, f := range []string{"json", "csv", "html"} {
+		if req.Flag(f) {
+			reportOptions.formats = append(reportOptions.formats, f)
+			req.Flags().Remove(f)
+		}
+	}
+	if req.Flag("diff") {
+		reportOptions.diff = true
+		req.Flags().Remove("diff")
+	}
+	if req.Flag("fix") {
+		reportOptions.fix = true
+		req.Flags().Remove("fix")
+	}
+	for _, f := range req.Flags().Get() {
+		// The yes flag and approval tokens are for the destructive fixes.
+		if f == "yes" || strings.HasPrefix(f, approvalPrefix+".") {
+			req.Flags().Remove(f)
+			continue
+		}
+		if !strings.HasPrefix(f, "allow=") {
+			continue
+		}
+		for _, c := range strings.Split(strings.TrimPrefix(f, "allow="), ",") {
+			if c != "" {
+				reportOptions.allow[c] = true
+			}
+		}
+		req.Flags().Remove(f)
+	}
+	return loadAuditExceptions(filepath.Join(env.Lookup("ROOT"), "etc", "arc", "audit_exceptions", req.DataCenter()+".json"))
+}
+
+// reportAudits compares the audits with the previous ones when diffing, saves
+// them as the latest audits of the datacenter, and exports their findings to
+// the requested formats in the run directory. The audits are saved by route
+// path, so auditing a pod or an instance doesn't replace the history of the
+// whole datacenter.
+func reportAudits(appName string) error {
+	if reportOptions.datacenter == "" {
+		return nil
+	}
+	dir := env.Lookup(strings.ToUpper(appName))
+	if dir == "" {
+		return nil
+	}
+	history := filepath.Join(append([]string{filepath.Dir(dir), "audits", reportOptions.datacenter}, reportOptions.path...)...)
+	findings := []*Finding{}
+	for _, a := range audits() {
+		name := filepath.Join(history, historyName(a.name))
+		if reportOptions.diff {
+			prev, err := loadFindings(name)
+			if err != nil {
+				return err
+			}
+			a.added, a.resolved = diffFindings(prev, a.findings)
+			a.diffed = true
+			findings = append(findings, a.added...)
+			findings = append(findings, a.resolved...)
+		} else {
+			findings = append(findings, a.findings...)
+		}
+		if err := saveFindings(name, a.findings); err != nil {
+			return err
+		}
+	}
+	for _, f := range reportOptions.formats {
+		if err := exportFindings(filepath.Join(dir, "audit."+f), reportFormats[f], findings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// historyName returns the name of the file the findings of the named audit
+// are saved to.
+func historyName(name string) string {
+	return strings.Replace(strings.ToLower(name), " ", "_", -1) + ".json"
+}
+
+// loadFindings reads the findings saved by a previous audit. There are no
+// findings if the audit hasn't been saved before.
+func loadFindings(name string) ([]*Finding, error) {
+	b, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	findings := []*Finding{}
+	if err := json.Unmarshal(b, &findings); err != nil {
+		return nil, fmt.Errorf("Unable to read the previous audit %s: %s", name, err.Error())
+	}
+	return findings, nil
+}
+
+// saveFindings replaces the saved findings of the audit.
+func saveFindings(name string, findings []*Finding) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	if findings == nil {
+		findings = []*Finding{}
+	}
+	b, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// exportFindings writes the findings to the named file.
+func exportFindings(name string, write func(io.Writer, []*Finding) error, findings []*Finding) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f, findings); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeJSON(w io.Writer, findings []*Finding) error {
+	if findings == nil {
+		findings = []*Finding{}
+	}
+	b, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+var reportColumns = []string{"audit", "kind", "change", "resource_type", "name", "id", "attribute", "expected", "actual", "message", "action"}
+
+func (f *Finding) columns() []string {
+	return []string{f.Audit, f.Kind, f.Change, f.ResourceType, f.Name, f.Id, f.Attribute, f.Expected, f.Actual, f.Message, f.Action}
+}
+
+func writeCSV(w io.Writer, findings []*Finding) error {
+	c := csv.NewWriter(w)
+	if err := c.Write(reportColumns); err != nil {
+		return err
+	}
+	for _, f := range findings {
+		if err := c.Write(f.columns()); err != nil {
+			return err
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+var htmlReport = template.Must(template.New("audit").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Audit</title>
+<style>
+table { border-collapse: collapse; font-family: sans-serif; font-size: small; }
+th, td { border: 1px solid #ccc; padding: 4px; text-align: left; vertical-align: top; }
+td.message { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Findings}}<tr><td>{{.Audit}}</td><td>{{.Kind}}</td><td>{{.Change}}</td><td>{{.ResourceType}}</td><td>{{.Name}}</td><td>{{.Id}}</td><td>{{.Attribute}}</td><td>{{.Expected}}</td><td>{{.Actual}}</td><td class="message">{{.Message}}</td><td>{{.Action}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, findings []*Finding) error {
+	return htmlReport.Execute(w, struct {
+		Columns  []string
+		Findings []*Finding
+	}{reportColumns, findings})
+}
@@ -0,0 +1,232 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aaa
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/route"
+)
+
+func TestFindingMessage(t *testing.T) {
+	tests := []struct {
+		f       Finding
+		message string
+	}{
+		{Finding{Name: "web-01"}, "web-01"},
+		{Finding{Id: "vol-1234"}, "vol-1234"},
+		{
+			Finding{ResourceType: "instance", Name: "web-01", Attribute: "image", Expected: "centos7", Actual: "centos6"},
+			`Instance "web-01" | Configured image: "centos7" - Deployed image: "centos6"`,
+		},
+	}
+	for i, test := range tests {
+		if m := test.f.message(); m != test.message {
+			t.Errorf("test %d: expected %q, got %q", i, test.message, m)
+		}
+	}
+}
+
+func TestDiffFindings(t *testing.T) {
+	a := &Finding{Audit: "Instance", Kind: "deployed", Name: "web-01", Message: "web-01"}
+	b := &Finding{Audit: "Instance", Kind: "configured", Name: "web-02", Message: "web-02"}
+	c := &Finding{Audit: "Instance", Kind: "deployed", Name: "web-03", Message: "web-03"}
+
+	added, resolved := diffFindings([]*Finding{a, b}, []*Finding{b, c, c})
+	if len(added) != 1 || added[0].Name != "web-03" || added[0].Change != Added {
+		t.Errorf("unexpected added findings %+v", added)
+	}
+	if len(resolved) != 1 || resolved[0].Name != "web-01" || resolved[0].Change != Resolved {
+		t.Errorf("unexpected resolved findings %+v", resolved)
+	}
+	if c.Change != "" {
+		t.Error("diffing changed the current findings")
+	}
+}
+
+// setupReport runs the report in the run directory root/run, saving the
+// audits in root/audits.
+func setupReport(t *testing.T, root string, params ...string) (string, *Audit) {
+	setupPolicy(t, "{}")
+	dir := filepath.Join(root, "run")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	env.Set("AAA", dir)
+	AuditBuffer = map[string]*Audit{}
+	req := route.NewRequest("dev-1", "alice", "")
+	req.Parse(params)
+	if err := AuditOptions(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Flags().Get()) != 0 {
+		t.Errorf("report flags weren't removed, got %v", req.Flags().Get())
+	}
+	if err := NewAudit("Instance"); err != nil {
+		t.Fatal(err)
+	}
+	return dir, AuditBuffer["Instance"]
+}
+
+func TestReportAudits(t *testing.T) {
+	root, err := ioutil.TempDir("", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	dir, a := setupReport(t, root, "audit", "json", "csv", "html")
+	a.Record(Configured, &Finding{ResourceType: "instance", Name: "web-01"})
+	a.Record(Mismatched, &Finding{ResourceType: "instance", Name: "web-02", Id: "i-1234", Attribute: "image", Expected: "centos7", Actual: "<centos6>"})
+	if err := reportAudits("aaa"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "audit.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := []*Finding{}
+	if err := json.Unmarshal(b, &findings); err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 2 || findings[1].Kind != "mismatched" || findings[1].Id != "i-1234" || findings[1].Audit != "Instance" {
+		t.Errorf("unexpected json findings %+v", findings)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "audit.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[0][0] != "audit" || records[1][4] != "web-01" {
+		t.Errorf("unexpected csv records %v", records)
+	}
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "audit.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte("&lt;centos6&gt;")) || bytes.Contains(b, []byte("<centos6>")) {
+		t.Error("html report isn't escaped")
+	}
+
+	saved, err := loadFindings(filepath.Join(root, "audits", "dev-1", "instance.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 {
+		t.Errorf("expected 2 saved findings, got %d", len(saved))
+	}
+}
+
+func TestReportAuditsPath(t *testing.T) {
+	root, err := ioutil.TempDir("", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	_, a := setupReport(t, root, "audit")
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "web-01"})
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "db-01"})
+	if err := reportAudits("aaa"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, a = setupReport(t, root, "pod", "web", "audit")
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "web-01"})
+	if err := reportAudits("aaa"); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := loadFindings(filepath.Join(root, "audits", "dev-1", "instance.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 {
+		t.Errorf("expected the datacenter audit to keep 2 findings, got %d", len(saved))
+	}
+	saved, err = loadFindings(filepath.Join(root, "audits", "dev-1", "pod", "web", "instance.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Name != "web-01" {
+		t.Errorf("unexpected pod audit findings %+v", saved)
+	}
+}
+
+func TestReportAuditsDiff(t *testing.T) {
+	root, err := ioutil.TempDir("", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	_, a := setupReport(t, root, "audit")
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "web-01"})
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "web-02"})
+	if err := reportAudits("aaa"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, a := setupReport(t, root, "audit", "diff", "json")
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "web-02"})
+	a.Record(Deployed, &Finding{ResourceType: "instance", Name: "web-03"})
+	if err := reportAudits("aaa"); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.added) != 1 || a.added[0].Name != "web-03" || len(a.resolved) != 1 || a.resolved[0].Name != "web-01" {
+		t.Errorf("unexpected diff, added %+v resolved %+v", a.added, a.resolved)
+	}
+
+	m := a.auditNotification("arc")
+	if len(m.Sections) != 2 || m.Sections[0].Title != "New findings" || !strings.Contains(m.Sections[0].Lines[0], "web-03") {
+		t.Errorf("unexpected diff notification %+v", m.Sections)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "audit.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := []*Finding{}
+	if err := json.Unmarshal(b, &findings); err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 2 || findings[0].Change != Added || findings[1].Change != Resolved {
+		t.Errorf("unexpected diff report %+v", findings)
+	}
+}
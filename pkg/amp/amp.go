@@ -29,13 +29,14 @@ package amp
 import (
 	"fmt"
 	"os"
-	"os/user"
 	"time"
 
+	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
 	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/help"
 	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/metrics"
 	"github.com/cisco/arc/pkg/msg"
 	"github.com/cisco/arc/pkg/resource"
 	"github.com/cisco/arc/pkg/route"
@@ -83,17 +84,21 @@ func New(cfg *config.Amp) (*amp, error) {
 // Run starts amp processing. It returns 0 for success, 1 for failure.
 // Upon failure err might be set to a non-nil value.
 func (a *amp) Run() (int, error) {
-	u, err := user.Current()
-	if err != nil {
-		return 1, err
-	}
-
-	// Create base request
-	req := route.NewRequest(a.Name(), u.Username, time.Now().UTC().String())
+	// Create base request. The user id is the authenticated user.
+	userId := aaa.UserId()
+	req := route.NewRequest(a.Name(), userId, time.Now().UTC().String())
 
 	// Parse the request from the command line.
 	req.Parse(os.Args[2:])
-	log.Info("Creating %s request for user %q", req, u.Username)
+	log.Info("Creating %s request for user %q", req, userId)
+	metrics.SetLabel("command", req.Command().String())
+
+	// The report formats and diff mode of an audit are handled by aaa.
+	if req.Command() == route.Audit {
+		if err := aaa.AuditOptions(req); err != nil {
+			return 1, err
+		}
+	}
 
 	// Load the data from the provider unless there is a Load, Help or Config command.
 	switch req.Command() {
@@ -121,6 +126,11 @@ func (a *amp) Run() (int, error) {
 		log.Info("Exiting, %s request failed\n", req)
 		return 1, nil
 	}
+	if req.Command() == route.Audit {
+		if err := aaa.FixAudits(); err != nil {
+			return 1, err
+		}
+	}
 	log.Info("Exiting successfully\n")
 	return 0, nil
 }
@@ -138,7 +148,7 @@ func (a *amp) KeyManagement() resource.KeyManagement {
 }
 
 func (a *amp) Route(req *route.Request) route.Response {
-	log.Route(req, "Amp")
+	defer log.Route(req, "Amp").End()
 
 	// Route to the appropriate resource
 	switch req.Top() {
@@ -209,6 +219,9 @@ func Help() {
 		{Name: "policy 'name'", Desc: "manage named policy"},
 		{Name: route.Info.String(), Desc: "show information about allocation amp resources"},
 		{Name: route.Config.String(), Desc: "show the amp configuration for the given account"},
+		{Name: route.Audit.String(), Desc: "audit the account, leaving out the exceptions in etc/arc/audit_exceptions/'account'.json"},
+		{Name: route.Audit.String() + " diff", Desc: "report only the findings new or resolved since the last audit"},
+		{Name: route.Audit.String() + " json|csv|html", Desc: "also export the findings to the run directory"},
 		{Name: route.Help.String(), Desc: "show this help"},
 	}
 	help.Print("", commands)
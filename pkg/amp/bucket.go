@@ -29,6 +29,7 @@ package amp
 import (
 	"fmt"
 
+	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
 	"github.com/cisco/arc/pkg/help"
 	"github.com/cisco/arc/pkg/log"
@@ -64,7 +65,13 @@ func newBucket(cfg *config.Bucket, s *storage, prov provider.Storage) (*bucket,
 // Bucket handles load, create, destroy, config and info requests by delegating them
 // to the providerBucket.
 func (b *bucket) Route(req *route.Request) route.Response {
-	log.Route(req, "Bucket %q", b.Name())
+	defer log.Route(req, "Bucket %q", b.Name()).End()
+
+	if err := aaa.Authorized(req, "bucket", b.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+
 	switch req.Command() {
 	case route.Load:
 		if err := b.Load(); err != nil {
@@ -29,6 +29,7 @@ package amp
 import (
 	"fmt"
 
+	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
 	"github.com/cisco/arc/pkg/help"
 	"github.com/cisco/arc/pkg/log"
@@ -69,7 +70,12 @@ func (b *bucketSet) Storage() resource.Storage {
 // Bucket handles load, create, destroy, config and info requests by delegating them
 // to the providerBucket.
 func (b *bucketSet) Route(req *route.Request) route.Response {
-	log.Route(req, "Bucket %q", b.Name())
+	defer log.Route(req, "Bucket %q", b.Name()).End()
+
+	if err := aaa.Authorized(req, "bucket_set", b.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
 
 	// Skip if the test flag is set
 	if req.TestFlag() {
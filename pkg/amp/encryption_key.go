@@ -28,7 +28,6 @@ package amp
 
 import (
 	"fmt"
-	"os/user"
 	"time"
 
 	"github.com/cisco/arc/pkg/aaa"
@@ -67,7 +66,13 @@ func newEncryptionKey(cfg *config.EncryptionKey, km *keyManagement, prov provide
 // EncryptionKey handles load, create, destroy, audit, config and info requests by delegating them
 // to the providerEncryptionKey.
 func (k *encryptionKey) Route(req *route.Request) route.Response {
-	log.Route(req, "Encryption Key %q", k.Name())
+	defer log.Route(req, "Encryption Key %q", k.Name()).End()
+
+	if err := aaa.Authorized(req, "encryption_key", k.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+
 	switch req.Command() {
 	case route.Load:
 		if err := k.Load(); err != nil {
@@ -182,14 +187,9 @@ func (k *encryptionKey) SetTags(t map[string]string) error {
 }
 
 func (k *encryptionKey) createSecurityTags() error {
-	u, err := user.Current()
-	if err != nil {
-		return err
-	}
-
 	tags := map[string]string{
 		"Name":          k.Name(),
-		"Created By":    u.Username,
+		"Created By":    aaa.UserId(),
 		"Last Modified": time.Now().UTC().String(),
 	}
 	for k, v := range k.KeyManagement().Amp().SecurityTags() {
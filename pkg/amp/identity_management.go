@@ -129,7 +129,7 @@ func (i *identityManagement) ProviderIdentityManagement() resource.ProviderIdent
 // IdentityManagement does not directly terminate a request so only handles load, config, and info
 // requests from it's parent.  All other commands are routed to identityManagement's children.
 func (i *identityManagement) Route(req *route.Request) route.Response {
-	log.Route(req, "Identity Management")
+	defer log.Route(req, "Identity Management").End()
 
 	// Route to the appropriate resource
 	switch req.Top() {
@@ -81,7 +81,7 @@ func newKeyManagement(amp *amp, cfg *config.KeyManagement) (*keyManagement, erro
 // KeyManagement terminates Load, Audit, Info, and Config commands. All other
 // commands are routed to keyManagement's children.
 func (k *keyManagement) Route(req *route.Request) route.Response {
-	log.Route(req, "Key Management")
+	defer log.Route(req, "Key Management").End()
 
 	switch req.Top() {
 	case "":
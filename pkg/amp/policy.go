@@ -29,6 +29,7 @@ package amp
 import (
 	"fmt"
 
+	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
 	"github.com/cisco/arc/pkg/help"
 	"github.com/cisco/arc/pkg/log"
@@ -64,7 +65,13 @@ func newPolicy(cfg *config.Policy, identityManagement *identityManagement, prov
 // Bucket handles load, create, destroy, config and info requests by delegating them
 // to the providerBucket.
 func (p *policy) Route(req *route.Request) route.Response {
-	log.Route(req, "Policy %q", p.Name())
+	defer log.Route(req, "Policy %q", p.Name()).End()
+
+	if err := aaa.Authorized(req, "policy", p.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+
 	switch req.Command() {
 	case route.Load:
 		if err := p.Load(); err != nil {
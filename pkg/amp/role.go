@@ -29,6 +29,7 @@ package amp
 import (
 	"fmt"
 
+	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
 	"github.com/cisco/arc/pkg/help"
 	"github.com/cisco/arc/pkg/log"
@@ -64,7 +65,13 @@ func newRole(cfg *config.Role, identityManagement *identityManagement, prov prov
 // Role handles load, create, destroy, provision, audit, config and info requests by delegating them
 // to the providerRole.
 func (r *role) Route(req *route.Request) route.Response {
-	log.Route(req, "Role %q", r.Name())
+	defer log.Route(req, "Role %q", r.Name()).End()
+
+	if err := aaa.Authorized(req, "role", r.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+
 	switch req.Command() {
 	case route.Load:
 		if err := r.Load(); err != nil {
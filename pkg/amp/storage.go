@@ -126,7 +126,7 @@ func (s *storage) ProviderStorage() resource.ProviderStorage {
 // Storage does not directly terminate a request so only handles load and info
 // requests from it's parent.  All other commands are routed to amp's children.
 func (s *storage) Route(req *route.Request) route.Response {
-	log.Route(req, "Storage")
+	defer log.Route(req, "Storage").End()
 
 	// Route to the appropriate resource
 	switch req.Top() {
@@ -29,13 +29,14 @@ package arc
 import (
 	"fmt"
 	"os"
-	"os/user"
 	"time"
 
+	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
 	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/help"
 	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/metrics"
 	"github.com/cisco/arc/pkg/msg"
 	"github.com/cisco/arc/pkg/resource"
 	"github.com/cisco/arc/pkg/route"
@@ -105,19 +106,24 @@ func New(cfg *config.Arc) (*arc, error) {
 // Run starts arc processing. It returns 0 for success, 1 for failure.
 // Upon failure err might be set to a non-nil value.
 func (a *arc) Run() (int, error) {
-	u, err := user.Current()
-	if err != nil {
-		return 1, err
-	}
-
-	// Create base request.
-	req := route.NewRequest(a.Name(), u.Username, time.Now().UTC().String())
+	// Create base request. The user id is the authenticated user.
+	userId := aaa.UserId()
+	req := route.NewRequest(a.Name(), userId, time.Now().UTC().String())
 
 	// Parse the request from the command line.
 	req.Parse(os.Args[2:])
-	log.Info("Creating %s request for user %q", req, u.Username)
+	log.Info("Creating %s request for user %q", req, userId)
+	metrics.SetLabel("command", req.Command().String())
+
+	// The report formats and diff mode of an audit are handled by aaa.
+	if req.Command() == route.Audit {
+		if err := aaa.AuditOptions(req); err != nil {
+			return 1, err
+		}
+	}
 
 	// Load the data from the provider unless there is a Load, Help or Config command.
+	loaded := false
 	switch req.Command() {
 	case route.None, route.Load:
 		// Invalid commands: issue a help command.
@@ -137,14 +143,23 @@ func (a *arc) Run() (int, error) {
 			return 1, fmt.Errorf("Failed to load datacenter %s", a.Name())
 		}
 		log.Info("Loading complete")
+		loaded = true
 	}
 
 	log.Info("Routing request: %q", req)
 	resp := a.Route(req)
+	if loaded {
+		a.metrics()
+	}
 	if resp != route.OK {
 		log.Info("Exiting, %s request failed\n", req)
 		return 1, nil
 	}
+	if req.Command() == route.Audit {
+		if err := aaa.FixAudits(); err != nil {
+			return 1, err
+		}
+	}
 	log.Info("Exiting successfully\n")
 	return 0, nil
 }
@@ -189,7 +204,7 @@ func (a *arc) Dns() resource.Dns {
 // All request routing is done via Route. Arc will terminate the load, help,
 // config and info requests. All other commands are routed to arc's children.
 func (a *arc) Route(req *route.Request) route.Response {
-	log.Route(req, "Arc")
+	defer log.Route(req, "Arc").End()
 
 	// Route to the appropriate resource
 	switch req.Top() {
@@ -213,6 +228,12 @@ func (a *arc) Route(req *route.Request) route.Response {
 			return route.FAIL
 		}
 		return a.containerService.Route(req.Pop())
+	case "cert":
+		if a.datacenter == nil {
+			msg.Error("Datacenter not defined in the config file")
+			return route.FAIL
+		}
+		return a.routeCert(req.Pop())
 	case "dns":
 		if a.dns == nil {
 			msg.Error("Dns not defined in the config file")
@@ -245,6 +266,9 @@ func (a *arc) Route(req *route.Request) route.Response {
 		return route.OK
 	case route.Audit:
 		return a.RouteInOrder(req)
+	case route.RevokeExpired:
+		// See grant.go
+		return a.revokeExpired(req)
 	default:
 		msg.Error("Unknown arc command %q.", req.Command().String())
 	}
@@ -268,11 +292,20 @@ func Help() {
 		{Name: "db 'name'", Desc: "manage named database service"},
 		{Name: "container", Desc: "manage container service"},
 		{Name: "dns", Desc: "manage dns"},
+		{Name: "cert", Desc: "manage ssh user certificates"},
 		{Name: route.Config.String(), Desc: "show the arc configuration for the given datacenter"},
 		{Name: route.Info.String(), Desc: "show information about allocated arc resources"},
+		{Name: route.RevokeExpired.String(), Desc: "revoke the pod grants that have expired"},
+		{Name: route.Audit.String(), Desc: "audit the datacenter, leaving out the exceptions in etc/arc/audit_exceptions/'datacenter'.json"},
+		{Name: route.Audit.String() + " diff", Desc: "report only the findings new or resolved since the last audit"},
+		{Name: route.Audit.String() + " json|csv|html", Desc: "also export the findings to the run directory"},
+		{Name: route.Audit.String() + " fix [yes]", Desc: "fix the findings once confirmed, yes confirms the terminate, release and delete fixes, which need approval in protected datacenters"},
+		{Name: route.Audit.String() + " fix allow=category,...", Desc: "fix the findings of the categories, such as create or resync, without confirmation"},
 		{Name: route.Help.String(), Desc: "show this help"},
 	}
 	help.Print("", commands)
+	fmt.Printf("\nDestroy and replace requests in protected datacenters need approval, run\n\n  arc approve 'token'\n\nto approve the request token given to another user.\n\n")
+	fmt.Printf("Every run is recorded in a hash chained audit log, run\n\n  arc audit-log verify\n  arc audit-log show [user=name] [datacenter=name] [resource=id] [since=time] [until=time]\n\nto verify the log or show the matching entries.\n\n")
 }
 
 func (a *arc) config() {
@@ -0,0 +1,251 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package arc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cryptossh "golang.org/x/crypto/ssh"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/help"
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/route"
+	"github.com/cisco/arc/pkg/ssh"
+	"github.com/cisco/arc/pkg/users"
+)
+
+const (
+	defaultCertTTL = 8 * time.Hour
+	maxCertTTL     = 24 * time.Hour
+)
+
+// The certificate authorities loaded in this run, indexed by datacenter.
+var cas = map[string]*ssh.CA{}
+
+// loadCA loads the ssh certificate authority of the datacenter. The key is
+// shared by all operators, gpg encrypted in the arc repo at
+// etc/arc/ssh_ca/<datacenter>.gpg like the spark token. A missing key fails
+// rather than being generated, since instances trust a single key and a new
+// one would lock out the users holding certificates signed by the other.
+func loadCA(datacenter string) (*ssh.CA, error) {
+	if ca := cas[datacenter]; ca != nil {
+		return ca, nil
+	}
+	root := env.Lookup("ROOT")
+	file := filepath.Join(root, "etc", "arc", "ssh_ca", datacenter+".gpg")
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, fmt.Errorf("The ssh certificate authority key %s doesn't exist. Create it in etc/arc/ssh_ca with "+
+			"\"ssh-keygen -t ecdsa -b 256 -m PEM -N '' -f %s\", encrypt it with \"encrypt_file %s <gpg uids>\", "+
+			"remove the unencrypted key and commit %s.gpg.", file, datacenter, datacenter, datacenter)
+	}
+	b, err := exec.Command(filepath.Join(root, "usr", "local", "bin", "decrypt_file"), file).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decrypt the ssh certificate authority key %s: %s", file, err.Error())
+	}
+	ca, err := ssh.ParseCA(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	cas[datacenter] = ca
+	return ca, nil
+}
+
+// routeCert handles the "cert" requests.
+func (a *arc) routeCert(req *route.Request) route.Response {
+	if req.Top() != "" {
+		certHelp()
+		return route.FAIL
+	}
+	if err := aaa.Authorized(req, "cert", a.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+	if req.TestFlag() {
+		msg.Detail("Test. Skipping...")
+		return route.OK
+	}
+
+	switch req.Command() {
+	case route.Load:
+		return route.OK
+	case route.Help:
+		certHelp()
+		return route.OK
+	case route.Info:
+		if !a.DataCenter().SshCa() {
+			return route.OK
+		}
+		ca, err := loadCA(req.DataCenter())
+		if err != nil {
+			msg.Error(err.Error())
+			return route.FAIL
+		}
+		msg.Info("SSH Certificate Authority")
+		msg.IndentInc()
+		msg.Detail("%s", ca.PublicKey())
+		msg.IndentDec()
+		return route.OK
+	case route.Issue:
+		return a.issueCert(req)
+	default:
+		msg.Error("Unknown cert command %q.", req.Command().String())
+	}
+	return route.FAIL
+}
+
+func certHelp() {
+	commands := []help.Command{
+		{Name: route.Issue.String() + " [user] [duration] [key=file]", Desc: "sign short lived certificates for the user's ssh keys"},
+		{Name: route.Info.String(), Desc: "show the ssh certificate authority public key"},
+		{Name: route.Help.String(), Desc: "provide this help"},
+	}
+	help.Print("cert", commands)
+}
+
+// certFlags parses "issue [user] [duration] [key=file]". The user defaults to
+// the requesting user and the duration to defaultCertTTL.
+func certFlags(req *route.Request) (string, time.Duration, string, error) {
+	name := req.UserId()
+	ttl := defaultCertTTL
+	key := ""
+	for _, f := range req.Flags().Get() {
+		if strings.HasPrefix(f, "key=") {
+			key = strings.TrimPrefix(f, "key=")
+			continue
+		}
+		if d, err := time.ParseDuration(f); err == nil {
+			if d <= 0 || d > maxCertTTL {
+				return "", 0, "", fmt.Errorf("Certificate duration must be positive and at most %s, got %q", maxCertTTL, f)
+			}
+			ttl = d
+			continue
+		}
+		name = f
+	}
+	return name, ttl, key, nil
+}
+
+// issueCert signs certificates for the user's configured ssh keys, valid for
+// the principals derived from the user's teams and active grants. The
+// certificates are written to the run directory, or next to the public key
+// file if one is given, where ssh picks them up.
+func (a *arc) issueCert(req *route.Request) route.Response {
+	msg.Info("Certificate Issue: %s", a.Name())
+	if !a.DataCenter().SshCa() {
+		msg.Error("The ssh certificate authority isn't enabled for datacenter %s", a.Name())
+		return route.FAIL
+	}
+	name, ttl, keyFile, err := certFlags(req)
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	keys := users.KeysOf(name)
+	if len(keys) == 0 {
+		msg.Error("User %s is not defined or has no ssh keys.", name)
+		return route.FAIL
+	}
+	grants, err := loadGrants(req.DataCenter())
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	principals := users.Principals(name, grants, time.Now())
+	if len(principals) == 0 {
+		msg.Error("User %s isn't a member of any team and has no grants.", name)
+		return route.FAIL
+	}
+
+	configured := map[string]cryptossh.PublicKey{}
+	for _, k := range keys {
+		pub, _, _, _, err := cryptossh.ParseAuthorizedKey([]byte(k))
+		if err != nil {
+			msg.Warn("Skipping malformed ssh key of user %s: %s", name, err.Error())
+			continue
+		}
+		configured[string(pub.Marshal())] = pub
+	}
+
+	// The certificates to issue, indexed by the file they are written to.
+	certs := map[string]cryptossh.PublicKey{}
+	if keyFile != "" {
+		b, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			msg.Error(err.Error())
+			return route.FAIL
+		}
+		pub, _, _, _, err := cryptossh.ParseAuthorizedKey(b)
+		if err != nil {
+			msg.Error("%s: %s", keyFile, err.Error())
+			return route.FAIL
+		}
+		if configured[string(pub.Marshal())] == nil {
+			msg.Error("%s isn't an ssh key of user %s", keyFile, name)
+			return route.FAIL
+		}
+		certs[strings.TrimSuffix(keyFile, ".pub")+"-cert.pub"] = pub
+	} else {
+		n := 0
+		for _, k := range keys {
+			pub, _, _, _, err := cryptossh.ParseAuthorizedKey([]byte(k))
+			if err != nil {
+				continue
+			}
+			n++
+			certs[filepath.Join(env.Lookup("ARC"), fmt.Sprintf("%s-%d-cert.pub", name, n))] = pub
+		}
+	}
+
+	ca, err := loadCA(req.DataCenter())
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	for file, pub := range certs {
+		b, cert, err := ca.Sign(pub, name, principals, ttl)
+		if err != nil {
+			msg.Error(err.Error())
+			return route.FAIL
+		}
+		if err := ioutil.WriteFile(file, []byte(b), 0644); err != nil {
+			msg.Error(err.Error())
+			return route.FAIL
+		}
+		until := time.Unix(int64(cert.ValidBefore), 0).UTC().Format(time.RFC3339)
+		msg.Detail("Certificate %s for %s until %s", file, strings.Join(principals, ", "), until)
+		aaa.Accounting("Certificate issued: %s, key %s, principals %s until %s", name, cryptossh.FingerprintSHA256(pub), strings.Join(principals, ", "), until)
+	}
+	return route.OK
+}
@@ -134,7 +134,7 @@ func (c *Cluster) SetDerived(d resource.Cluster) {
 // info requests in order to manage the cluster. All other commands are routed to
 // the cluster's pods.
 func (c *Cluster) Route(req *route.Request) route.Response {
-	log.Route(req, "Cluster %q", c.Name())
+	defer log.Route(req, "Cluster %q", c.Name()).End()
 
 	// Route to the appropriate resource. Pod is the only valid sub-resource.
 	if req.Top() == "pod" {
@@ -167,6 +167,19 @@ func (c *Cluster) Route(req *route.Request) route.Response {
 		return route.OK
 	}
 
+	if err := aaa.Approved(req, "cluster", c.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+
+	pods := []resource.Pod{}
+	for _, r := range c.pods.Get() {
+		pods = append(pods, r.(resource.Pod))
+	}
+	if !confirm(req, "cluster "+c.Name(), pods) {
+		return route.FAIL
+	}
+
 	switch req.Command() {
 	case route.Load:
 		return c.RouteInOrder(req)
@@ -193,6 +206,11 @@ func (c *Cluster) Route(req *route.Request) route.Response {
 		return c.restart(req)
 	case route.Replace:
 		return c.replace(req)
+	case route.Patch:
+		return c.patch(req)
+	case route.Compliance:
+		msg.Info("Cluster Compliance: %s", c.Name())
+		return c.routeToChildren(req)
 	default:
 		msg.Error("Unknown cluster command %q.", req.Command().String())
 	}
@@ -218,12 +236,15 @@ func (c *Cluster) help() {
 		{Name: route.Create.String(), Desc: fmt.Sprintf("create %s cluster", c.Name())},
 		{Name: route.Provision.String(), Desc: fmt.Sprintf("provision %s cluster", c.Name())},
 		{Name: route.Provision.String() + " users", Desc: fmt.Sprintf("update %s cluster users", c.Name())},
+		{Name: route.Provision.String() + " changed", Desc: fmt.Sprintf("provision %s cluster instances not at the configured versions", c.Name())},
 		{Name: route.Start.String(), Desc: fmt.Sprintf("start %s cluster", c.Name())},
-		{Name: route.Stop.String(), Desc: fmt.Sprintf("stop %s cluster", c.Name())},
+		{Name: route.Stop.String() + " [yes]", Desc: fmt.Sprintf("stop %s cluster, yes skips confirmation", c.Name())},
 		{Name: route.Restart.String(), Desc: fmt.Sprintf("restart %s cluster", c.Name())},
-		{Name: route.Replace.String(), Desc: fmt.Sprintf("replace %s cluster", c.Name())},
+		{Name: route.Replace.String() + " [yes]", Desc: fmt.Sprintf("replace %s cluster, yes skips confirmation", c.Name())},
+		{Name: route.Patch.String() + " [batch]", Desc: fmt.Sprintf("patch the %s cluster operating systems, a batch of instances per pod at a time", c.Name())},
 		{Name: route.Audit.String(), Desc: fmt.Sprintf("audit %s cluster", c.Name())},
-		{Name: route.Destroy.String(), Desc: fmt.Sprintf("destroy %s cluster", c.Name())},
+		{Name: route.Compliance.String() + " [fix]", Desc: fmt.Sprintf("check %s cluster hardening, optionally re-applying it", c.Name())},
+		{Name: route.Destroy.String() + " [yes]", Desc: fmt.Sprintf("destroy %s cluster, yes skips confirmation", c.Name())},
 		{Name: route.Config.String(), Desc: fmt.Sprintf("provide the %s cluster configuration", c.Name())},
 		{Name: route.Info.String(), Desc: fmt.Sprintf("provide information about allocated %s cluster", c.Name())},
 		{Name: route.Help.String(), Desc: "provide this help"},
@@ -484,6 +505,40 @@ func (c *Cluster) PostReplace(req *route.Request) route.Response {
 	return route.OK
 }
 
+// Patch
+
+func (c *Cluster) patch(req *route.Request) route.Response {
+	msg.Info("Cluster Patch: %s", c.Name())
+	if c.Destroyed() {
+		msg.Detail("Cluster does not exist, skipping...")
+		return route.OK
+	}
+	if resp := c.Derived().PrePatch(req); resp != route.OK {
+		return resp
+	}
+	if resp := c.Derived().Patch(req); resp != route.OK {
+		return resp
+	}
+	if resp := c.Derived().PostPatch(req); resp != route.OK {
+		return resp
+	}
+	msg.Detail("Cluster Patched: %s", c.Name())
+	aaa.Accounting("Cluster patched: %s", c.Name())
+	return route.OK
+}
+
+func (c *Cluster) PrePatch(req *route.Request) route.Response {
+	return route.OK
+}
+
+func (c *Cluster) Patch(req *route.Request) route.Response {
+	return c.routeToChildren(req)
+}
+
+func (c *Cluster) PostPatch(req *route.Request) route.Response {
+	return route.OK
+}
+
 // Audit
 
 func (c *Cluster) Audit(flags ...string) error {
@@ -113,7 +113,7 @@ func (c *clusters) FindInstanceByIP(ip string) resource.Instance {
 
 // Route satisfies the embedded resource.Resource interface in resource.Clusters.
 func (c *clusters) Route(req *route.Request) route.Response {
-	log.Route(req, "Clusters")
+	defer log.Route(req, "Clusters").End()
 
 	// Route to the appropriate resource. First try follow the resource path.
 	switch req.Top() {
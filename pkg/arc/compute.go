@@ -205,7 +205,7 @@ func (c *compute) ProviderCompute() resource.ProviderCompute {
 // Compute terminates load, create, destroy, help, config and info commands.
 // All other commands are routed to compute's children.
 func (c *compute) Route(req *route.Request) route.Response {
-	log.Route(req, "Compute")
+	defer log.Route(req, "Compute").End()
 
 	switch req.Top() {
 	case "":
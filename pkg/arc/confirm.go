@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package arc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/resource"
+	"github.com/cisco/arc/pkg/route"
+)
+
+// affecter is satisfied by instances able to list the resources affected
+// when they are destroyed, replaced or stopped.
+type affecter interface {
+	affected() []string
+}
+
+// confirm prints the instances, volumes, elastic IPs and dns records of the
+// pods affected by a destroy, replace or stop request and asks the user to
+// confirm it. The prompt is skipped if the yes flag is given, and refused if
+// stdin isn't a terminal. Once confirmed the yes flag is added to the request
+// so the children of the resource aren't asked again.
+func confirm(req *route.Request, name string, pods []resource.Pod) bool {
+	if !confirmable(req) {
+		return true
+	}
+
+	instances, details := 0, []string{}
+	for _, p := range pods {
+		m := p.Instances().GetInstances()
+		names := []string{}
+		for n := range m {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			i := m[n]
+			if i.Destroyed() {
+				continue
+			}
+			instances++
+			if a, ok := i.(affecter); ok {
+				details = append(details, a.affected()...)
+				continue
+			}
+			details = append(details, fmt.Sprintf("instance %s %s", i.Name(), i.Id()))
+		}
+		for _, c := range p.DnsCNameRecords() {
+			details = append(details, fmt.Sprintf("dns cname record %s", c.Id()))
+		}
+	}
+	if instances == 0 {
+		return true
+	}
+	return prompt(req, name, fmt.Sprintf("%d instances", instances), details)
+}
+
+// confirmResources prints the resources affected by a destroy, replace or
+// stop request of the named resource and asks the user to confirm it, the
+// same as confirm. Nothing is asked if no resources are affected.
+func confirmResources(req *route.Request, name string, details []string) bool {
+	if !confirmable(req) || len(details) == 0 {
+		return true
+	}
+	n := 0
+	for _, d := range details {
+		if !strings.HasPrefix(d, " ") {
+			n++
+		}
+	}
+	return prompt(req, name, fmt.Sprintf("%d resources", n), details)
+}
+
+// confirmable returns true for destroy, replace and stop requests which
+// haven't been confirmed with the yes flag.
+func confirmable(req *route.Request) bool {
+	switch req.Command() {
+	case route.Destroy, route.Replace, route.Stop:
+		return !req.Flag("yes")
+	}
+	return false
+}
+
+// prompt prints the affected resources and asks the user to type yes.
+func prompt(req *route.Request, name, affects string, details []string) bool {
+	msg.Warn("%s %s affects %s in %s:", strings.Title(req.Command().String()), name, affects, req.DataCenter())
+	msg.IndentInc()
+	for _, d := range details {
+		msg.Detail("%s", d)
+	}
+	msg.IndentDec()
+
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		msg.Error("Unable to confirm the request, stdin isn't a terminal. Add the yes flag to run it without confirmation.")
+		return false
+	}
+	fmt.Printf("\nType yes to %s %s: ", req.Command(), name)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(answer) != "yes" {
+		msg.Info("Cancelled %s %s", req.Command(), name)
+		return false
+	}
+	req.Flags().Append("yes")
+	return true
+}
+
+// affected lists the instance and its volumes, elastic IP and dns records.
+func (i *Instance) affected() []string {
+	l := []string{fmt.Sprintf("instance %s %s %s", i.Name(), i.Id(), i.PrivateIPAddress())}
+	if i.volumes != nil {
+		for _, r := range i.volumes.Get() {
+			v := r.(resource.Volume)
+			if v.Id() == "" {
+				continue
+			}
+			mount := v.MountPoint()
+			if v.Boot() {
+				mount = "/"
+			}
+			l = append(l, fmt.Sprintf("  volume %s %s %dGB %s", v.Id(), v.Device(), v.Size(), mount))
+		}
+	}
+	if i.eip != nil && i.eip.Id() != "" {
+		l = append(l, fmt.Sprintf("  elastic ip %s %s", i.eip.Id(), i.eip.IpAddress()))
+	}
+	if i.privateARecord != nil {
+		l = append(l, fmt.Sprintf("  dns a record %s", i.privateARecord.Id()))
+	}
+	if i.publicARecord != nil {
+		l = append(l, fmt.Sprintf("  dns a record %s", i.publicARecord.Id()))
+	}
+	return l
+}
@@ -75,7 +75,7 @@ func newContainerService(cfg *config.ContainerService, arc *arc) (*containerServ
 
 // Route satisfies the resource.ContainerService interface.
 func (cs *containerService) Route(req *route.Request) route.Response {
-	log.Route(req, "ContainerService")
+	defer log.Route(req, "ContainerService").End()
 
 	if req.Top() != "" {
 		cs.Help()
@@ -88,6 +88,14 @@ func (cs *containerService) Route(req *route.Request) route.Response {
 		return route.OK
 	}
 
+	if err := aaa.Approved(req, "container", cs.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+	if !cs.Destroyed() && !confirmResources(req, "container service "+cs.Name(), []string{"container service " + cs.Name()}) {
+		return route.FAIL
+	}
+
 	// Commands that can be handled locally
 	switch req.Command() {
 	case route.Load:
@@ -128,7 +128,7 @@ func newDatabase(cfg *config.Database, dbs *databaseService, p provider.Database
 
 // Route satisfies the resource.Database interface.
 func (db *database) Route(req *route.Request) route.Response {
-	log.Route(req, "Database %q", db.Name())
+	defer log.Route(req, "Database %q", db.Name()).End()
 
 	if req.Top() != "" {
 		db.Help()
@@ -141,6 +141,14 @@ func (db *database) Route(req *route.Request) route.Response {
 		return route.OK
 	}
 
+	if err := aaa.Approved(req, "database", db.Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+	if !db.Destroyed() && !confirmResources(req, "database "+db.Name(), []string{fmt.Sprintf("database %s %s", db.Name(), db.Id())}) {
+		return route.FAIL
+	}
+
 	// Commands that can be handled locally
 	switch req.Command() {
 	case route.Load:
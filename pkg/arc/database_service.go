@@ -84,7 +84,7 @@ func newDatabaseService(cfg *config.DatabaseService, arc *arc) (*databaseService
 
 // Route satisfies the resource.DatabaseService interface.
 func (dbs *databaseService) Route(req *route.Request) route.Response {
-	log.Route(req, "DatabaseService")
+	defer log.Route(req, "DatabaseService").End()
 
 	// Route to the appropriate resource
 	switch req.Top() {
@@ -170,7 +170,7 @@ func (d *dataCenter) Dns() resource.Dns {
 // DataCenter does not directly terminate a request so only handles load and info
 // requests from it's parent.  All other commands are routed to arc's children.
 func (d *dataCenter) Route(req *route.Request) route.Response {
-	log.Route(req, "DataCenter")
+	defer log.Route(req, "DataCenter").End()
 
 	// Route to the appropriate resource
 	switch req.Top() {
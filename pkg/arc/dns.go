@@ -28,6 +28,7 @@ package arc
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
@@ -173,7 +174,26 @@ func (d *dns) DataCenter() resource.DataCenter {
 // DataCenter does not directly terminate a request so only handles load and info
 // requests from it's parent.  All other commands are routed to arc's children.
 func (d *dns) Route(req *route.Request) route.Response {
-	log.Route(req, "Dns")
+	defer log.Route(req, "Dns").End()
+
+	// Is the user associated with the request allowed to do dns commands?
+	if err := aaa.Authorized(req, "dns", d.Domain()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+
+	// The records are approved and confirmed here as well, since they are
+	// destroyed through dns.
+	if !req.TestFlag() {
+		if err := aaa.Approved(req, "dns", d.Domain()); err != nil {
+			msg.Error(err.Error())
+			return route.UNAUTHORIZED
+		}
+		records := strings.TrimSpace("dns records " + strings.Join(req.Path().Get(), " "))
+		if !confirmResources(req, "dns "+d.Domain(), []string{records}) {
+			return route.FAIL
+		}
+	}
 
 	// Route to the appropriate resource
 	switch req.Top() {
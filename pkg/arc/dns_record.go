@@ -124,7 +124,7 @@ func (r *dnsRecord) DynamicValues() []string {
 
 // Route satisfies the embedded resource.Resource interface in resource.DnsRecord.
 func (r *dnsRecord) Route(req *route.Request) route.Response {
-	log.Route(req, "DnsRecord %s %q", r.Type(), r.Name())
+	defer log.Route(req, "DnsRecord %s %q", r.Type(), r.Name()).End()
 
 	// The path should be empty at this point.
 	if req.Top() != "" {
@@ -128,7 +128,7 @@ func (d *dnsRecords) Type() string {
 
 // Route satisfies the embedded resource.Resource interface in resource.DnsRecords.
 func (d *dnsRecords) Route(req *route.Request) route.Response {
-	log.Route(req, "Dns %s Records", d.recordType)
+	defer log.Route(req, "Dns %s Records", d.recordType).End()
 
 	// Is the resource the name of a dns record?
 	if record := d.Find(req.Top()); record != nil {
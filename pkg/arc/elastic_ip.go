@@ -55,7 +55,7 @@ func newElasticIP(i resource.Instance, prov provider.DataCenter) (*elasticIP, er
 // ElasticIP handles load, create, destroy, and info requests by delegating them
 // to the providerElasticIP.
 func (e *elasticIP) Route(req *route.Request) route.Response {
-	log.Route(req, "ElasticIP")
+	defer log.Route(req, "ElasticIP").End()
 	return route.FAIL
 }
 
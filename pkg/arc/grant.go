@@ -0,0 +1,172 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package arc
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/resource"
+	"github.com/cisco/arc/pkg/route"
+	"github.com/cisco/arc/pkg/users"
+)
+
+// loadGrants loads the grant store of the datacenter, which is kept with the
+// arc run directories so it outlives the runs.
+func loadGrants(datacenter string) (*users.Grants, error) {
+	dir := filepath.Dir(env.Lookup("ARC"))
+	return users.LoadGrants(filepath.Join(dir, "grants", datacenter+".json"))
+}
+
+// grantFlags parses "grant 'user' [sudo] 'duration'", returning the user,
+// whether sudo is granted and for how long.
+func grantFlags(req *route.Request) (*users.User, bool, time.Duration, error) {
+	var user *users.User
+	var sudo bool
+	var d time.Duration
+	for _, f := range req.Flags().Get() {
+		if f == "sudo" {
+			sudo = true
+			continue
+		}
+		if v, err := time.ParseDuration(f); err == nil {
+			if v <= 0 {
+				return nil, false, 0, fmt.Errorf("Grant duration must be positive, got %q", f)
+			}
+			d = v
+			continue
+		}
+		u := users.Users[f]
+		if u == nil || u.Remove {
+			return nil, false, 0, fmt.Errorf("User %s is not defined.", f)
+		}
+		user = u
+	}
+	if user == nil || d == 0 {
+		return nil, false, 0, fmt.Errorf("Grant requires a user and a duration such as 4h, and optionally sudo")
+	}
+	return user, sudo, d, nil
+}
+
+// grant gives a user access to the pod's instances for a limited time. The
+// grant is recorded before the user is pushed so a partially applied grant
+// is still revoked by revoke-expired.
+func (p *Pod) grant(req *route.Request) route.Response {
+	msg.Info("Pod Grant: %s", p.Name())
+	if p.Destroyed() {
+		msg.Detail("Pod does not exist, skipping...")
+		return route.OK
+	}
+	user, sudo, d, err := grantFlags(req)
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	grants, err := loadGrants(req.DataCenter())
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	g := &users.Grant{
+		User:      user.Name,
+		Pod:       p.Name(),
+		Sudo:      sudo,
+		GrantedBy: req.UserId(),
+		Granted:   now,
+		Expires:   now.Add(d),
+	}
+	grants.Add(g)
+	if err := grants.Save(); err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	if resp := p.routeToChildren(req); resp != route.OK {
+		return resp
+	}
+	msg.Detail("Granted %s", g)
+	aaa.Accounting("Pod grant: %s", g)
+	return route.OK
+}
+
+// revoke undoes an expired grant to the pod's instances. See arc.revokeExpired.
+func (p *Pod) revoke(req *route.Request) route.Response {
+	msg.Info("Pod Revoke: %s", p.Name())
+	if p.Destroyed() {
+		msg.Detail("Pod does not exist, skipping...")
+		return route.OK
+	}
+	return p.routeToChildren(req)
+}
+
+// revokeExpired revokes the datacenter's grants that have expired. Grants to
+// pods that no longer exist are dropped. Grants that fail to be revoked are
+// kept so the next run retries them.
+func (a *arc) revokeExpired(req *route.Request) route.Response {
+	msg.Info("Revoke Expired Grants: %s", a.Name())
+	grants, err := loadGrants(req.DataCenter())
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	expired := grants.Expired(time.Now())
+	if len(expired) == 0 {
+		msg.Detail("No expired grants")
+		return route.OK
+	}
+
+	resp := route.OK
+	for _, g := range expired {
+		var pod resource.Pod
+		if a.datacenter != nil {
+			pod = a.DataCenter().Compute().Clusters().FindPod(g.Pod)
+		}
+		if pod == nil {
+			msg.Warn("Pod %s no longer exists, dropping the grant to %s", g.Pod, g.User)
+		} else {
+			r := req.Clone(route.RevokeExpired)
+			r.Flags().Set([]string{g.User})
+			if pod.Route(r) != route.OK {
+				msg.Error("Failed to revoke %s", g)
+				resp = route.FAIL
+				continue
+			}
+		}
+		grants.Remove(g)
+		msg.Detail("Revoked %s", g)
+		aaa.Accounting("Grant revoked: %s", g)
+	}
+	if err := grants.Save(); err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	return resp
+}
@@ -29,6 +29,7 @@ package arc
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
@@ -69,6 +70,7 @@ type Instance struct {
 	providerInstance resource.ProviderInstance
 	privateARecord   *dnsRecord
 	publicARecord    *dnsRecord
+	rebootRequired   bool
 	derived_         resource.Instance
 }
 
@@ -335,6 +337,13 @@ func (i *Instance) SetTags(t map[string]string) error {
 	return i.providerInstance.SetTags(t)
 }
 
+func (i *Instance) Tags() map[string]string {
+	if i.providerInstance == nil {
+		return map[string]string{}
+	}
+	return i.providerInstance.Tags()
+}
+
 func (i *Instance) RootUser() string {
 	switch {
 	case strings.HasPrefix(i.Image(), "centos"):
@@ -373,13 +382,18 @@ func (i *Instance) SetDerived(d resource.Instance) {
 }
 
 func (i *Instance) Route(req *route.Request) route.Response {
-	log.Route(req, "Instance %q", i.Name())
+	defer log.Route(req, "Instance %q", i.Name()).End()
 
 	if req.Top() != "" {
 		i.help()
 		return route.FAIL
 	}
 
+	if err := aaa.Authorized(req, "instance", i.Name(), "pod:"+i.Pod().Name(), "cluster:"+i.Pod().Cluster().Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+
 	// Skip if the test flag is set.
 	if req.TestFlag() {
 		msg.Detail("Test. Skipping...")
@@ -432,6 +446,21 @@ func (i *Instance) Route(req *route.Request) route.Response {
 	case route.Replace:
 		// See instance_replace.go
 		return i.replace(req)
+	case route.Maintenance:
+		// See instance_paging.go
+		return i.maintenance(req)
+	case route.Patch:
+		// See instance_patch.go
+		return i.patch(req)
+	case route.Compliance:
+		// See instance_compliance.go
+		return i.compliance(req)
+	case route.Grant:
+		// See instance_users.go
+		return i.grant(req)
+	case route.RevokeExpired:
+		// See instance_users.go
+		return i.revoke(req)
 	case route.Audit:
 		// See instance_audit.go
 		err := aaa.NewAudit("Instance")
@@ -536,7 +565,11 @@ func instanceHelp(n string) {
 		{Name: route.Stop.String(), Desc: fmt.Sprintf("stop%s instance", name)},
 		{Name: route.Restart.String(), Desc: fmt.Sprintf("restart%s instance", name)},
 		{Name: route.Replace.String(), Desc: fmt.Sprintf("replace%s instance", name)},
+		{Name: route.Patch.String(), Desc: fmt.Sprintf("patch the%s instance operating system", name)},
+		{Name: route.Maintenance.String() + " [duration]", Desc: fmt.Sprintf("disable paging for%s instance for the duration", name)},
+		{Name: route.Maintenance.String() + " end", Desc: fmt.Sprintf("end the%s instance maintenance window", name)},
 		{Name: route.Audit.String(), Desc: fmt.Sprintf("audit%s instance", name)},
+		{Name: route.Compliance.String() + " [fix]", Desc: fmt.Sprintf("check%s instance hardening, optionally re-applying it", name)},
 		{Name: route.Destroy.String(), Desc: fmt.Sprintf("destroy%s instance", name)},
 		{Name: route.Config.String(), Desc: fmt.Sprintf("provide the%s instance configuration", name)},
 		{Name: route.Info.String(), Desc: fmt.Sprintf("provide information about allocated%s instance", name)},
@@ -572,6 +605,12 @@ func (i *Instance) info() {
 	if i.roleIdentifier.Name() != "" {
 		msg.Detail("%-20s\t%s", "role", i.roleIdentifier.Name())
 	}
+	if until := i.maintenanceUntil(); time.Now().Before(until) {
+		msg.Detail("%-20s\t%s", "maintenance until", until.Format(time.RFC3339))
+	}
+	for _, d := range i.Derived().VersionDrift() {
+		msg.Detail("%-20s\t%s", "version drift", d)
+	}
 	if i.volumes != nil {
 		i.volumes.info()
 	}
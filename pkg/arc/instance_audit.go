@@ -26,6 +26,13 @@
 
 package arc
 
+import (
+	"fmt"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/route"
+)
+
 func (i *Instance) Audit(flags ...string) error {
 	if i.Derived().Pod().Cluster().AuditIgnore() {
 		return nil
@@ -51,6 +58,13 @@ func (i *Instance) MidAudit(flags ...string) error {
 		return err
 	}
 	if !i.Created() {
+		// The instance is configured but not created, an audit fix creates it.
+		aaa.AuditBuffer[flags[0]].Remedy(aaa.Configured, i.Name(), "create", fmt.Sprintf("create instance %s", i.Name()), func() error {
+			if i.Derived().Route(aaa.FixRequest(route.Create)) != route.OK {
+				return fmt.Errorf("Unable to create instance %s", i.Name())
+			}
+			return nil
+		})
 		return nil
 	}
 	if err := i.volumes.Audit(flags...); err != nil {
@@ -0,0 +1,136 @@
+//
+// Copyright (c) 2017, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package arc
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/command"
+	"github.com/cisco/arc/pkg/compliance"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/route"
+)
+
+// compliance runs the hardening compliance checks on the instance. If the fix
+// flag is set and checks fail, the hardening for the failed categories is
+// re-applied and the checks are run again.
+func (i *Instance) compliance(req *route.Request) route.Response {
+	msg.Info("Instance Compliance: %s", i.Name())
+	if i.Destroyed() {
+		msg.Detail("Instance does not exist, skipping...")
+		return route.OK
+	}
+	if !i.Started() {
+		msg.Detail("Instance is not running, skipping...")
+		return route.OK
+	}
+	report, resp := i.checkCompliance()
+	if resp != route.OK {
+		return resp
+	}
+	if req.Flag("fix") && len(report.Failed()) > 0 {
+		if resp := i.fixCompliance(req, report); resp != route.OK {
+			return resp
+		}
+		if report, resp = i.checkCompliance(); resp != route.OK {
+			return resp
+		}
+		aaa.Accounting("Instance compliance fixed: %s, %s", i.Name(), i.Id())
+	}
+	report.Print()
+	if err := report.Audit(); err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	return route.OK
+}
+
+// checkCompliance copies the check script to the instance, runs it and
+// returns the report.
+func (i *Instance) checkCompliance() (*compliance.Report, route.Response) {
+	checks := compliance.Checks()
+	script := filepath.Join(env.Lookup("ARC"), "compliance_"+i.Name())
+	if err := ioutil.WriteFile(script, []byte(compliance.Script(checks)), 0755); err != nil {
+		msg.Error(err.Error())
+		return nil, route.FAIL
+	}
+	output, err := command.RunRemoteWithOutput(command.Command{
+		Instance: i,
+		Desc:     "check compliance",
+		Src:      script,
+		Dest:     "/tmp/arc_compliance",
+	})
+	if err != nil {
+		msg.Error("Instance %s compliance checks failed to run: %s\n%s", i.Name(), err.Error(), output)
+		return nil, route.FAIL
+	}
+	return compliance.Parse(i.Name(), checks, output), route.OK
+}
+
+// fixCompliance re-applies the hardening for each category with failed checks.
+func (i *Instance) fixCompliance(req *route.Request, report *compliance.Report) route.Response {
+	if resp := i.setupArc(req, "fix arc permissions", false); resp != route.OK {
+		return resp
+	}
+	for _, category := range report.FailedCategories() {
+		switch category {
+		case compliance.Ssh:
+			if !command.Run([]command.Command{
+				{
+					Type: command.Remote,
+					Desc: "apply ssh hardening",
+					Src:  "/usr/lib/arc/hardening/52_ssh_hardening",
+				},
+				{
+					Type: command.Sudo,
+					Desc: "reload sshd",
+					Src:  "/bin/sh -c 'service sshd reload || service ssh reload'",
+				},
+			}, i) {
+				return route.FAIL
+			}
+		case compliance.Cron:
+			if !command.RunRemote(command.Command{
+				Instance: i,
+				Desc:     "apply cron hardening",
+				Src:      "/usr/lib/arc/hardening/51_cron_hardening",
+			}) {
+				return route.FAIL
+			}
+		case compliance.Aide:
+			if resp := i.provisionAide(req); resp != route.OK {
+				return resp
+			}
+		default:
+			msg.Warn("Instance %s %s compliance failures require manual remediation", i.Name(), category)
+		}
+	}
+	return route.OK
+}
@@ -27,74 +27,147 @@
 package arc
 
 import (
-	"github.com/cisco/arc/pkg/command"
-	"github.com/cisco/arc/pkg/log"
+	"fmt"
+	"time"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/monitor"
 	"github.com/cisco/arc/pkg/msg"
 	"github.com/cisco/arc/pkg/route"
 )
 
+// maintenanceTag records the end of an instance's maintenance window.
+// An empty value means the instance isn't in maintenance.
+const maintenanceTag = "Maintenance Until"
+
+// StartPaging enables paging for the instance. Paging stays disabled while
+// the instance is in a maintenance window, unless the request is a provision
+// or "maintenance end" which end the maintenance window.
 func (i *Instance) StartPaging(req *route.Request) route.Response {
-	if req.Flag("bootstrap") || req.Flag("force") {
+	if req.Flag("bootstrap") || req.Flag("force") || req.Flag("nopaging") {
 		return route.OK
 	}
-	if resp := i.startPagingConsul(req); resp != route.OK {
-		return resp
+	if until := i.maintenanceUntil(); !until.IsZero() {
+		ends := req.Command() == route.Provision || req.Command() == route.Maintenance
+		if !ends && time.Now().Before(until) {
+			msg.Detail("Instance in maintenance until %s, paging remains disabled", until.Format(time.RFC3339))
+			return route.OK
+		}
+		if err := i.SetTags(map[string]string{maintenanceTag: ""}); err != nil {
+			msg.Warn("Unable to clear maintenance window: %s", err.Error())
+		}
 	}
-	if resp := i.startPagingSensu(req); resp != route.OK {
-		return resp
-	}
-	return route.OK
+	return i.paging(func(m monitor.Monitor) error {
+		return m.Enable(i)
+	}, "enable")
 }
 
+// StopPaging disables paging for the instance until paging is started again.
 func (i *Instance) StopPaging(req *route.Request) route.Response {
-	if req.Flag("bootstrap") || req.Flag("force") {
+	if req.Flag("bootstrap") || req.Flag("force") || req.Flag("nopaging") {
 		return route.OK
 	}
-	if resp := i.stopPagingConsul(req); resp != route.OK {
-		return resp
-	}
-	if resp := i.stopPagingSensu(req); resp != route.OK {
-		return resp
+	return i.paging(func(m monitor.Monitor) error {
+		return m.Disable(i, time.Time{})
+	}, "disable")
+}
+
+// monitors returns the monitoring integrations configured for the pod, or
+// for the datacenter if the pod doesn't configure any.
+func (i *Instance) monitors() ([]monitor.Monitor, error) {
+	cfg := i.Pod().Monitoring()
+	if len(cfg) == 0 {
+		cfg = i.Pod().Cluster().Compute().DataCenter().Monitoring()
 	}
-	return route.OK
+	return monitor.New(cfg)
 }
 
-func (i *Instance) paging(req *route.Request, cmd, service string) route.Response {
-	output, err := command.RunRemoteWithOutput(command.Command{
-		Instance: i,
-		Desc:     cmd + " " + service + " paging",
-		Src:      "/usr/lib/arc/paging/" + service + "_paging",
-		Args:     []string{cmd, i.Name()},
-	})
+// paging applies f to each of the instance's monitors. A failure of a required
+// monitor fails the request, other failures are reported as warnings.
+func (i *Instance) paging(f func(monitor.Monitor) error, action string) route.Response {
+	monitors, err := i.monitors()
 	if err != nil {
-		msg.Detail("Unable to %s %s paging", cmd, service)
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	resp := route.OK
+	for _, m := range monitors {
+		err := f(m)
+		if err == nil {
+			continue
+		}
+		if m.Required() {
+			msg.Error("Unable to %s %s paging: %s", action, m.Name(), err.Error())
+			resp = route.FAIL
+			continue
+		}
+		msg.Detail("Unable to %s %s paging", action, m.Name())
 		msg.Warn("%s. See log for more details.", err.Error())
-		log.Warn("%s", output)
 	}
-	return route.OK
+	return resp
 }
 
-func (i *Instance) startPagingConsul(req *route.Request) route.Response {
-	return i.paging(req, "enable", "consul")
+// maintenanceUntil returns the end of the instance's maintenance window, or
+// the zero time if the instance isn't in maintenance.
+func (i *Instance) maintenanceUntil() time.Time {
+	v := i.Tags()[maintenanceTag]
+	if v == "" {
+		return time.Time{}
+	}
+	until, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}
+	}
+	return until
 }
 
-func (i *Instance) stopPagingConsul(req *route.Request) route.Response {
-	return i.paging(req, "disable", "consul")
-}
+// maintenance handles "maintenance <duration>", which disables paging for the
+// duration, and "maintenance end", which ends the maintenance window early.
+func (i *Instance) maintenance(req *route.Request) route.Response {
+	msg.Info("Instance Maintenance: %s", i.Name())
+	if !i.Created() {
+		msg.Detail("Instance does not exist. Skipping...")
+		return route.OK
+	}
+	if req.Flag("end") {
+		if resp := i.StartPaging(req); resp != route.OK {
+			return resp
+		}
+		aaa.Accounting("Instance maintenance ended: %s, %s", i.Name(), i.Id())
+		return route.OK
+	}
 
-func (i *Instance) startPagingSensu(req *route.Request) route.Response {
-	_, err := command.CopyToWithOutput(command.Command{
-		Instance: i,
-		Desc:     "Copy check_monit_services",
-		Src:      "/usr/lib/arc/tools/check_monit_services",
-	})
+	d, err := maintenanceDuration(req)
 	if err != nil {
-		msg.Detail("Unable to enable sensu paging")
-		msg.Warn("%s. See log for more details.", err.Error())
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	until := time.Now().Add(d).UTC().Truncate(time.Second)
+	if resp := i.paging(func(m monitor.Monitor) error {
+		return m.Disable(i, until)
+	}, "disable"); resp != route.OK {
+		return resp
 	}
-	return i.paging(req, "enable", "sensu")
+	if err := i.SetTags(map[string]string{maintenanceTag: until.Format(time.RFC3339)}); err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	msg.Detail("Paging disabled until %s", until.Format(time.RFC3339))
+	aaa.Accounting("Instance maintenance: %s, %s until %s", i.Name(), i.Id(), until.Format(time.RFC3339))
+	return route.OK
 }
 
-func (i *Instance) stopPagingSensu(req *route.Request) route.Response {
-	return i.paging(req, "disable", "sensu")
+// maintenanceDuration returns the duration given as the flag of a maintenance request.
+func maintenanceDuration(req *route.Request) (time.Duration, error) {
+	for _, f := range req.Flags().Get() {
+		d, err := time.ParseDuration(f)
+		if err != nil {
+			continue
+		}
+		if d <= 0 {
+			return 0, fmt.Errorf("Maintenance duration must be positive, got %q", f)
+		}
+		return d, nil
+	}
+	return 0, fmt.Errorf("Maintenance requires a duration such as 2h or 30m, or end")
 }
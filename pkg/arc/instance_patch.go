@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2017, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package arc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/command"
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/route"
+)
+
+// rebootRequiredStatus is the exit status of patch_software when a reboot is
+// needed to complete the patch.
+const rebootRequiredStatus = 100
+
+func (i *Instance) patch(req *route.Request) route.Response {
+	msg.Info("Instance Patch: %s", i.Name())
+	if i.Destroyed() {
+		msg.Detail("Instance does not exist, skipping...")
+		return route.OK
+	}
+	if !i.Started() {
+		msg.Detail("Instance is not running, skipping...")
+		return route.OK
+	}
+	if resp := i.Derived().PrePatch(req); resp != route.OK {
+		return resp
+	}
+	if resp := i.Derived().Patch(req); resp != route.OK {
+		return resp
+	}
+	if resp := i.Derived().PostPatch(req); resp != route.OK {
+		return resp
+	}
+	msg.Detail("Patched: %s", i.Id())
+	aaa.Accounting("Instance patched: %s, %s", i.Name(), i.Id())
+	return route.OK
+}
+
+// PrePatch stops paging and applies the operating system updates, recording
+// whether a reboot is needed.
+func (i *Instance) PrePatch(req *route.Request) route.Response {
+	msg.Info("Instance Patch Update: %s", i.Name())
+	if resp := i.setupArc(req, "fix arc permissions", false); resp != route.OK {
+		return resp
+	}
+	if resp := i.StopPaging(req); resp != route.OK {
+		return resp
+	}
+	// patch_software applies the updates with update_software.
+	if output, err := command.CopyToWithOutput(command.Command{
+		Instance: i,
+		Desc:     "update software",
+		Src:      "/usr/lib/arc/provision/update_software",
+	}); err != nil {
+		msg.Error("Instance %s failed to copy update_software: %s\n%s", i.Name(), err.Error(), output)
+		return route.FAIL
+	}
+	output, err := command.RunRemoteWithOutput(command.Command{
+		Instance: i,
+		Desc:     "patch software",
+		Src:      "/usr/lib/arc/patch/patch_software",
+	})
+	i.rebootRequired = false
+	if err != nil {
+		if status, ok := command.ExitStatus(err); !ok || status != rebootRequiredStatus {
+			msg.Error("Instance %s failed to patch: %s\n%s", i.Name(), err.Error(), output)
+			return route.FAIL
+		}
+		i.rebootRequired = true
+	}
+	return route.OK
+}
+
+// Patch restarts the instance if the update requires it. Paging stays
+// disabled across the restart and is started by PostPatch.
+func (i *Instance) Patch(req *route.Request) route.Response {
+	if !i.rebootRequired {
+		msg.Detail("Instance %s does not require a reboot", i.Name())
+		return route.OK
+	}
+	r := req.Clone(route.Restart)
+	r.Flags().Append("nopaging")
+	if resp := i.restart(r); resp != route.OK {
+		return resp
+	}
+	i.rebootRequired = false
+	return route.OK
+}
+
+// PostPatch waits for ssh and the monit health checks before starting paging.
+func (i *Instance) PostPatch(req *route.Request) route.Response {
+	msg.Info("Instance Patch Check: %s", i.Name())
+	if !msg.Wait(
+		fmt.Sprintf("Waiting for Instance %s ssh", i.Name()),    // title
+		fmt.Sprintf("Instance %s ssh is unavailable", i.Name()), // err
+		60, // duration
+		func() bool {
+			msg.Quiet(true)
+			defer msg.Quiet(false)
+			_, err := command.RunWithOutput([]command.Command{{Type: command.Sudo, Desc: "check ssh", Src: "/bin/true"}}, i)
+			return err == nil
+		},
+		func() bool { return true },
+	) {
+		msg.Error("Instance %s ssh is unavailable", i.Name())
+		return route.FAIL
+	}
+	if output, err := command.RunRemoteWithOutput(command.Command{
+		Instance: i,
+		Desc:     "check monit services",
+		Src:      "/usr/lib/arc/tools/check_monit_services",
+	}); err != nil {
+		msg.Error("Instance %s failed health checks: %s\n%s", i.Name(), err.Error(), output)
+		return route.FAIL
+	}
+	return i.StartPaging(req)
+}
+
+// patchBatch returns the number of instances per pod to patch at once. A
+// number given as a request flag overrides the configured batch size.
+func patchBatch(req *route.Request, configured int) int {
+	for _, f := range req.Flags().Get() {
+		if n, err := strconv.Atoi(f); err == nil && n > 0 {
+			return n
+		}
+	}
+	return configured
+}
@@ -27,18 +27,16 @@
 package arc
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/command"
-	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/hiera"
 	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/mirror"
 	"github.com/cisco/arc/pkg/msg"
 	"github.com/cisco/arc/pkg/route"
 	"github.com/cisco/arc/pkg/secrets"
@@ -55,6 +53,12 @@ func (i *Instance) provision(req *route.Request) route.Response {
 		return resp
 	}
 
+	// See instance_version.go
+	if i.skipUnchanged(req) {
+		msg.Detail("Instance is at the configured versions, skipping...")
+		return route.OK
+	}
+
 	if resp := i.Derived().PreProvision(req); resp != route.OK {
 		return resp
 	}
@@ -64,6 +68,13 @@ func (i *Instance) provision(req *route.Request) route.Response {
 	if resp := i.Derived().PostProvision(req); resp != route.OK {
 		return resp
 	}
+	// A bootstrap provision doesn't apply the full configuration, so the
+	// versions are only recorded by a complete provision.
+	if !req.Flag("bootstrap") {
+		if err := i.setVersionTags(); err != nil {
+			msg.Warn("Failed to record provisioned versions for %s\n\t%s", i.Name(), err.Error())
+		}
+	}
 	msg.Detail("Provisioned: %s", i.Id())
 	aaa.Accounting("Instance provisioned: %s, %s", i.Name(), i.Id())
 	return route.OK
@@ -231,17 +242,17 @@ func (i *Instance) provisionInstallPuppet(req *route.Request) route.Response {
 }
 
 func (i *Instance) provisionInstallServertype(req *route.Request) route.Response {
+	msg.Info("Pull servertype from mirror")
+	pkg, err := mirror.ServerType(i.ServerType(), i.Pod().PkgName())
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
 	commands := []command.Command{
-		{
-			Type: command.Local,
-			Desc: "pull servertype from mirror",
-			Src:  "/usr/lib/arc/provision/pull_pkg",
-			Args: []string{i.Pod().PkgName(), env.Lookup("ARC"), i.ServerType()},
-		},
 		{
 			Type: command.Copy,
 			Desc: "push servertype",
-			Src:  env.Lookup("ARC") + "/" + i.Pod().PkgName(),
+			Src:  pkg,
 			Dest: "/usr/lib/arc/" + i.Pod().PkgName(),
 		},
 		{
@@ -261,28 +272,19 @@ func (i *Instance) provisionInstallPackages(req *route.Request) route.Response {
 	msg.Quiet(true)
 	defer msg.Quiet(false)
 
-	// The pull_packages script copies the packages.txt file and the
-	// packages from the master mirror to the ARC directory.
-	// If the packages.txt file doesn't exist the script will still succeed.
-	if !command.RunLocal(command.Command{
-		Desc: "pull packages from mirror",
-		Src:  "/usr/lib/arc/provision/pull_packages",
-		Args: []string{i.ServerType(), i.Version(), env.Lookup("ARC")},
-	}) {
+	// Pull the packages-<version>.txt file and the packages it lists from
+	// the mirror. If the packages file doesn't exist there is nothing to install.
+	packagesFile, pkgs, err := mirror.Packages(i.ServerType(), i.Version())
+	if err != nil {
+		msg.Quiet(false)
+		msg.Error(err.Error())
 		return route.FAIL
 	}
-
-	// Read the contents of the packages.txt file. Each line of the file contains a name of a single package.
-	packagesFile := env.Lookup("ARC") + fmt.Sprintf("/packages-%s.txt", i.Version())
 	log.Debug("Packages file: %s", packagesFile)
-
-	f, err := os.Open(packagesFile)
-	if err != nil {
-		log.Debug(err.Error())
+	if packagesFile == "" {
 		msg.Detail("No packages found. Skipping...")
 		return route.OK
 	}
-	defer f.Close()
 
 	commands := []command.Command{
 		{
@@ -294,29 +296,21 @@ func (i *Instance) provisionInstallPackages(req *route.Request) route.Response {
 			Type: command.Copy,
 			Desc: "copy manifest file to the instance",
 			Src:  packagesFile,
-			Dest: "/usr/lib/arc/" + fmt.Sprintf("packages-%s.txt", i.Version()),
+			Dest: "/usr/lib/arc/" + filepath.Base(packagesFile),
 		},
 	}
-	s := bufio.NewScanner(f)
-	s.Split(bufio.ScanLines)
-	for s.Scan() {
-		pkg := filepath.Base(s.Text())
+	for _, pkg := range pkgs {
+		name := filepath.Base(pkg)
 		commands = append(commands,
 			command.Command{
 				Type: command.Copy,
-				Desc: fmt.Sprintf("upload %q", pkg),
-				Src:  env.Lookup("ARC") + "/" + pkg,
-				Dest: "/usr/lib/arc/" + pkg,
-			},
-			command.Command{
-				Type: command.Local,
-				Desc: fmt.Sprintf("cleanup %q", pkg),
-				Src:  "rm",
-				Args: []string{"-f", env.Lookup("ARC") + "/" + pkg},
+				Desc: fmt.Sprintf("upload %q", name),
+				Src:  pkg,
+				Dest: "/usr/lib/arc/" + name,
 			},
 			command.Command{
 				Type: command.Message,
-				Desc: "Installing: " + pkg,
+				Desc: "Installing: " + name,
 			},
 		)
 	}
@@ -324,19 +318,13 @@ func (i *Instance) provisionInstallPackages(req *route.Request) route.Response {
 		Type: command.Remote,
 		Desc: "install all the packages",
 		Src:  "/usr/lib/arc/tools/install_packages",
-		Args: []string{fmt.Sprintf("/usr/lib/arc/packages-%s.txt", i.Version())},
+		Args: []string{"/usr/lib/arc/" + filepath.Base(packagesFile)},
 	},
 		command.Command{
 			Type: command.Message,
 			Dest: "Detail",
 			Desc: "Packages Installed",
 		},
-		command.Command{
-			Type: command.Local,
-			Desc: "cleanup packages from mirror",
-			Src:  "rm",
-			Args: []string{"-f", packagesFile},
-		},
 	)
 	if !command.RunQuiet(commands, i) {
 		return route.FAIL
@@ -366,17 +354,17 @@ func (i *Instance) provisionAide(req *route.Request) route.Response {
 	}
 	log.Debug("Aide Package Name: %q", pkgName)
 
+	msg.Info("Pull aide puppet module from mirror")
+	pkg, err := mirror.Aide(pkgName)
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
 	commands := []command.Command{
-		{
-			Type: command.Local,
-			Desc: "pull aide puppet module from mirror",
-			Src:  "/usr/lib/arc/provision/pull_pkg",
-			Args: []string{pkgName, env.Lookup("ARC")},
-		},
 		{
 			Type: command.Copy,
 			Desc: "push aide puppet module",
-			Src:  env.Lookup("ARC") + "/" + pkgName,
+			Src:  pkg,
 			Dest: "/usr/lib/arc/" + pkgName,
 		},
 		{
@@ -28,7 +28,9 @@ package arc
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/cisco/arc/pkg/command"
 	"github.com/cisco/arc/pkg/env"
@@ -45,8 +47,15 @@ func (i *Instance) configureUsers(req *route.Request, asRoot bool) route.Respons
 	commands := []command.Command{}
 
 	commands = i.copyUserScripts(commands)
+	if i.sshCa() {
+		commands, err = i.setupSshCa(req, commands)
+		if err != nil {
+			msg.Error(err.Error())
+			return route.FAIL
+		}
+	}
 	commands = i.setupGroups(commands)
-	commands, err = i.setupUsers(commands)
+	commands, err = i.setupUsers(req, commands)
 
 	if err != nil {
 		msg.Error(err.Error())
@@ -72,7 +81,7 @@ func (i *Instance) copyUserScripts(commands []command.Command) []command.Command
 		},
 	)
 	dir := "/usr/lib/arc/users/"
-	scripts := []string{"setup_group", "setup_user", "add_user_to_groups", "setup_ssh", "setup_sudo"}
+	scripts := []string{"setup_group", "setup_user", "add_user_to_groups", "setup_ssh", "setup_sudo", "setup_ssh_ca", "setup_principals"}
 	for _, script := range scripts {
 		commands = append(commands,
 			command.Command{
@@ -118,7 +127,68 @@ func (i *Instance) setupGroups(commands []command.Command) []command.Command {
 	return commands
 }
 
-func (i *Instance) setupUsers(commands []command.Command) ([]command.Command, error) {
+// sshCa returns true if the instance's users log in with certificates signed
+// by the datacenter's ssh certificate authority rather than with their keys.
+func (i *Instance) sshCa() bool {
+	return i.Pod().Cluster().Compute().DataCenter().SshCa()
+}
+
+// setupSshCa installs the datacenter's certificate authority public key as
+// the key sshd trusts for user certificates.
+func (i *Instance) setupSshCa(req *route.Request, commands []command.Command) ([]command.Command, error) {
+	ca, err := loadCA(req.DataCenter())
+	if err != nil {
+		return nil, err
+	}
+	caFile := env.Lookup("ARC") + "/ssh_ca.pub"
+	if err := ioutil.WriteFile(caFile, []byte(ca.PublicKey()+"\n"), 0644); err != nil {
+		return nil, err
+	}
+	commands = append(commands,
+		command.Command{
+			Type: command.Message,
+			Dest: "Info",
+			Desc: "Configuring the ssh certificate authority",
+		},
+		command.Command{
+			Type: command.Copy,
+			Desc: "ssh_ca.pub",
+			Src:  caFile,
+			Dest: "/tmp/arc_user_ca.pub",
+		},
+		command.Command{
+			Type: command.Sudo,
+			Desc: "setup ssh certificate authority",
+			Src:  "/usr/lib/arc/users/setup_ssh_ca",
+		},
+	)
+	return commands, nil
+}
+
+// principals returns the certificate principals the instance accepts for the
+// named user: the instance's teams the user belongs to, and the user's
+// grant to the instance's pod if it is active.
+func (i *Instance) principals(req *route.Request, name string) ([]string, error) {
+	if !i.sshCa() {
+		return nil, nil
+	}
+	principals := []string{}
+	for _, t := range i.Teams() {
+		if team := users.Teams[t]; team != nil && team.FindUser(name) != nil {
+			principals = append(principals, users.TeamPrincipal(t))
+		}
+	}
+	grants, err := loadGrants(req.DataCenter())
+	if err != nil {
+		return nil, err
+	}
+	if g := grants.Active(name, i.Pod().Name(), time.Now()); g != nil {
+		principals = append(principals, users.GrantPrincipal(g.Pod))
+	}
+	return principals, nil
+}
+
+func (i *Instance) setupUsers(req *route.Request, commands []command.Command) ([]command.Command, error) {
 	for _, name := range i.Teams() {
 		team := users.Teams[name]
 		if team == nil {
@@ -133,10 +203,13 @@ func (i *Instance) setupUsers(commands []command.Command) ([]command.Command, er
 		)
 		for _, user := range team.Users {
 			if user.Remove {
-				commands = i.removeUser(user, commands)
+				commands = i.removeUser(user.Name, commands)
 			} else {
-				var err error
-				commands, err = i.addUser(user, commands, team.Sudo)
+				principals, err := i.principals(req, user.Name)
+				if err != nil {
+					return nil, err
+				}
+				commands, err = i.addUser(user, commands, team.Sudo, principals)
 				if err != nil {
 					return nil, err
 				}
@@ -146,7 +219,7 @@ func (i *Instance) setupUsers(commands []command.Command) ([]command.Command, er
 	return commands, nil
 }
 
-func (i *Instance) addUser(user *users.User, commands []command.Command, sudo bool) ([]command.Command, error) {
+func (i *Instance) addUser(user *users.User, commands []command.Command, sudo bool, principals []string) ([]command.Command, error) {
 	groupArgs := []string{user.Name}
 	groupArgs = append(groupArgs, user.Groups...)
 
@@ -156,7 +229,14 @@ func (i *Instance) addUser(user *users.User, commands []command.Command, sudo bo
 		return nil, err
 	}
 
-	for _, key := range user.SshKeys {
+	// With a certificate authority the users log in with certificates, so the
+	// authorized keys are emptied rather than listing the users' keys. Expired
+	// keys are left out.
+	keys := user.SshKeys.Active(time.Now())
+	if i.sshCa() {
+		keys = nil
+	}
+	for _, key := range keys {
 		if _, err := file.WriteString(key + "\n"); err != nil {
 			file.Close()
 			return nil, err
@@ -192,6 +272,9 @@ func (i *Instance) addUser(user *users.User, commands []command.Command, sudo bo
 			Args: []string{user.Name},
 		},
 	)
+	if i.sshCa() {
+		commands = i.setupPrincipals(user.Name, principals, commands)
+	}
 	if sudo {
 		c := command.Command{
 			Type: command.Sudo,
@@ -210,24 +293,147 @@ func (i *Instance) addUser(user *users.User, commands []command.Command, sudo bo
 	return commands, nil
 }
 
-func (i *Instance) removeUser(user *users.User, commands []command.Command) []command.Command {
+func (i *Instance) removeUser(name string, commands []command.Command) []command.Command {
 	commands = append(commands,
 		command.Command{
 			Type: command.Sudo,
-			Desc: "remove user \"" + user.Name + "\"",
+			Desc: "remove user \"" + name + "\"",
 			Src:  "/usr/lib/arc/users/setup_user",
-			Args: []string{user.Name, "remove"},
+			Args: []string{name, "remove"},
+		},
+	)
+	commands = i.removeSudo(name, commands)
+	if i.sshCa() {
+		commands = append(commands,
+			command.Command{
+				Type: command.Sudo,
+				Desc: "remove user \"" + name + "\" principals",
+				Src:  "/usr/lib/arc/users/setup_principals",
+				Args: []string{name, "remove"},
+			},
+		)
+	}
+	commands = append(commands,
+		command.Command{
+			Type: command.Message,
+			Desc: "Removed user: " + name,
 		},
+	)
+	return commands
+}
+
+func (i *Instance) setupPrincipals(name string, principals []string, commands []command.Command) []command.Command {
+	return append(commands,
 		command.Command{
 			Type: command.Sudo,
-			Desc: "remove sudo access for  user \"" + user.Name + "\"",
-			Src:  "/usr/lib/arc/users/setup_sudo",
-			Args: []string{user.Name, "remove"},
+			Desc: "setup user \"" + name + "\" principals",
+			Src:  "/usr/lib/arc/users/setup_principals",
+			Args: append([]string{name}, principals...),
 		},
+	)
+}
+
+func (i *Instance) removeSudo(name string, commands []command.Command) []command.Command {
+	return append(commands,
 		command.Command{
-			Type: command.Message,
-			Desc: "Removed user: " + user.Name,
+			Type: command.Sudo,
+			Desc: "remove sudo access for  user \"" + name + "\"",
+			Src:  "/usr/lib/arc/users/setup_sudo",
+			Args: []string{name, "remove"},
 		},
 	)
-	return commands
+}
+
+// teamAccess returns whether the named user is a member of the instance's
+// teams, and whether one of those teams has sudo.
+func (i *Instance) teamAccess(name string) (member, sudo bool) {
+	for _, t := range i.Teams() {
+		team := users.Teams[t]
+		if team == nil {
+			continue
+		}
+		if u := team.FindUser(name); u != nil && !u.Remove {
+			member = true
+			sudo = sudo || team.Sudo
+		}
+	}
+	return member, sudo
+}
+
+// grant adds the user of a pod grant request to the instance, with sudo if
+// the grant is for sudo. See pod.grant.
+func (i *Instance) grant(req *route.Request) route.Response {
+	msg.Info("Instance Grant: %s", i.Name())
+	if i.Destroyed() {
+		msg.Detail("Instance does not exist, skipping...")
+		return route.OK
+	}
+	if !i.Started() {
+		msg.Detail("Instance is not running, skipping...")
+		return route.OK
+	}
+	user, sudo, _, err := grantFlags(req)
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	commands := i.copyUserScripts([]command.Command{})
+	commands = i.setupGroups(commands)
+	principals, err := i.principals(req, user.Name)
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	commands, err = i.addUser(user, commands, sudo, principals)
+	if err != nil {
+		msg.Error(err.Error())
+		return route.FAIL
+	}
+	if !command.RunQuiet(commands, i) {
+		return route.FAIL
+	}
+	return route.OK
+}
+
+// revoke undoes an expired grant to the user named in the request. Users that
+// are members of the instance's teams are left with the access of their teams,
+// other users are removed. See arc.revokeExpired.
+func (i *Instance) revoke(req *route.Request) route.Response {
+	msg.Info("Instance Revoke: %s", i.Name())
+	if i.Destroyed() {
+		msg.Detail("Instance does not exist, skipping...")
+		return route.OK
+	}
+	if !i.Started() {
+		msg.Detail("Instance is not running, skipping...")
+		return route.OK
+	}
+	flags := req.Flags().Get()
+	if len(flags) != 1 {
+		msg.Error("Revoke requires a user")
+		return route.FAIL
+	}
+	name := flags[0]
+
+	commands := i.copyUserScripts([]command.Command{})
+	member, sudo := i.teamAccess(name)
+	if !member {
+		commands = i.removeUser(name, commands)
+	} else {
+		if !sudo {
+			commands = i.removeSudo(name, commands)
+		}
+		if i.sshCa() {
+			principals, err := i.principals(req, name)
+			if err != nil {
+				msg.Error(err.Error())
+				return route.FAIL
+			}
+			commands = i.setupPrincipals(name, principals, commands)
+		}
+	}
+	if !command.RunQuiet(commands, i) {
+		return route.FAIL
+	}
+	return route.OK
 }
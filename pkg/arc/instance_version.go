@@ -0,0 +1,93 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package arc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/cisco/arc/pkg/msg"
+	"github.com/cisco/arc/pkg/route"
+)
+
+// The tags used to record the versions applied by the last successful provision.
+const (
+	servertypeTag        = "ServerType"
+	servertypeVersionTag = "ServerType Version"
+	bootstrapVersionTag  = "Bootstrap Version"
+	deployVersionTag     = "Deploy Version"
+	secretsVersionTag    = "Secrets Version"
+	aideVersionTag       = "Aide Version"
+)
+
+// desiredVersions returns the versions the instance is configured to be at,
+// keyed by the tag used to record them.
+func (i *Instance) desiredVersions() map[string]string {
+	c := i.Pod().Cluster().Compute()
+	return map[string]string{
+		servertypeTag:        i.ServerType(),
+		servertypeVersionTag: i.Version(),
+		bootstrapVersionTag:  strconv.Itoa(c.BootstrapVersion()),
+		deployVersionTag:     strconv.Itoa(c.DeployVersion()),
+		secretsVersionTag:    strconv.Itoa(c.SecretsVersion()),
+		aideVersionTag:       strconv.Itoa(c.AideVersion()),
+	}
+}
+
+// setVersionTags records the configured versions on the instance. It is called
+// after a successful provision.
+func (i *Instance) setVersionTags() error {
+	msg.Detail("Recording provisioned versions")
+	return i.SetTags(i.desiredVersions())
+}
+
+// VersionDrift satisfies the resource.Instance interface.
+func (i *Instance) VersionDrift() []string {
+	var drift []string
+	tags := i.Tags()
+	for k, v := range i.desiredVersions() {
+		recorded, ok := tags[k]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("%s: not recorded, configured %s", k, v))
+		case recorded != v:
+			drift = append(drift, fmt.Sprintf("%s: recorded %s, configured %s", k, recorded, v))
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// skipUnchanged returns true if the request is a "changed" provision and the
+// instance is already at the desired versions.
+func (i *Instance) skipUnchanged(req *route.Request) bool {
+	if !req.Flag("changed") {
+		return false
+	}
+	return len(i.Derived().VersionDrift()) == 0
+}
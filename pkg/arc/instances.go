@@ -122,7 +122,7 @@ func (i *instances) FindByIP(ip string) resource.Instance {
 
 // Route satisfies the embedded resource.Resource interface in resource.Pods.
 func (i *instances) Route(req *route.Request) route.Response {
-	log.Route(req, "Instances")
+	defer log.Route(req, "Instances").End()
 
 	switch req.Top() {
 	case "instance":
@@ -140,7 +140,7 @@ func (i *instances) Route(req *route.Request) route.Response {
 	}
 
 	switch req.Command() {
-	case route.Load, route.Create, route.Provision, route.Start, route.Stop, route.Restart, route.Replace:
+	case route.Load, route.Create, route.Provision, route.Start, route.Stop, route.Restart, route.Replace, route.Maintenance, route.Patch, route.Compliance, route.Grant, route.RevokeExpired:
 		return i.RouteInOrder(req)
 	case route.Destroy:
 		return i.RouteReverseOrder(req)
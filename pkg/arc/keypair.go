@@ -119,7 +119,7 @@ func (k *keypair) FingerPrint() string {
 }
 
 func (k *keypair) Route(req *route.Request) route.Response {
-	log.Route(req, "Keypair %q", k.Name())
+	defer log.Route(req, "Keypair %q", k.Name()).End()
 
 	if req.Top() != "" {
 		k.help()
@@ -0,0 +1,70 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package arc
+
+import (
+	"github.com/cisco/arc/pkg/metrics"
+)
+
+// metrics sets the gauges of the datacenter's instances: the number of instances
+// by state, and the number of deployed instances of each pod versus its configured
+// count.
+func (a *arc) metrics() {
+	if !metrics.Enabled() || a.DataCenter() == nil || a.DataCenter().Compute() == nil {
+		return
+	}
+	compute := a.Arc.DataCenter.Compute
+	if compute == nil || compute.Clusters == nil {
+		return
+	}
+	for _, cluster := range *compute.Clusters {
+		if cluster.Pods == nil {
+			continue
+		}
+		for _, conf := range *cluster.Pods {
+			labels := []metrics.Label{{Name: "cluster", Value: cluster.Name()}, {Name: "pod", Value: conf.Name()}}
+			metrics.Set("pod_instances_configured", "Number of instances configured for the pod.", float64(conf.Count()), labels...)
+
+			deployed := 0
+			p := a.DataCenter().Compute().FindPod(conf.Name())
+			if p != nil && p.Instances() != nil {
+				for _, i := range p.Instances().GetInstances() {
+					state := i.State()
+					if state == "" {
+						state = "not created"
+					}
+					if state != "not created" && state != "terminated" {
+						deployed++
+					}
+					metrics.Add("resources", "Number of resources by type and state.", 1,
+						metrics.Label{Name: "type", Value: "instance"}, metrics.Label{Name: "state", Value: state})
+				}
+			}
+			metrics.Set("pod_instances", "Number of deployed instances of the pod.", float64(deployed), labels...)
+		}
+	}
+}
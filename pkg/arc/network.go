@@ -28,6 +28,7 @@ package arc
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/cisco/arc/pkg/aaa"
 	"github.com/cisco/arc/pkg/config"
@@ -165,14 +166,26 @@ func (n *network) CidrGroup(s string) []string {
 // in order to manage the entire network. All other commands are routed to
 // network's children.
 func (n *network) Route(req *route.Request) route.Response {
-	log.Route(req, "Network")
+	defer log.Route(req, "Network").End()
 
 	// Is the user associated with the request allowed to do network commands?
-	if err := aaa.Authorized(req, "Network", n.Name()); err != nil {
+	if err := aaa.Authorized(req, "network", n.Name()); err != nil {
 		msg.Error(err.Error())
 		return route.UNAUTHORIZED
 	}
 
+	// The network's subnet and security groups are approved and confirmed
+	// here as well, since they are destroyed through the network.
+	if !req.TestFlag() {
+		if err := aaa.Approved(req, "network", n.Name()); err != nil {
+			msg.Error(err.Error())
+			return route.UNAUTHORIZED
+		}
+		if !confirmResources(req, "network "+n.Name(), n.affected(req.Path().Get())) {
+			return route.FAIL
+		}
+	}
+
 	switch req.Top() {
 	case "subnet":
 		return n.SubnetGroups().Route(req.Pop())
@@ -215,6 +228,59 @@ func (n *network) Route(req *route.Request) route.Response {
 	return route.FAIL
 }
 
+// affected lists the network, subnets and security groups addressed by the
+// request path, which is empty for the whole network.
+func (n *network) affected(path []string) []string {
+	top, name := "", ""
+	if len(path) > 0 {
+		top = path[0]
+	}
+	if len(path) > 1 {
+		name = path[1]
+	}
+	l := []string{}
+	if top == "" && n.Id() != "" {
+		l = append(l, fmt.Sprintf("network %s %s", n.Name(), n.Id()))
+	}
+	if top == "" || top == "subnet" {
+		names := []string{}
+		for k := range n.subnetGroups.Get() {
+			if name == "" || k == name {
+				names = append(names, k)
+			}
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			subnets := n.subnetGroups.Find(k).Subnets()
+			ids := []string{}
+			for s := range subnets {
+				ids = append(ids, s)
+			}
+			sort.Strings(ids)
+			for _, s := range ids {
+				if id := subnets[s].Id(); id != "" {
+					l = append(l, fmt.Sprintf("subnet %s %s", s, id))
+				}
+			}
+		}
+	}
+	if top == "" || top == "secgroup" {
+		names := []string{}
+		for k := range n.securityGroups.securityGroups {
+			if name == "" || k == name {
+				names = append(names, k)
+			}
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			if g := n.securityGroups.Find(k); g.Id() != "" {
+				l = append(l, fmt.Sprintf("security group %s %s", k, g.Id()))
+			}
+		}
+	}
+	return l
+}
+
 func (n *network) help() {
 	providerCommands := help.Append(n.providerNetwork.HelpCommands(), n.providerNetworkPost.HelpCommands())
 	commands := []help.Command{
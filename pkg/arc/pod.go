@@ -208,6 +208,22 @@ func (p *Pod) PkgName() string {
 	return ""
 }
 
+// changedInstances returns the created instances whose recorded versions differ
+// from the configured versions.
+func (p *Pod) changedInstances() []resource.Instance {
+	changed := []resource.Instance{}
+	for _, j := range p.instances.Get() {
+		i := j.(resource.Instance)
+		if !i.Created() {
+			continue
+		}
+		if len(i.VersionDrift()) > 0 {
+			changed = append(changed, i)
+		}
+	}
+	return changed
+}
+
 func (p *Pod) Derived() resource.Pod {
 	return p.derived_
 }
@@ -221,7 +237,7 @@ func (p *Pod) SetDerived(d resource.Pod) {
 // info requests in order to manage the pod. All other commands are routed to
 // the pod's instances.
 func (p *Pod) Route(req *route.Request) route.Response {
-	log.Route(req, "Pod %q", p.Name())
+	defer log.Route(req, "Pod %q", p.Name()).End()
 
 	// Route to the appropriate resource. Instance is the only valid sub-resource.
 	if req.Top() == "instance" {
@@ -244,7 +260,7 @@ func (p *Pod) Route(req *route.Request) route.Response {
 		return route.FAIL
 	}
 
-	if err := aaa.Authorized(req, "pod", p.Name()); err != nil {
+	if err := aaa.Authorized(req, "pod", p.Name(), "cluster:"+p.Cluster().Name()); err != nil {
 		msg.Error(err.Error())
 		return route.UNAUTHORIZED
 	}
@@ -254,6 +270,15 @@ func (p *Pod) Route(req *route.Request) route.Response {
 		return route.OK
 	}
 
+	if err := aaa.Approved(req, "pod", p.Name(), "cluster:"+p.Cluster().Name()); err != nil {
+		msg.Error(err.Error())
+		return route.UNAUTHORIZED
+	}
+
+	if !confirm(req, "pod "+p.Name(), []resource.Pod{p.Derived()}) {
+		return route.FAIL
+	}
+
 	switch req.Command() {
 	case route.Load:
 		return p.load(req)
@@ -280,6 +305,19 @@ func (p *Pod) Route(req *route.Request) route.Response {
 		return p.restart(req)
 	case route.Replace:
 		return p.replace(req)
+	case route.Maintenance:
+		return p.maintenance(req)
+	case route.Patch:
+		return p.patch(req)
+	case route.Compliance:
+		msg.Info("Pod Compliance: %s", p.Name())
+		return p.routeToChildren(req)
+	case route.Grant:
+		// See grant.go
+		return p.grant(req)
+	case route.RevokeExpired:
+		// See grant.go
+		return p.revoke(req)
 	default:
 		msg.Error("Unknown pod command %q.", req.Command().String())
 	}
@@ -324,12 +362,18 @@ func podHelp(n string) {
 		{Name: route.Create.String(), Desc: fmt.Sprintf("create%s pod", name)},
 		{Name: route.Provision.String(), Desc: fmt.Sprintf("provision%s pod", name)},
 		{Name: route.Provision.String() + " users", Desc: fmt.Sprintf("update%s pod users", name)},
+		{Name: route.Provision.String() + " changed", Desc: fmt.Sprintf("provision%s pod instances not at the configured versions", name)},
 		{Name: route.Start.String(), Desc: fmt.Sprintf("start%s pod", name)},
-		{Name: route.Stop.String(), Desc: fmt.Sprintf("stop%s pod", name)},
+		{Name: route.Stop.String() + " [yes]", Desc: fmt.Sprintf("stop%s pod, yes skips confirmation", name)},
 		{Name: route.Restart.String(), Desc: fmt.Sprintf("restart%s pod", name)},
-		{Name: route.Replace.String(), Desc: fmt.Sprintf("replace%s pod", name)},
+		{Name: route.Replace.String() + " [yes]", Desc: fmt.Sprintf("replace%s pod, yes skips confirmation", name)},
+		{Name: route.Patch.String() + " [batch]", Desc: fmt.Sprintf("patch the%s pod operating systems, a batch of instances at a time", name)},
+		{Name: route.Maintenance.String() + " [duration]", Desc: fmt.Sprintf("disable paging for%s pod for the duration", name)},
+		{Name: route.Maintenance.String() + " end", Desc: fmt.Sprintf("end the%s pod maintenance window", name)},
+		{Name: route.Grant.String() + " 'user' [sudo] 'duration'", Desc: fmt.Sprintf("give a user access to%s pod until the duration expires", name)},
 		{Name: route.Audit.String(), Desc: fmt.Sprintf("audit%s pod", name)},
-		{Name: route.Destroy.String(), Desc: fmt.Sprintf("destroy%s pod", name)},
+		{Name: route.Compliance.String() + " [fix]", Desc: fmt.Sprintf("check%s pod hardening, optionally re-applying it", name)},
+		{Name: route.Destroy.String() + " [yes]", Desc: fmt.Sprintf("destroy%s pod, yes skips confirmation", name)},
 		{Name: route.Config.String(), Desc: fmt.Sprintf("provide the%s pod configuration", name)},
 		{Name: route.Info.String(), Desc: fmt.Sprintf("provide information about allocated%s pod", name)},
 		{Name: route.Help.String(), Desc: "provide this help"},
@@ -378,6 +422,13 @@ func (p *Pod) info(req *route.Request) {
 			msg.Detail("%-20s\t%s", "secondary", secondary)
 		}
 	}
+	if changed := p.changedInstances(); len(changed) > 0 {
+		names := ""
+		for _, i := range changed {
+			names += i.Name() + " "
+		}
+		msg.Detail("%-20s\t%s", "version drift", names)
+	}
 	msg.IndentInc()
 	p.RouteInOrder(req)
 	msg.IndentDec()
@@ -477,6 +528,10 @@ func (p *Pod) provision(req *route.Request) route.Response {
 		msg.Detail("Pod does not exist, skipping...")
 		return route.OK
 	}
+	if req.Flag("changed") && len(p.changedInstances()) == 0 {
+		msg.Detail("Pod is at the configured versions, skipping...")
+		return route.OK
+	}
 	if resp := p.Derived().PreProvision(req); resp != route.OK {
 		return resp
 	}
@@ -505,6 +560,29 @@ func (p *Pod) PostProvision(req *route.Request) route.Response {
 
 // Start
 
+// maintenance disables paging for the pod's instances for the duration given
+// in the request, or ends the maintenance window if the end flag is set.
+// Paging is re-enabled by the monitors when the window expires, or when the
+// instances are next provisioned.
+func (p *Pod) maintenance(req *route.Request) route.Response {
+	msg.Info("Pod Maintenance: %s", p.Name())
+	if p.Destroyed() {
+		msg.Detail("Pod does not exist, skipping...")
+		return route.OK
+	}
+	if !req.Flag("end") {
+		if _, err := maintenanceDuration(req); err != nil {
+			msg.Error(err.Error())
+			return route.FAIL
+		}
+	}
+	if resp := p.routeToChildren(req); resp != route.OK {
+		return resp
+	}
+	aaa.Accounting("Pod maintenance: %s", p.Name())
+	return route.OK
+}
+
 func (p *Pod) start(req *route.Request) route.Response {
 	msg.Info("Pod Start: %s", p.Name())
 	if p.Destroyed() {
@@ -639,6 +717,80 @@ func (p *Pod) PostReplace(req *route.Request) route.Response {
 	return route.OK
 }
 
+// Patch
+
+func (p *Pod) patch(req *route.Request) route.Response {
+	msg.Info("Pod Patch: %s", p.Name())
+	if p.Destroyed() {
+		msg.Detail("Pod does not exist, skipping...")
+		return route.OK
+	}
+	if resp := p.Derived().PrePatch(req); resp != route.OK {
+		return resp
+	}
+	if resp := p.Derived().Patch(req); resp != route.OK {
+		return resp
+	}
+	if resp := p.Derived().PostPatch(req); resp != route.OK {
+		return resp
+	}
+	msg.Detail("Pod Patched: %s", p.Name())
+	aaa.Accounting("Pod patched: %s", p.Name())
+	return route.OK
+}
+
+func (p *Pod) PrePatch(req *route.Request) route.Response {
+	return route.OK
+}
+
+// Patch patches the pod's running instances in rolling batches. Each instance
+// in a batch is updated, then those needing it are restarted, then the batch
+// is checked before moving on. Patching stops at the first failure.
+func (p *Pod) Patch(req *route.Request) route.Response {
+	if req.Flag("podonly") {
+		return route.OK
+	}
+	instances := []resource.Instance{}
+	for _, j := range p.instances.Get() {
+		i := j.(resource.Instance)
+		if !i.Created() || !i.Started() {
+			msg.Detail("Instance %s is not running, skipping...", i.Name())
+			continue
+		}
+		instances = append(instances, i)
+	}
+	size := patchBatch(req, p.Cluster().PatchBatch())
+	for n := 0; n < len(instances); n += size {
+		end := n + size
+		if end > len(instances) {
+			end = len(instances)
+		}
+		batch := instances[n:end]
+		msg.Info("Pod Patch Batch: %s, instances %d-%d of %d", p.Name(), n+1, end, len(instances))
+		for _, i := range batch {
+			if resp := i.PrePatch(req); resp != route.OK {
+				return resp
+			}
+		}
+		for _, i := range batch {
+			if resp := i.Patch(req); resp != route.OK {
+				return resp
+			}
+		}
+		for _, i := range batch {
+			if resp := i.PostPatch(req); resp != route.OK {
+				return resp
+			}
+			aaa.Accounting("Instance patched: %s, %s", i.Name(), i.Id())
+		}
+	}
+	return route.OK
+}
+
+func (p *Pod) PostPatch(req *route.Request) route.Response {
+	return route.OK
+}
+
 // Audit
 
 func (p *Pod) Audit(flags ...string) error {
@@ -101,7 +101,7 @@ func (p *pods) FindInstanceByIP(ip string) resource.Instance {
 
 // Route satisfies the embedded resource.Resource interface in resource.Pods.
 func (p *pods) Route(req *route.Request) route.Response {
-	log.Route(req, "Pods")
+	defer log.Route(req, "Pods").End()
 
 	// Route to the appropriate resource.
 	if req.Top() == "pod" {
@@ -120,7 +120,7 @@ func (p *pods) Route(req *route.Request) route.Response {
 
 	// Handle the command.
 	switch req.Command() {
-	case route.Load, route.Create, route.Provision, route.Start, route.Stop, route.Restart, route.Replace:
+	case route.Load, route.Create, route.Provision, route.Start, route.Stop, route.Restart, route.Replace, route.Patch, route.Compliance:
 		return p.RouteInOrder(req)
 	case route.Destroy:
 		return p.RouteReverseOrder(req)
@@ -55,7 +55,7 @@ func newRoleIdentifier(in resource.Instance, prov provider.DataCenter, name stri
 }
 
 func (r *roleIdentifier) Route(req *route.Request) route.Response {
-	log.Route(req, "Role %q", r.Name())
+	defer log.Route(req, "Role %q", r.Name()).End()
 	return route.FAIL
 }
 
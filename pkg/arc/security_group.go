@@ -95,7 +95,7 @@ func (s *securityGroup) Network() resource.Network {
 // SecurityGroup handled load, create, destroy, audit, help, config and info requests
 // to manage a named ssecurity group.
 func (s *securityGroup) Route(req *route.Request) route.Response {
-	log.Route(req, "SecurityGroup %q", s.Name())
+	defer log.Route(req, "SecurityGroup %q", s.Name()).End()
 
 	if req.Top() != "" {
 		s.help()
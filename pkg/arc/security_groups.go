@@ -83,7 +83,7 @@ func (s *securityGroups) Find(name string) resource.SecurityGroup {
 // in order to manage all security groups. All other commands are routed to
 // a named security group.
 func (s *securityGroups) Route(req *route.Request) route.Response {
-	log.Route(req, "SecurityGroups")
+	defer log.Route(req, "SecurityGroups").End()
 
 	group := s.Find(req.Top())
 	if group != nil {
@@ -108,9 +108,9 @@ func (s *securityGroups) Route(req *route.Request) route.Response {
 			msg.Detail("\nSecurityGroups exists, skipping...")
 			return route.OK
 		}
-		// If the create has a flag we are not going to perform a two pass create
-		// as described below. This is most likely an explicit "create norules".
-		if !req.Flags().Empty() {
+		// An explicit "create norules" isn't performed as the two pass create
+		// described below. Other flags, such as yes, don't change the create.
+		if req.Flag("norules") {
 			return s.RouteInOrder(req)
 		}
 		// If we want to create all security groups at once, and since security
@@ -152,7 +152,9 @@ func (s *securityGroups) Route(req *route.Request) route.Response {
 			msg.Detail("\nSecurityGroups does not exist, skipping...")
 			return route.OK
 		}
-		if !req.Flags().Empty() {
+		// An explicit "destroy rules_only" is a single pass. Other flags, such
+		// as yes or an approval token, don't change the destroy.
+		if req.Flag("rules_only") {
 			return s.RouteReverseOrder(req)
 		}
 		// Needs two passes to delete the security groups because the security rules
@@ -93,7 +93,7 @@ func (s *subnet) Network() resource.Network {
 // Subnet handles load, create, destroy, and info requests by delegating them
 // to the providerSubnet.
 func (s *subnet) Route(req *route.Request) route.Response {
-	log.Route(req, "Subnet %q", s.Name())
+	defer log.Route(req, "Subnet %q", s.Name()).End()
 
 	if req.Top() != "" {
 		panic("Internal error: Unknown resource " + req.Top())
@@ -105,7 +105,7 @@ func (s *subnetGroup) Find(name string) resource.Subnet {
 // SubnetGroup handled load, create, destroy, help, config and info requests
 // to manage a named subnet group.
 func (s *subnetGroup) Route(req *route.Request) route.Response {
-	log.Route(req, "SubnetGroup %q", s.Name())
+	defer log.Route(req, "SubnetGroup %q", s.Name()).End()
 
 	if req.Top() != "" {
 		s.help()
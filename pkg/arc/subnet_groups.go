@@ -88,7 +88,7 @@ func (s *subnetGroups) Get() map[string]resource.SubnetGroup {
 // in order to manage all subnet groups. All other commands are routed to
 // a named subnet group.
 func (s *subnetGroups) Route(req *route.Request) route.Response {
-	log.Route(req, "SubnetGroups")
+	defer log.Route(req, "SubnetGroups").End()
 
 	group := s.Find(req.Top())
 	if group != nil {
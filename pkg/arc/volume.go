@@ -60,7 +60,7 @@ func newVolume(comp resource.Compute, prov provider.DataCenter, cfg *config.Volu
 // Volume handles load, create, destroy, and info requests by delegating them
 // to the providerVolume.
 func (v *volume) Route(req *route.Request) route.Response {
-	log.Route(req, "Volume %q", v.Device())
+	defer log.Route(req, "Volume %q", v.Device()).End()
 	return route.FAIL
 }
 
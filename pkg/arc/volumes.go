@@ -75,7 +75,7 @@ func (v *volumes) Find(device string) resource.Volume {
 
 // Route satisfies the embedded resource.Resource interface in resource.Volumes.
 func (v *volumes) Route(req *route.Request) route.Response {
-	log.Route(req, "Volumes")
+	defer log.Route(req, "Volumes").End()
 
 	return route.FAIL
 }
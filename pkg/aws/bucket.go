@@ -175,7 +175,7 @@ func (b *bucket) EnableReplication(key resource.EncryptionKey) error {
 }
 
 func (b *bucket) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS Bucket %q", b.Name())
+	defer log.Route(req, "AWS Bucket %q", b.Name()).End()
 	return route.OK
 }
 
@@ -188,7 +188,7 @@ func (b *bucket) Audit(flags ...string) error {
 		return fmt.Errorf("Audit Object does not exist")
 	}
 	if b.bucket == nil {
-		a.Audit(aaa.Configured, "%s", b.Name())
+		a.Record(aaa.Configured, &aaa.Finding{ResourceType: "bucket", Name: b.Name()})
 	}
 	return nil
 }
@@ -102,15 +102,14 @@ func (c *bucketCache) audit(flags ...string) error {
 	}
 	for k, v := range c.cache {
 		if v.configured == nil {
-			a.Audit(aaa.Deployed, "%s", k)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "bucket", Name: k})
 		}
 	}
 	if c.unnamed != nil {
-		a.Audit(aaa.Deployed, "\r")
 		for i, v := range c.unnamed {
 			u := "\t" + strings.Replace(fmt.Sprintf("%+v", v), "\n", "\n\t", -1)
 			m := fmt.Sprintf("Unnamed Bucket %d - Bucket Name: %q %s", i+1, *v.Name, u)
-			a.Audit(aaa.Deployed, m)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "bucket", Id: aws.StringValue(v.Name), Message: m})
 		}
 	}
 	return nil
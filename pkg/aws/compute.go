@@ -29,6 +29,7 @@ package aws
 import (
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 
 	"github.com/cisco/arc/pkg/aaa"
@@ -89,7 +90,24 @@ func (c *compute) AuditEIP(flags ...string) error {
 	}
 	for _, v := range addr.Addresses {
 		if v.AssociationId == nil {
-			a.Audit(aaa.Deployed, "Elastic IP %q is not associated with anything", *v.PublicIp)
+			f := &aaa.Finding{
+				ResourceType: "eip", Name: *v.PublicIp, Id: aws.StringValue(v.AllocationId),
+				Message: fmt.Sprintf("Elastic IP %q is not associated with anything", *v.PublicIp),
+			}
+			// Only elastic IPs tagged with this datacenter are released by a
+			// fix, the others may belong to another datacenter in the account.
+			if !taggedWith(v.Tags, c.Compute.Name()) {
+				a.Record(aaa.Deployed, f)
+				continue
+			}
+			release := &ec2.ReleaseAddressInput{AllocationId: v.AllocationId}
+			if v.AllocationId == nil {
+				release = &ec2.ReleaseAddressInput{PublicIp: v.PublicIp}
+			}
+			a.Record(aaa.Deployed, f.Fix("release", fmt.Sprintf("release unattached elastic ip %s", f.Name), func() error {
+				_, err := c.ec2.ReleaseAddress(release)
+				return err
+			}))
 		}
 	}
 	return nil
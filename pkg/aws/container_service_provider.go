@@ -70,7 +70,7 @@ func newContainerServiceProvider(cfg *config.ContainerService) (provider.Contain
 		SharedConfigState: session.SharedConfigEnable,
 	}
 
-	sess, err := session.NewSessionWithOptions(opts)
+	sess, err := newSession(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +70,7 @@ func newDatabaseServiceProvider(cfg *config.DatabaseService) (provider.DatabaseS
 		SharedConfigState: session.SharedConfigEnable,
 	}
 
-	sess, err := session.NewSessionWithOptions(opts)
+	sess, err := newSession(opts)
 	if err != nil {
 		return nil, err
 	}
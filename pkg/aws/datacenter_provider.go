@@ -72,7 +72,7 @@ func NewDataCenterProvider(cfg *config.DataCenter) (provider.DataCenter, error)
 		SharedConfigState: session.SharedConfigEnable,
 	}
 
-	sess, err := session.NewSessionWithOptions(opts)
+	sess, err := newSession(opts)
 	if err != nil {
 		return nil, err
 	}
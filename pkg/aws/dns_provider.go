@@ -64,7 +64,7 @@ func NewDnsProvider(cfg *config.Dns) (provider.Dns, error) {
 		SharedConfigState: session.SharedConfigEnable,
 	}
 
-	sess, err := session.NewSessionWithOptions(opts)
+	sess, err := newSession(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +74,7 @@ func newDnsRecord(rec resource.DnsRecord, cfg *config.DnsRecord, p *dnsProvider)
 }
 
 func (r *dnsRecord) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS DNS %s Record %q", r.record.Type(), r.Id())
+	defer log.Route(req, "AWS DNS %s Record %q", r.record.Type(), r.Id()).End()
 
 	switch req.Command() {
 	case route.Create:
@@ -100,17 +100,20 @@ func (r *dnsRecord) Audit(flags ...string) error {
 	}
 	// Configured but not Deployed
 	if !r.Created() {
-		a.Audit(aaa.Configured, "Dns Record %q is configured but not deployed", r.Name())
+		a.Record(aaa.Configured, &aaa.Finding{
+			ResourceType: "dns record", Name: r.Name(),
+			Message: fmt.Sprintf("Dns Record %q is configured but not deployed", r.Name()),
+		})
 	}
 	// Mismatches
 	if r.rrset == nil {
 		return nil
 	}
 	if r.rrset.Type != nil && *r.rrset.Type != r.Type() {
-		a.Audit(aaa.Mismatched, "Dns Record %q | Configured: %q - Deployed: %q", r.Name(), r.Type(), *r.rrset.Type)
+		a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "dns record", Name: r.Name(), Id: r.Id(), Attribute: "type", Expected: r.Type(), Actual: *r.rrset.Type})
 	}
 	if r.rrset.TTL != nil && *r.rrset.TTL != int64(r.Ttl()) {
-		a.Audit(aaa.Mismatched, "Dns Record %q | Configured: \"%d\" - Deployed: \"%d\"", r.Name(), r.Ttl(), *r.rrset.TTL)
+		a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "dns record", Name: r.Name(), Id: r.Id(), Attribute: "ttl", Expected: fmt.Sprint(r.Ttl()), Actual: fmt.Sprint(*r.rrset.TTL)})
 	}
 	return nil
 }
@@ -89,7 +89,7 @@ func (k *encryptionKey) Audit(flags ...string) error {
 		return fmt.Errorf("Audit Object does not exist")
 	}
 	if k.encryptionKey == nil {
-		a.Audit(aaa.Configured, "%s", k.Name())
+		a.Record(aaa.Configured, &aaa.Finding{ResourceType: "encryption key", Name: k.Name()})
 	}
 	return nil
 }
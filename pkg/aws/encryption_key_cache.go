@@ -102,14 +102,13 @@ func (c *encryptionKeyCache) audit(flags ...string) error {
 	}
 	for k, v := range c.cache {
 		if v.configured == nil && !strings.Contains(aws.StringValue(v.deployed.AliasName), "aws") {
-			a.Audit(aaa.Deployed, "%s", k)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "encryption key", Name: k, Id: aws.StringValue(v.deployed.TargetKeyId)})
 		}
 	}
 	if c.unnamed != nil {
-		a.Audit(aaa.Deployed, "\r")
 		for _, v := range c.unnamed {
 			m := fmt.Sprintf("Unnamed Encryption Key %s ", aws.StringValue(v.KeyArn))
-			a.Audit(aaa.Deployed, m)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "encryption key", Id: aws.StringValue(v.KeyId), Message: m})
 		}
 	}
 	return nil
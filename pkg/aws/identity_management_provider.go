@@ -74,7 +74,7 @@ func newIdentityManagementProvider(cfg *config.Amp) (provider.IdentityManagement
 		Profile:           name,
 		SharedConfigState: session.SharedConfigEnable,
 	}
-	sess, err := session.NewSessionWithOptions(opts)
+	sess, err := newSession(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +29,7 @@ package aws
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -130,7 +131,7 @@ func newInstance(in resource.Instance, cfg *config.Instance, p *dataCenterProvid
 }
 
 func (i *instance) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS Instance %q", i.Name())
+	defer log.Route(req, "AWS Instance %q", i.Name()).End()
 
 	switch req.Command() {
 	case route.Load:
@@ -212,7 +213,40 @@ func (i *instance) PublicIPAddress() string {
 }
 
 func (i *instance) SetTags(t map[string]string) error {
-	return setTags(i.ec2, t, i.Id())
+	if err := setTags(i.ec2, t, i.Id()); err != nil {
+		return err
+	}
+	// Keep the cached tags in step with the ones just set.
+	if i.instance == nil {
+		return nil
+	}
+	for k, v := range t {
+		found := false
+		for _, tag := range i.instance.Tags {
+			if tag.Key != nil && *tag.Key == k {
+				tag.Value = aws.String(v)
+				found = true
+				break
+			}
+		}
+		if !found {
+			i.instance.Tags = append(i.instance.Tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+	}
+	return nil
+}
+
+func (i *instance) Tags() map[string]string {
+	tags := map[string]string{}
+	if i.instance == nil {
+		return tags
+	}
+	for _, tag := range i.instance.Tags {
+		if tag.Key != nil && tag.Value != nil {
+			tags[*tag.Key] = *tag.Value
+		}
+	}
+	return tags
 }
 
 func (i *instance) Audit(flags ...string) error {
@@ -226,7 +260,7 @@ func (i *instance) Audit(flags ...string) error {
 	}
 	// Configured but not deployed
 	if i.Destroyed() {
-		a.Audit(aaa.Configured, "%s", i.Name())
+		a.Record(aaa.Configured, &aaa.Finding{ResourceType: "instance", Name: i.Name()})
 		return nil
 	}
 	// Mismatched
@@ -245,28 +279,34 @@ func (i *instance) compareImage(a *aaa.Audit) {
 		d = i.provider.findById(*i.instance.ImageId)
 	}
 	if i.instance.ImageId != nil && d != "" && d != i.Image() {
-		a.Audit(aaa.Mismatched, "Instance %q | Configured Image: %q - Deployed Image: %q", i.Name(), i.Image(), d)
+		a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "image", Expected: i.Image(), Actual: d})
 	}
 }
 
 func (i *instance) compareInstanceType(a *aaa.Audit) {
 	if i.instance.InstanceType != nil && *i.instance.InstanceType != i.Instance.InstanceType() {
-		a.Audit(aaa.Mismatched, "Instance %q | Configured Instance Type: %q - Deployed Instance Type: %q", i.Name(), i.Instance.InstanceType(), *i.instance.InstanceType)
+		a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "instance type", Expected: i.Instance.InstanceType(), Actual: *i.instance.InstanceType})
 	}
 }
 
 func (i *instance) compareRole(a *aaa.Audit) {
 	// Role Configured but not deployed
 	if i.instance.IamInstanceProfile == nil && i.Instance.Role() != "" {
-		a.Audit(aaa.Mismatched, "Instance %q | Configured Role: %q - No Role Deployed", i.Name(), i.Instance.Role())
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "role", Expected: i.Instance.Role(),
+			Message: fmt.Sprintf("Instance %q | Configured Role: %q - No Role Deployed", i.Name(), i.Instance.Role()),
+		})
 	}
 	if i.instance.IamInstanceProfile != nil && i.instance.IamInstanceProfile.Arn != nil {
 		if i.Instance.Role() == "" {
 			// Role Deployed but not configured
-			a.Audit(aaa.Mismatched, "Instance %q | No Configured Role - Deployed Role: %q", i.Name(), filepath.Base(*i.instance.IamInstanceProfile.Arn))
+			a.Record(aaa.Mismatched, &aaa.Finding{
+				ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "role", Actual: filepath.Base(*i.instance.IamInstanceProfile.Arn),
+				Message: fmt.Sprintf("Instance %q | No Configured Role - Deployed Role: %q", i.Name(), filepath.Base(*i.instance.IamInstanceProfile.Arn)),
+			})
 		} else if i.Instance.Role() != "" && i.Instance.Role() != filepath.Base(*i.instance.IamInstanceProfile.Arn) {
 			// Roles do not match
-			a.Audit(aaa.Mismatched, "Instance %q | Configured Role: %q - Deployed Role: %q", i.Name(), i.Instance.Role(), filepath.Base(*i.instance.IamInstanceProfile.Arn))
+			a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "role", Expected: i.Instance.Role(), Actual: filepath.Base(*i.instance.IamInstanceProfile.Arn)})
 		}
 	}
 }
@@ -285,7 +325,10 @@ func (i *instance) compareSecgroups(a *aaa.Audit) {
 				}
 			}
 			if !found {
-				a.Audit(aaa.Mismatched, "Instance %q | This instance is a member of Secgroup %q but isn't configured to be", i.Name(), *d.GroupName)
+				a.Record(aaa.Mismatched, &aaa.Finding{
+					ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "secgroup", Actual: *d.GroupName,
+					Message: fmt.Sprintf("Instance %q | This instance is a member of Secgroup %q but isn't configured to be", i.Name(), *d.GroupName),
+				})
 				found = false
 			}
 		}
@@ -300,7 +343,10 @@ func (i *instance) compareSecgroups(a *aaa.Audit) {
 				}
 			}
 			if !found {
-				a.Audit(aaa.Mismatched, "Instance %q | This instance is configured to be a member of Secgroup %q but isn't", i.Name(), c)
+				a.Record(aaa.Mismatched, &aaa.Finding{
+					ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "secgroup", Expected: c,
+					Message: fmt.Sprintf("Instance %q | This instance is configured to be a member of Secgroup %q but isn't", i.Name(), c),
+				})
 				found = false
 			}
 		}
@@ -319,7 +365,7 @@ func (i *instance) compareSubnet(a *aaa.Audit) {
 				}
 			}
 		}
-		a.Audit(aaa.Mismatched, "Instance %q | Configured Subnet: %q - Deployed Subnet: %q", i.Name(), cfgName, depName)
+		a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "subnet", Expected: cfgName, Actual: depName})
 	}
 }
 
@@ -329,7 +375,7 @@ func (i *instance) compareVolumes(a *aaa.Audit) {
 	}
 	// Correct Number of Volumes
 	if len(i.instance.BlockDeviceMappings) != len(i.volumes) {
-		a.Audit(aaa.Mismatched, "Instance %q | Number of Configured Volumes: %d - Number of Deployed Volumes: %d", i.Name(), len(i.volumes), len(i.instance.BlockDeviceMappings))
+		a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "instance", Name: i.Name(), Id: i.Id(), Attribute: "volumes", Expected: strconv.Itoa(len(i.volumes)), Actual: strconv.Itoa(len(i.instance.BlockDeviceMappings))})
 	}
 }
 
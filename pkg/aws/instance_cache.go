@@ -43,6 +43,7 @@ type instanceCacheEntry struct {
 }
 
 type instanceCache struct {
+	ec2     *ec2.EC2
 	cache   map[string]*instanceCacheEntry
 	unnamed []*ec2.Instance
 }
@@ -51,6 +52,7 @@ func newInstanceCache(c *compute) (*instanceCache, error) {
 	log.Debug("Initializing AWS Instance Cache")
 
 	i := &instanceCache{
+		ec2:   c.ec2,
 		cache: map[string]*instanceCacheEntry{},
 	}
 
@@ -175,16 +177,28 @@ func (c *instanceCache) audit(flags ...string) error {
 			}
 		}
 		if !skip && v.configured == nil {
-			a.Audit(aaa.Deployed, "%s", k)
+			f := &aaa.Finding{ResourceType: "instance", Name: k, Id: aws.StringValue(v.deployed.InstanceId)}
+			a.Record(aaa.Deployed, f.Fix("terminate", fmt.Sprintf("terminate rogue instance %s %s", f.Name, f.Id), c.terminate(f.Id)))
 		}
 	}
 	if c.unnamed != nil {
-		a.Audit(aaa.Deployed, "\r")
 		for i, v := range c.unnamed {
 			u := "\t" + strings.Replace(fmt.Sprintf("%+v", v), "\n", "\n\t", -1)
 			m := fmt.Sprintf("Unnamed Instance %d - InstanceId: %q %s", i+1, *v.InstanceId, u)
-			a.Audit(aaa.Deployed, m)
+			// Untagged instances can't be tied to this datacenter, so they
+			// are reported without a fix.
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "instance", Id: aws.StringValue(v.InstanceId), Message: m})
 		}
 	}
 	return nil
 }
+
+// terminate returns the audit fix terminating the rogue instance.
+func (c *instanceCache) terminate(id string) func() error {
+	return func() error {
+		_, err := c.ec2.TerminateInstances(&ec2.TerminateInstancesInput{
+			InstanceIds: []*string{aws.String(id)},
+		})
+		return err
+	}
+}
@@ -59,7 +59,7 @@ func newInternetGateway(c *ec2.EC2, n *network, name string) (*internetGateway,
 }
 
 func (i *internetGateway) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS InternetGateway %q", i.name())
+	defer log.Route(req, "AWS InternetGateway %q", i.name()).End()
 
 	if req.Top() != "" {
 		i.help()
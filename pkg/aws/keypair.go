@@ -55,7 +55,7 @@ func newKeyPair(cfg *config.KeyPair, c *ec2.EC2) (resource.ProviderKeyPair, erro
 }
 
 func (k *keypair) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS KeyPair %q", k.Name())
+	defer log.Route(req, "AWS KeyPair %q", k.Name()).End()
 
 	switch req.Command() {
 	case route.Create:
@@ -86,7 +86,7 @@ func newNatGateway(c *ec2.EC2, net resource.Network, name, availabilityZone stri
 }
 
 func (n *natGateway) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS NatGateway %q", n.name())
+	defer log.Route(req, "AWS NatGateway %q", n.name()).End()
 
 	if req.Top() != "" {
 		n.help()
@@ -73,7 +73,7 @@ func newNatGateways(c *ec2.EC2, n resource.Network) (*natGateways, error) {
 }
 
 func (n *natGateways) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS NatGateways")
+	defer log.Route(req, "AWS NatGateways").End()
 
 	if natGateway := n.find(req.Top()); natGateway != nil {
 		return natGateway.Route(req.Pop())
@@ -97,7 +97,7 @@ func newNetwork(cfg *config.Network, c *ec2.EC2) (*network, error) {
 }
 
 func (n *network) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS Network")
+	defer log.Route(req, "AWS Network").End()
 
 	// Route to the appropriate resource.
 	switch req.Top() {
@@ -65,7 +65,7 @@ func newNetworkPost(net resource.Network, cfg *config.Network, c *ec2.EC2) (*net
 }
 
 func (n *networkPost) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS Network Post")
+	defer log.Route(req, "AWS Network Post").End()
 
 	// Do sub-object routing first.
 	switch req.Top() {
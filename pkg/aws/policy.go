@@ -74,21 +74,30 @@ func (p *policy) Audit(flags ...string) error {
 		return fmt.Errorf("Audit Object does not exist")
 	}
 	if p.policy == nil {
-		a.Audit(aaa.Configured, "%s", p.Name())
+		a.Record(aaa.Configured, &aaa.Finding{ResourceType: "policy", Name: p.Name()})
 		return nil
 	}
 	log.Debug("Deployed description %q", aws.StringValue(p.policy.Description))
 	log.Debug("Configed description %q", p.Description())
 	if p.policy.Description != nil && p.Description() != "" && strings.Compare(aws.StringValue(p.policy.Description), p.Description()) != 0 {
-		a.Audit(aaa.Mismatched, "Policy %q's description does not match configured description", aws.StringValue(p.policy.PolicyName))
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "policy", Name: aws.StringValue(p.policy.PolicyName), Attribute: "description", Expected: p.Description(), Actual: aws.StringValue(p.policy.Description),
+			Message: fmt.Sprintf("Policy %q's description does not match configured description", aws.StringValue(p.policy.PolicyName)),
+		})
 		return nil
 	}
 	if p.policy.Description != nil && p.Description() == "" {
-		a.Audit(aaa.Mismatched, "Policy %q has a deployed description but not a configured one", aws.StringValue(p.policy.PolicyName))
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "policy", Name: aws.StringValue(p.policy.PolicyName), Attribute: "description", Actual: aws.StringValue(p.policy.Description),
+			Message: fmt.Sprintf("Policy %q has a deployed description but not a configured one", aws.StringValue(p.policy.PolicyName)),
+		})
 		return nil
 	}
 	if p.policy.Description == nil && p.Description() != "" {
-		a.Audit(aaa.Mismatched, "Policy %q has a configured description but not a deployed one", aws.StringValue(p.policy.PolicyName))
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "policy", Name: aws.StringValue(p.policy.PolicyName), Attribute: "description", Expected: p.Description(),
+			Message: fmt.Sprintf("Policy %q has a configured description but not a deployed one", aws.StringValue(p.policy.PolicyName)),
+		})
 		return nil
 	}
 	return nil
@@ -131,15 +131,14 @@ func (c *policyCache) audit(flags ...string) error {
 	}
 	for k, v := range c.cache {
 		if v.configured == nil {
-			a.Audit(aaa.Deployed, "%s", k)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "policy", Name: k, Id: aws.StringValue(v.deployed.PolicyId)})
 		}
 	}
 	if c.unnamed != nil {
-		a.Audit(aaa.Deployed, "\r")
 		for i, v := range c.unnamed {
 			u := "\t" + strings.Replace(fmt.Sprintf("%+v", v), "\n", "\n\t", -1)
 			m := fmt.Sprintf("Unnamed Policy %d - Policy ID: %q %s", i+1, *v.PolicyId, u)
-			a.Audit(aaa.Deployed, m)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "policy", Id: aws.StringValue(v.PolicyId), Message: m})
 		}
 	}
 	return nil
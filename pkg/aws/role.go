@@ -105,26 +105,41 @@ func (r *role) Audit(flags ...string) error {
 		return fmt.Errorf("Audit Object does not exist")
 	}
 	if r.role == nil {
-		a.Audit(aaa.Configured, "%s", r.Name())
+		a.Record(aaa.Configured, &aaa.Finding{ResourceType: "role", Name: r.Name()})
 		return nil
 	}
 	// Mismatched Policies
 	for _, p := range r.roguePolicies() {
-		a.Audit(aaa.Mismatched, "Role %q's policy %q is deployed but not configured", r.Name(), p)
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "role", Name: r.Name(), Attribute: "policy", Actual: p,
+			Message: fmt.Sprintf("Role %q's policy %q is deployed but not configured", r.Name(), p),
+		})
 	}
 
 	for _, p := range r.orphanedPolicies() {
-		a.Audit(aaa.Mismatched, "Role %q's policy %q is configured but not deployed", r.Name(), p)
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "role", Name: r.Name(), Attribute: "policy", Expected: p,
+			Message: fmt.Sprintf("Role %q's policy %q is configured but not deployed", r.Name(), p),
+		})
 	}
 	// Mismatched Description
 	if r.role.Description != nil && r.Description() != "" && strings.Compare(aws.StringValue(r.role.Description), r.Description()) != 0 {
-		a.Audit(aaa.Mismatched, "Role %q's description does not match configured description", aws.StringValue(r.role.RoleName))
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "role", Name: aws.StringValue(r.role.RoleName), Attribute: "description", Expected: r.Description(), Actual: aws.StringValue(r.role.Description),
+			Message: fmt.Sprintf("Role %q's description does not match configured description", aws.StringValue(r.role.RoleName)),
+		})
 	}
 	if r.role.Description != nil && r.Description() == "" {
-		a.Audit(aaa.Mismatched, "Role %q has a deployed description but not a configured one", aws.StringValue(r.role.RoleName))
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "role", Name: aws.StringValue(r.role.RoleName), Attribute: "description", Actual: aws.StringValue(r.role.Description),
+			Message: fmt.Sprintf("Role %q has a deployed description but not a configured one", aws.StringValue(r.role.RoleName)),
+		})
 	}
 	if r.role.Description == nil && r.Description() != "" {
-		a.Audit(aaa.Mismatched, "Role %q has a configured description but not a deployed one", aws.StringValue(r.role.RoleName))
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "role", Name: aws.StringValue(r.role.RoleName), Attribute: "description", Expected: r.Description(),
+			Message: fmt.Sprintf("Role %q has a configured description but not a deployed one", aws.StringValue(r.role.RoleName)),
+		})
 	}
 	return nil
 }
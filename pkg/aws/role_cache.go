@@ -126,15 +126,14 @@ func (c *roleCache) audit(flags ...string) error {
 	}
 	for k, v := range c.cache {
 		if v.configured == nil {
-			a.Audit(aaa.Deployed, "%s", k)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "role", Name: k, Id: aws.StringValue(v.deployed.RoleId)})
 		}
 	}
 	if c.unnamed != nil {
-		a.Audit(aaa.Deployed, "\r")
 		for i, v := range c.unnamed {
 			u := "\t" + strings.Replace(fmt.Sprintf("%+v", v), "\n", "\n\t", -1)
 			m := fmt.Sprintf("Unnamed Policy %d - Policy ID: %q %s", i+1, *v.RoleId, u)
-			a.Audit(aaa.Deployed, m)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "role", Id: aws.StringValue(v.RoleId), Message: m})
 		}
 	}
 	return nil
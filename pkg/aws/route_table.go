@@ -65,7 +65,7 @@ func newRouteTable(c *ec2.EC2, n *network, name, access, availabilityZone string
 }
 
 func (r *routeTable) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS RouteTable %q", r.name())
+	defer log.Route(req, "AWS RouteTable %q", r.name()).End()
 
 	if req.Top() != "" {
 		r.help()
@@ -94,7 +94,7 @@ func newRouteTables(c *ec2.EC2, n *network) (*routeTables, error) {
 }
 
 func (r *routeTables) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS RouteTables")
+	defer log.Route(req, "AWS RouteTables").End()
 
 	if routeTable := r.find(req.Top()); routeTable != nil {
 		return routeTable.Route(req.Pop())
@@ -72,7 +72,7 @@ func newSecurityGroup(net resource.Network, cfg *config.SecurityGroup, c *ec2.EC
 }
 
 func (s *securityGroup) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS Security Group %q", s.Name())
+	defer log.Route(req, "AWS Security Group %q", s.Name()).End()
 
 	switch req.Command() {
 	case route.Create:
@@ -375,7 +375,13 @@ func (s *securityGroup) Audit(flags ...string) error {
 	}
 	a := aaa.AuditBuffer[flags[0]]
 	if s.Destroyed() {
-		a.Audit(aaa.Configured, "%s", s.Name())
+		f := &aaa.Finding{ResourceType: "secgroup", Name: s.Name()}
+		a.Record(aaa.Configured, f.Fix("create", fmt.Sprintf("create secgroup %s", s.Name()), func() error {
+			if s.create(aaa.FixRequest(route.Create)) != route.OK {
+				return fmt.Errorf("Unable to create secgroup %s", s.Name())
+			}
+			return nil
+		}))
 		return nil
 	}
 	// Compare security rules loaded from AWS against secrules created from
@@ -385,7 +391,14 @@ func (s *securityGroup) Audit(flags ...string) error {
 		return err
 	}
 	verbose := true
-	s.secrules.compareEqual(cfgSecrules, verbose, s.Name(), flags...)
+	if !s.secrules.compareEqual(cfgSecrules, verbose, s.Name(), flags...) {
+		a.Remedy(aaa.Mismatched, s.Name(), "resync", fmt.Sprintf("resync the rules of secgroup %s", s.Name()), func() error {
+			if s.update(aaa.FixRequest(route.Provision)) != route.OK {
+				return fmt.Errorf("Unable to resync secgroup %s", s.Name())
+			}
+			return nil
+		})
+	}
 	return nil
 }
 
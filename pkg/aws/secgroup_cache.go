@@ -76,7 +76,7 @@ func newSecurityGroupCache(n *network) (*securityGroupCache, error) {
 		if s.Tags == nil {
 			log.Verbose("Unamed Security Group")
 			if s.GroupId != nil {
-				log.Verbose("\t\t&s", &s.GroupId)
+				log.Verbose("\t\t%s", *s.GroupId)
 			}
 			c.unnamed = append(c.unnamed, s)
 			continue
@@ -93,7 +93,7 @@ func newSecurityGroupCache(n *network) (*securityGroupCache, error) {
 		if name == "" {
 			log.Verbose("Unamed Security Group")
 			if s.GroupId != nil {
-				log.Verbose("\t\t&s", &s.GroupId)
+				log.Verbose("\t\t%s", *s.GroupId)
 			}
 			c.unnamed = append(c.unnamed, s)
 			continue
@@ -127,15 +127,14 @@ func (c *securityGroupCache) audit(flags ...string) error {
 	a := aaa.AuditBuffer[flags[0]]
 	for k, v := range c.cache {
 		if v.configured == nil {
-			a.Audit(aaa.Deployed, "%s", k)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "secgroup", Name: k, Id: aws.StringValue(v.deployed.GroupId)})
 		}
 	}
 	if c.unnamed != nil {
-		a.Audit(aaa.Deployed, "\r")
 		for i, v := range c.unnamed {
 			u := "\t" + strings.Replace(fmt.Sprintf("%+v", v), "\n", "\n\t", -1)
 			m := fmt.Sprintf("Unnamed Security Group %d - GroupId: %s\n%s", i+1, *v.GroupId, u)
-			a.Audit(aaa.Deployed, m)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "secgroup", Id: aws.StringValue(v.GroupId), Message: m})
 		}
 	}
 	return nil
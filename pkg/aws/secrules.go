@@ -212,7 +212,10 @@ func (r *securityRules) compareEqual(cfg *securityRules, quiet bool, name string
 				msg.Detail("Security Group: %q\nIngress rule is configured but not deployed\n%s", name, indentRule(rule))
 			}
 			if !doAudit {
-				a.Audit(aaa.Mismatched, "\n> Security Group: %q - Ingress rule is configured but not deployed\n%s", name, indentRule(rule))
+				a.Record(aaa.Mismatched, &aaa.Finding{
+					ResourceType: "secgroup", Name: name, Attribute: "ingress rule", Expected: fmt.Sprintf("%+v", rule),
+					Message: fmt.Sprintf("\n> Security Group: %q - Ingress rule is configured but not deployed\n%s", name, indentRule(rule)),
+				})
 			}
 			found = true
 		}
@@ -223,7 +226,10 @@ func (r *securityRules) compareEqual(cfg *securityRules, quiet bool, name string
 				msg.Detail("Security Group: %q\nIngress rule is deployed but not configured\n%s", name, indentRule(rule))
 			}
 			if !doAudit {
-				a.Audit(aaa.Mismatched, "\n> Security Group: %q - Ingress rule is deployed but not configured\n%s", name, indentRule(rule))
+				a.Record(aaa.Mismatched, &aaa.Finding{
+					ResourceType: "secgroup", Name: name, Attribute: "ingress rule", Actual: fmt.Sprintf("%+v", rule),
+					Message: fmt.Sprintf("\n> Security Group: %q - Ingress rule is deployed but not configured\n%s", name, indentRule(rule)),
+				})
 			}
 			found = true
 		}
@@ -234,7 +240,10 @@ func (r *securityRules) compareEqual(cfg *securityRules, quiet bool, name string
 				msg.Detail("Security Group: %q\nEgress rule is configured but not deployed\n%s", name, indentRule(rule))
 			}
 			if !doAudit {
-				a.Audit(aaa.Mismatched, "\n> Security Group: %q - Egress rule is configured but not deployed\n%s", name, indentRule(rule))
+				a.Record(aaa.Mismatched, &aaa.Finding{
+					ResourceType: "secgroup", Name: name, Attribute: "egress rule", Expected: fmt.Sprintf("%+v", rule),
+					Message: fmt.Sprintf("\n> Security Group: %q - Egress rule is configured but not deployed\n%s", name, indentRule(rule)),
+				})
 			}
 			found = true
 		}
@@ -245,7 +254,10 @@ func (r *securityRules) compareEqual(cfg *securityRules, quiet bool, name string
 				msg.Detail("Security Group: %q\nEgress rule is deployed but not configured\n%s", name, indentRule(rule))
 			}
 			if !doAudit {
-				a.Audit(aaa.Mismatched, "\n> Security Group: %q - Egress rule is deployed but not configured\n%s", name, indentRule(rule))
+				a.Record(aaa.Mismatched, &aaa.Finding{
+					ResourceType: "secgroup", Name: name, Attribute: "egress rule", Actual: fmt.Sprintf("%+v", rule),
+					Message: fmt.Sprintf("\n> Security Group: %q - Egress rule is deployed but not configured\n%s", name, indentRule(rule)),
+				})
 			}
 			found = true
 		}
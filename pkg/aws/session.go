@@ -0,0 +1,70 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package aws
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/cisco/arc/pkg/trace"
+)
+
+// newSession creates an aws session whose api calls are recorded as timing spans.
+func newSession(opts session.Options) (*session.Session, error) {
+	sess, err := session.NewSessionWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	sess.Handlers.Validate.PushFront(startSpan)
+	sess.Handlers.Complete.PushBack(endSpan)
+	return sess, nil
+}
+
+var spans = struct {
+	sync.Mutex
+	m map[*request.Request]*trace.Span
+}{m: map[*request.Request]*trace.Span{}}
+
+func startSpan(r *request.Request) {
+	s := trace.Start("aws", r.ClientInfo.ServiceName+" "+r.Operation.Name)
+	if s == nil {
+		return
+	}
+	spans.Lock()
+	spans.m[r] = s
+	spans.Unlock()
+}
+
+func endSpan(r *request.Request) {
+	spans.Lock()
+	s := spans.m[r]
+	delete(spans.m, r)
+	spans.Unlock()
+	s.End()
+}
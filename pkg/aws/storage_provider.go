@@ -82,7 +82,7 @@ func newStorageProvider(cfg *config.Amp) (provider.Storage, error) {
 			Profile:           name,
 			SharedConfigState: session.SharedConfigEnable,
 		}
-		sess, err := session.NewSessionWithOptions(opts)
+		sess, err := newSession(opts)
 		if err != nil {
 			return nil, err
 		}
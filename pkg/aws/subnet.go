@@ -70,7 +70,7 @@ func newSubnet(net resource.Network, cfg *config.Subnet, c *ec2.EC2) (*subnet, e
 }
 
 func (s *subnet) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS Subnet %q", s.Name())
+	defer log.Route(req, "AWS Subnet %q", s.Name()).End()
 
 	switch req.Command() {
 	case route.Create:
@@ -357,14 +357,14 @@ func (s *subnet) Audit(flags ...string) error {
 	}
 	a := aaa.AuditBuffer[flags[0]]
 	if !s.Created() {
-		a.Audit(aaa.Configured, "%s", s.Name())
+		a.Record(aaa.Configured, &aaa.Finding{ResourceType: "subnet", Name: s.Name()})
 		return nil
 	}
 	if s.CidrBlock() != *s.subnet.CidrBlock {
-		a.Audit(aaa.Mismatched, "%s: cidr block mismatch - configured: %s, deployed: %s", s.Name(), s.CidrBlock(), *s.subnet.CidrBlock)
+		a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "subnet", Name: s.Name(), Id: s.Id(), Attribute: "cidr block", Expected: s.CidrBlock(), Actual: *s.subnet.CidrBlock})
 	}
 	if s.AvailabilityZone() != *s.subnet.AvailabilityZone {
-		a.Audit(aaa.Mismatched, "%s: az mismatch - configured: %s, deployed: %s", s.Name(), s.AvailabilityZone(), *s.subnet.AvailabilityZone)
+		a.Record(aaa.Mismatched, &aaa.Finding{ResourceType: "subnet", Name: s.Name(), Id: s.Id(), Attribute: "availability zone", Expected: s.AvailabilityZone(), Actual: *s.subnet.AvailabilityZone})
 	}
 	return nil
 }
@@ -92,7 +92,7 @@ func newSubnetCache(n *network) (*subnetCache, error) {
 		if name == "" {
 			log.Verbose("Unamed subnet")
 			if s.SubnetId != nil {
-				log.Verbose("\t\t&s", &s.SubnetId)
+				log.Verbose("\t\t%s", *s.SubnetId)
 			}
 			c.unnamed = append(c.unnamed, s)
 			continue
@@ -135,15 +135,14 @@ func (c *subnetCache) audit(flags ...string) error {
 	a := aaa.AuditBuffer[flags[0]]
 	for k, v := range c.cache {
 		if v.configured == nil {
-			a.Audit(aaa.Deployed, "%s", k)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "subnet", Name: k, Id: aws.StringValue(v.deployed.SubnetId)})
 		}
 	}
 	if c.unnamed != nil {
-		a.Audit(aaa.Deployed, "\r")
 		for i, v := range c.unnamed {
 			u := "\t" + strings.Replace(fmt.Sprintf("%+v", v), "\n", "\n\t", -1)
 			m := fmt.Sprintf("Unnamed Subnet %d - SubnetId: %s CidrBlock: %s\n%s", i+1, *v.SubnetId, *v.CidrBlock, u)
-			a.Audit(aaa.Deployed, m)
+			a.Record(aaa.Deployed, &aaa.Finding{ResourceType: "subnet", Id: aws.StringValue(v.SubnetId), Message: m})
 		}
 	}
 	return nil
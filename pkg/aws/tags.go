@@ -68,6 +68,16 @@ func createTags(c *ec2.EC2, name string, rsrcId string, req *route.Request) erro
 	return err
 }
 
+// taggedWith returns true if the tags include the DataCenter tag of the given datacenter.
+func taggedWith(tags []*ec2.Tag, datacenter string) bool {
+	for _, t := range tags {
+		if t != nil && aws.StringValue(t.Key) == "DataCenter" && aws.StringValue(t.Value) == datacenter {
+			return true
+		}
+	}
+	return false
+}
+
 func printTags(tags []*ec2.Tag) {
 	if tags == nil {
 		return
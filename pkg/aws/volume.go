@@ -99,18 +99,27 @@ func (v *volume) Audit(flags ...string) error {
 	}
 	// Configured but not Deployed
 	if v.Destroyed() && v.instance != nil {
-		a.Audit(aaa.Mismatched, "Instance %q | The volume %s, %s is configured, but not deployed", v.instance.Name(), v.Device(), v.MountPoint())
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "instance", Name: v.instance.Name(), Id: v.instance.Id(), Attribute: "volume", Expected: v.Device(),
+			Message: fmt.Sprintf("Instance %q | The volume %s, %s is configured, but not deployed", v.instance.Name(), v.Device(), v.MountPoint()),
+		})
 		// Have the function return early if there aren't volumes deployed to compare with
 		return nil
 	}
 	// Mismatches
 	// Encrypted Volumes?
 	if v.volume.Encrypted != nil && !*v.volume.Encrypted && !v.Boot() {
-		a.Audit(aaa.Mismatched, "Instance %q | Deployed Volume %q is not encrypted", v.instance.Name(), *v.volume.VolumeId)
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "volume", Name: v.instance.Name(), Id: *v.volume.VolumeId, Attribute: "encrypted", Expected: "true", Actual: "false",
+			Message: fmt.Sprintf("Instance %q | Deployed Volume %q is not encrypted", v.instance.Name(), *v.volume.VolumeId),
+		})
 	}
 	// Correct Size
 	if v.volume.Size != nil && *v.volume.Size != v.Size() {
-		a.Audit(aaa.Mismatched, "Instance %q | Configured Volume Size: %d - Deployed Volume Size: %d", v.instance.Name(), v.Size(), v.volume.Size)
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "volume", Name: v.instance.Name(), Id: v.Id(), Attribute: "size", Expected: fmt.Sprint(v.Size()), Actual: fmt.Sprint(*v.volume.Size),
+			Message: fmt.Sprintf("Instance %q | Configured Volume Size: %d - Deployed Volume Size: %d", v.instance.Name(), v.Size(), *v.volume.Size),
+		})
 	}
 	return nil
 }
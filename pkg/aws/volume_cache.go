@@ -42,6 +42,7 @@ type volumeCacheEntry struct {
 }
 
 type volumeCache struct {
+	ec2   *ec2.EC2
 	cache map[string]*volumeCacheEntry
 }
 
@@ -49,6 +50,7 @@ func newVolumeCache(c *compute) (*volumeCache, error) {
 	log.Debug("Initializing AWS Volume Cache")
 
 	v := &volumeCache{
+		ec2:   c.ec2,
 		cache: map[string]*volumeCacheEntry{},
 	}
 
@@ -139,7 +141,22 @@ func (c *volumeCache) audit(flags ...string) error {
 		case "in-use", "pending":
 			continue
 		default:
-			a.Audit(aaa.Deployed, "Volume %q is not attached to any instance", *v.deployed.VolumeId)
+			id := *v.deployed.VolumeId
+			f := &aaa.Finding{
+				ResourceType: "volume", Id: id,
+				Message: fmt.Sprintf("Volume %q is not attached to any instance", id),
+			}
+			// Only available volumes can be deleted. Volumes that are
+			// being created, deleted or are in error are reported without
+			// a fix.
+			if *v.deployed.State != "available" {
+				a.Record(aaa.Deployed, f)
+				continue
+			}
+			a.Record(aaa.Deployed, f.Fix("delete", fmt.Sprintf("delete orphaned volume %s", id), func() error {
+				_, err := c.ec2.DeleteVolume(&ec2.DeleteVolumeInput{VolumeId: aws.String(id)})
+				return err
+			}))
 		}
 	}
 	return nil
@@ -60,7 +60,7 @@ func newVpc(c *ec2.EC2, n *network) (*vpc, error) {
 }
 
 func (v *vpc) Route(req *route.Request) route.Response {
-	log.Route(req, "AWS Vpc")
+	defer log.Route(req, "AWS Vpc").End()
 
 	if req.TestFlag() {
 		msg.Detail("Test. Skipping...")
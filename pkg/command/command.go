@@ -36,6 +36,8 @@ import (
 	"github.com/cisco/arc/pkg/log"
 	"github.com/cisco/arc/pkg/msg"
 	"github.com/cisco/arc/pkg/resource"
+	"github.com/cisco/arc/pkg/ssh"
+	"github.com/cisco/arc/pkg/trace"
 )
 
 //---------------------------------------------------------------------------
@@ -154,7 +156,8 @@ func RunLocal(c Command) bool {
 
 // RunRemoteWithOutput copies the source script from the given command to
 // the destination on the instance. It then runs the script on the instance.
-// The command output and an error are returned.
+// The command output, whether or not the script succeeds, and an error are
+// returned.
 func RunRemoteWithOutput(c Command) ([]byte, error) {
 	return runCommandWithOutput(c, runRemote)
 }
@@ -196,6 +199,13 @@ func CopyTo(c Command) bool {
 	return runCommand(c, CopyToWithOutput)
 }
 
+// ExitStatus returns the exit status of a remote or sudo command that exited
+// with a non-zero status. The boolean is false if err wasn't caused by the
+// command's exit status.
+func ExitStatus(err error) (int, bool) {
+	return ssh.ExitStatus(err)
+}
+
 //---------------------------------------------------------------------------
 
 func runCommands(c []Command, i resource.Instance, r bool) bool {
@@ -302,11 +312,21 @@ func runCommand(c Command, f commandFunc) bool {
 
 //---------------------------------------------------------------------------
 
+// span starts a timing span for running the command.
+func (c Command) span(kind string) *trace.Span {
+	name := kind + " command: " + c.Desc
+	if c.Instance != nil {
+		name += " on " + c.Instance.Name()
+	}
+	return trace.Start("command", name)
+}
+
 func (c Command) arcSrc() bool {
 	return strings.HasPrefix(c.Src, "/usr/lib/arc")
 }
 
 func runLocal(c Command) ([]byte, error) {
+	defer c.span("Local").End()
 	msg.Info("Local command: %s", c.Desc)
 	output, err := local(c)
 	if err != nil {
@@ -316,6 +336,7 @@ func runLocal(c Command) ([]byte, error) {
 }
 
 func runRemote(c Command, cl *client) ([]byte, error) {
+	defer c.span("Remote").End()
 	msg.Info("Remote command: %s on %s", c.Desc, c.Instance.Name())
 	output, err := copyto(c, cl)
 	if err != nil {
@@ -325,14 +346,11 @@ func runRemote(c Command, cl *client) ([]byte, error) {
 	if d.Dest != "" {
 		d.Src = d.Dest
 	}
-	output, err = sudo(d, cl)
-	if err != nil {
-		return output, err
-	}
-	return nil, nil
+	return sudo(d, cl)
 }
 
 func runSudo(c Command, cl *client) ([]byte, error) {
+	defer c.span("Sudo").End()
 	msg.Info("Sudo command: %s on %s", c.Desc, c.Instance.Name())
 	output, err := sudo(c, cl)
 	if err != nil {
@@ -342,6 +360,7 @@ func runSudo(c Command, cl *client) ([]byte, error) {
 }
 
 func copyTo(c Command, cl *client) ([]byte, error) {
+	defer c.span("Copy").End()
 	msg.Info("Copy command: %s to %s", c.Desc, c.Instance.Name())
 	output, err := copyto(c, cl)
 	if err != nil {
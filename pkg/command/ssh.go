@@ -36,6 +36,7 @@ import (
 	"github.com/cisco/arc/pkg/msg"
 	"github.com/cisco/arc/pkg/resource"
 	"github.com/cisco/arc/pkg/ssh"
+	"github.com/cisco/arc/pkg/trace"
 )
 
 type client struct {
@@ -43,6 +44,7 @@ type client struct {
 }
 
 func newClient(i resource.Instance, asRoot bool) (*client, error) {
+	defer trace.Start("ssh", "connect to "+i.Name()).End()
 
 	// Create the underlying ssh client.
 	cl, err := ssh.NewClient()
@@ -0,0 +1,158 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+// Package compliance provides the declarative checks used to verify that an
+// instance is hardened, and the report of running them.
+package compliance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The check categories. The ssh and cron categories correspond to the
+// scripts in /usr/lib/arc/hardening which can be re-applied to fix them.
+const (
+	Ssh     = "ssh"
+	Cron    = "cron"
+	Sudoers = "sudoers"
+	Aide    = "aide"
+)
+
+// Check is a single compliance check. Test is a shell test run as root on the
+// instance that succeeds when the instance is compliant.
+type Check struct {
+	Category string
+	Name     string
+	Test     string
+}
+
+const (
+	sshd       = "/usr/sbin/sshd"
+	sshdConfig = "/etc/ssh/sshd_config"
+)
+
+// sshdOptions are the sshd_config options set by 52_ssh_hardening. Protocol
+// isn't checked since current versions of sshd only support protocol 2 and no
+// longer report it.
+var sshdOptions = map[string]string{
+	"LogLevel":                "INFO",
+	"X11Forwarding":           "no",
+	"MaxAuthTries":            "4",
+	"IgnoreRhosts":            "yes",
+	"HostbasedAuthentication": "no",
+	"PermitRootLogin":         "no",
+	"PermitEmptyPasswords":    "no",
+	"PermitUserEnvironment":   "no",
+	"ClientAliveInterval":     "21600",
+	"ClientAliveCountMax":     "0",
+	"MACs":                    "hmac-sha2-512-etm@openssh.com,hmac-sha2-256-etm@openssh.com,hmac-sha2-512,hmac-sha2-256",
+	"Ciphers":                 "chacha20-poly1305@openssh.com,aes256-gcm@openssh.com,aes128-gcm@openssh.com,aes256-ctr,aes192-ctr,aes128-ctr",
+}
+
+// cronModes are the file modes set by 51_cron_hardening.
+var cronModes = map[string]string{
+	"/etc/crontab":      "600",
+	"/etc/cron.hourly":  "600",
+	"/etc/cron.daily":   "600",
+	"/etc/cron.weekly":  "600",
+	"/etc/cron.monthly": "600",
+	"/etc/cron.d":       "600",
+}
+
+// cronAbsent are the files removed by 51_cron_hardening.
+var cronAbsent = []string{
+	"/etc/cron.deny",
+	"/etc/at.deny",
+	"/etc/anacron.deny",
+}
+
+// Checks returns the compliance checks in a stable order.
+func Checks() []Check {
+	checks := []Check{
+		{Ssh, "sshd_config owned by root with mode 600", fmt.Sprintf("[ \"$(stat -c %%U:%%a %s)\" = root:600 ]", sshdConfig)},
+	}
+	// The options are tested against the effective configuration reported by
+	// "sshd -T", which accounts for defaults, commented out options, includes
+	// and sshd using the first value given for an option.
+	for _, k := range sortedKeys(sshdOptions) {
+		checks = append(checks, Check{
+			Category: Ssh,
+			Name:     fmt.Sprintf("sshd %s %s", k, sshdOptions[k]),
+			Test: fmt.Sprintf("[ \"$(%s -T | awk '$1 == \"%s\" { print tolower($2) }')\" = '%s' ]",
+				sshd, strings.ToLower(k), strings.ToLower(sshdOptions[k])),
+		})
+	}
+	for _, k := range sortedKeys(cronModes) {
+		checks = append(checks, Check{
+			Category: Cron,
+			Name:     fmt.Sprintf("%s mode %s", k, cronModes[k]),
+			Test:     fmt.Sprintf("[ \"$(stat -c %%a %s)\" = %s ]", k, cronModes[k]),
+		})
+	}
+	for _, f := range cronAbsent {
+		checks = append(checks, Check{
+			Category: Cron,
+			Name:     fmt.Sprintf("%s absent", f),
+			Test:     fmt.Sprintf("[ ! -e %s ]", f),
+		})
+	}
+	checks = append(checks,
+		Check{Sudoers, "sudoers syntax valid", "visudo -c -q"},
+		Check{Sudoers, "sudoers.d files owned by root with mode 440",
+			"[ -z \"$(find /etc/sudoers.d -type f ! \\( -user root -perm 0440 \\))\" ]"},
+		Check{Sudoers, "sudoers does not disable authentication",
+			"! grep -rqs '!authenticate' /etc/sudoers /etc/sudoers.d"},
+		Check{Aide, "aide database present",
+			"[ -s /var/lib/aide/aide.db.gz ] || [ -s /var/lib/aide/aide.db ]"},
+	)
+	return checks
+}
+
+// marker prefixes each result line printed by the check script.
+const marker = "arc-compliance"
+
+// Script returns a bash script which runs the given checks and prints a
+// result line for each.
+func Script(checks []Check) string {
+	s := "#!/bin/bash\n"
+	for n, c := range checks {
+		s += fmt.Sprintf("# %s\n", c.Name)
+		s += fmt.Sprintf("if ( %s ) >/dev/null 2>&1; then echo '%s %d pass'; else echo '%s %d fail'; fi\n",
+			c.Test, marker, n, marker, n)
+	}
+	return s
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,116 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package compliance
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/log"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "compliance")
+	if err != nil {
+		panic(err)
+	}
+	env.Set("COMPLIANCE", dir)
+	if err := log.Init("compliance"); err != nil {
+		panic(err)
+	}
+	result := m.Run()
+	log.Fini()
+	os.RemoveAll(dir)
+	os.Exit(result)
+}
+
+func TestChecks(t *testing.T) {
+	checks := Checks()
+	names := map[string]bool{}
+	for _, c := range checks {
+		if names[c.Name] {
+			t.Errorf("Duplicate check %q", c.Name)
+		}
+		names[c.Name] = true
+		switch c.Category {
+		case Ssh, Cron, Sudoers, Aide:
+		default:
+			t.Errorf("Unknown category %q of %q", c.Category, c.Name)
+		}
+	}
+	if names["sshd Protocol 2"] {
+		t.Error("Unexpected Protocol check")
+	}
+	for _, c := range checks {
+		if c.Name == "sshd PermitRootLogin no" {
+			if !strings.Contains(c.Test, "/usr/sbin/sshd -T") || !strings.Contains(c.Test, `"permitrootlogin"`) {
+				t.Errorf("Expected the effective sshd configuration to be tested, got %s", c.Test)
+			}
+			return
+		}
+	}
+	t.Error("Missing the PermitRootLogin check")
+}
+
+func TestScript(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash isn't available")
+	}
+	dir, err := ioutil.TempDir("", "script")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	present := filepath.Join(dir, "present")
+	if err := ioutil.WriteFile(present, []byte("PermitRootLogin no\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []Check{
+		{Ssh, "file present", "[ -e " + present + " ]"},
+		{Ssh, "file absent", "[ ! -e " + present + " ]"},
+		{Cron, "quoted test", "[ \"$(awk '{ print $2 }' " + present + ")\" = 'no' ]"},
+		{Cron, "noisy failure", "echo arc-compliance 3 pass; exit 1"},
+	}
+	out, err := exec.Command("bash", "-c", Script(checks)).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Parse("web-01", checks, out)
+	passed := []bool{}
+	for _, res := range r.Results {
+		passed = append(passed, res.Passed)
+	}
+	if len(passed) != 4 || !passed[0] || passed[1] || !passed[2] || passed[3] {
+		t.Errorf("Unexpected results %v from\n%s", passed, out)
+	}
+}
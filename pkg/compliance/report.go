@@ -0,0 +1,131 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package compliance
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/msg"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Check
+	Passed bool
+}
+
+// Report is the outcome of running the checks on an instance.
+type Report struct {
+	Instance string
+	Results  []Result
+}
+
+// Parse builds the report from the output of the script returned by Script.
+// Checks without a result line are reported as failed.
+func Parse(instance string, checks []Check, output []byte) *Report {
+	passed := map[int]bool{}
+	for _, line := range strings.Split(string(output), "\n") {
+		f := strings.Fields(line)
+		if len(f) != 3 || f[0] != marker {
+			continue
+		}
+		n, err := strconv.Atoi(f[1])
+		if err != nil {
+			continue
+		}
+		passed[n] = f[2] == "pass"
+	}
+	r := &Report{Instance: instance}
+	for n, c := range checks {
+		r.Results = append(r.Results, Result{Check: c, Passed: passed[n]})
+	}
+	return r
+}
+
+// Failed returns the failed results.
+func (r *Report) Failed() []Result {
+	failed := []Result{}
+	for _, res := range r.Results {
+		if !res.Passed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// FailedCategories returns the categories with at least one failed check,
+// in check order.
+func (r *Report) FailedCategories() []string {
+	categories := []string{}
+	seen := map[string]bool{}
+	for _, res := range r.Failed() {
+		if !seen[res.Category] {
+			seen[res.Category] = true
+			categories = append(categories, res.Category)
+		}
+	}
+	return categories
+}
+
+// Print provides a user friendly view of the report.
+func (r *Report) Print() {
+	failed := len(r.Failed())
+	msg.Info("Compliance Report: %s, %d passed, %d failed", r.Instance, len(r.Results)-failed, failed)
+	for _, res := range r.Results {
+		status := "pass"
+		if !res.Passed {
+			status = "FAIL"
+		}
+		msg.Detail("%-4s  %-8s  %s", status, res.Category, res.Name)
+	}
+}
+
+// auditName is the name of the aaa audit compliance failures are reported to.
+const auditName = "Compliance"
+
+// Audit reports the failed checks through the aaa audit path, which sends
+// them to the audit spark room at the end of the run.
+func (r *Report) Audit() error {
+	if aaa.AuditBuffer[auditName] == nil {
+		if err := aaa.NewAuditWithOptions(auditName, false, false, true); err != nil {
+			return err
+		}
+	}
+	a := aaa.AuditBuffer[auditName]
+	if a == nil {
+		return nil
+	}
+	for _, res := range r.Failed() {
+		a.Record(aaa.Mismatched, &aaa.Finding{
+			ResourceType: "instance", Name: r.Instance, Attribute: res.Name, Expected: "pass", Actual: "fail",
+			Message: r.Instance + ": " + res.Name,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package compliance
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cisco/arc/pkg/aaa"
+)
+
+var testChecks = []Check{
+	{Ssh, "sshd PermitRootLogin no", "true"},
+	{Cron, "/etc/crontab mode 600", "true"},
+	{Sudoers, "sudoers syntax valid", "true"},
+	{Ssh, "sshd X11Forwarding no", "true"},
+}
+
+func TestParse(t *testing.T) {
+	output := []byte(`Warning: Permanently added 'web-01' to the list of known hosts.
+arc-compliance 0 fail
+arc-compliance 1 pass
+arc-compliance x pass
+not arc-compliance 2 pass
+arc-compliance 3 fail
+`)
+	r := Parse("web-01", testChecks, output)
+	if r.Instance != "web-01" || len(r.Results) != len(testChecks) {
+		t.Fatalf("Unexpected report %+v", r)
+	}
+	failed := []string{}
+	for _, res := range r.Failed() {
+		failed = append(failed, res.Name)
+	}
+	// The sudoers check has no result line, so it is reported as failed.
+	expected := []string{"sshd PermitRootLogin no", "sudoers syntax valid", "sshd X11Forwarding no"}
+	if !reflect.DeepEqual(failed, expected) {
+		t.Errorf("Expected %v to fail, got %v", expected, failed)
+	}
+	if c := r.FailedCategories(); !reflect.DeepEqual(c, []string{Ssh, Sudoers}) {
+		t.Errorf("Expected the ssh and sudoers categories, got %v", c)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	r := Parse("web-01", testChecks, []byte("arc-compliance 0 pass\narc-compliance 1 pass\narc-compliance 2 pass\narc-compliance 3 fail\n"))
+	if err := r.Audit(); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(aaa.AuditBuffer, auditName)
+
+	findings := aaa.AuditBuffer[auditName].Findings()
+	if len(findings) != 1 {
+		t.Fatalf("Expected a single finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.Name != "web-01" || f.Attribute != "sshd X11Forwarding no" || f.Actual != "fail" || f.Message != "web-01: sshd X11Forwarding no" {
+		t.Errorf("Unexpected finding %+v", f)
+	}
+}
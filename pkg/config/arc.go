@@ -42,6 +42,7 @@ type Arc struct {
 	Title_           string            `json:"title"`
 	Provider         *Provider         `json:"provider"`
 	Notifications    *Notifications    `json:"notifications"`
+	Mirror           *Mirror           `json:"mirror"`
 	DataCenter       *DataCenter       `json:"datacenter"`
 	DatabaseService  *DatabaseService  `json:"database_service"`
 	ContainerService *ContainerService `json:"container_service"`
@@ -87,6 +88,12 @@ func (a *Arc) Print() {
 	if a.Provider != nil {
 		a.Provider.Print()
 	}
+	if a.Notifications != nil {
+		a.Notifications.Print()
+	}
+	if a.Mirror != nil {
+		a.Mirror.Print()
+	}
 	if a.DataCenter != nil {
 		a.DataCenter.Print()
 	}
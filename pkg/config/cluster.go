@@ -48,6 +48,7 @@ type Cluster struct {
 	Pods          *Pods        `json:"pods"`
 	SecurityTags_ SecurityTags `json:"security_tags"`
 	AuditIgnore_  bool         `json:"audit_ignore"`
+	PatchBatch_   int          `json:"patch_batch"`
 }
 
 // Name satisfies the resource.StaticCluster interface.
@@ -63,10 +64,20 @@ func (c *Cluster) AuditIgnore() bool {
 	return c.AuditIgnore_
 }
 
+// PatchBatch satisfies the resource.StaticCluster interface. It is the number
+// of instances in each pod patched at the same time, defaulting to one.
+func (c *Cluster) PatchBatch() int {
+	if c.PatchBatch_ < 1 {
+		return 1
+	}
+	return c.PatchBatch_
+}
+
 // Print provides a user friendly way to view the cluster configuration.
 func (c *Cluster) Print() {
 	msg.Info("Cluster Config")
 	msg.Detail("%-20s\t%s", "name", c.Name())
+	msg.Detail("%-20s\t%d", "patch_batch", c.PatchBatch())
 	msg.IndentInc()
 	if c.Pods != nil {
 		c.Pods.Print()
@@ -35,12 +35,26 @@ type DataCenter struct {
 	Network       *Network     `json:"network"`
 	Compute       *Compute     `json:"compute"`
 	SecurityTags_ SecurityTags `json:"security_tags"`
+	Monitoring_   Monitoring   `json:"monitoring"`
+	SshCa_        bool         `json:"ssh_ca"`
 }
 
 func (d *DataCenter) SecurityTags() SecurityTags {
 	return d.SecurityTags_
 }
 
+// Monitoring returns the monitoring integrations used by the datacenter's
+// instances, unless a pod provides its own.
+func (d *DataCenter) Monitoring() Monitoring {
+	return d.Monitoring_
+}
+
+// SshCa returns true if users reach the datacenter's instances with
+// certificates signed by the datacenter's ssh certificate authority.
+func (d *DataCenter) SshCa() bool {
+	return d.SshCa_
+}
+
 // Print provides a user friendly way to view the entire datacenter configuration.
 // This is a deep print.
 func (d *DataCenter) Print() {
@@ -52,6 +66,12 @@ func (d *DataCenter) Print() {
 	if d.SecurityTags_ != nil {
 		d.SecurityTags_.Print()
 	}
+	if d.SshCa_ {
+		msg.Detail("%-20s\t%t", "ssh ca", d.SshCa_)
+	}
+	if d.Monitoring_ != nil {
+		d.Monitoring_.Print()
+	}
 	if d.Network != nil {
 		d.Network.Print()
 	}
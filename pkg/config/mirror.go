@@ -0,0 +1,56 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package config
+
+import "github.com/cisco/arc/pkg/msg"
+
+// The configuration of the mirror object. The url is the root of the
+// package mirror and may use the http, https or file scheme. An http mirror
+// only protects against corrupted downloads, so it should only be used on a
+// trusted network. The cache is the local directory where packages pulled
+// from the mirror are kept across runs.
+type Mirror struct {
+	Url_   string `json:"url"`
+	Cache_ string `json:"cache"`
+}
+
+// Url returns the root url of the package mirror.
+func (m *Mirror) Url() string {
+	return m.Url_
+}
+
+// Cache returns the local directory used to cache mirrored packages.
+func (m *Mirror) Cache() string {
+	return m.Cache_
+}
+
+// Print provides a user friendly way to view the mirror configuration.
+func (m *Mirror) Print() {
+	msg.Info("Mirror Config")
+	msg.Detail("%-20s\t%s", "url", m.Url())
+	msg.Detail("%-20s\t%s", "cache", m.Cache())
+}
@@ -0,0 +1,100 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package config
+
+import "github.com/cisco/arc/pkg/msg"
+
+// Monitoring is the list of monitoring integrations used to enable and
+// disable paging for instances. It can be set for a datacenter and
+// overridden by a pod.
+type Monitoring []*Monitor
+
+// Print provides a user friendly way to view the monitoring configuration.
+func (m Monitoring) Print() {
+	msg.Info("Monitoring Config")
+	msg.IndentInc()
+	for _, monitor := range m {
+		monitor.Print()
+	}
+	msg.IndentDec()
+}
+
+// The configuration of a monitoring integration. The type is one of consul,
+// sensu, alertmanager or webhook. The url is the endpoint of the alertmanager
+// or webhook integrations and is unused by consul and sensu which are driven
+// from the instance. The label is the alertmanager label matched against the
+// instance name, and the headers are added to each http request. If required
+// is set a failure to enable or disable paging fails the operation, otherwise
+// it is reported as a warning.
+type Monitor struct {
+	Type_     string            `json:"type"`
+	Url_      string            `json:"url"`
+	Label_    string            `json:"label"`
+	Headers_  map[string]string `json:"headers"`
+	Required_ bool              `json:"required"`
+}
+
+// Type returns the kind of monitoring integration.
+func (m *Monitor) Type() string {
+	return m.Type_
+}
+
+// Url returns the endpoint of the monitoring integration.
+func (m *Monitor) Url() string {
+	return m.Url_
+}
+
+// Label returns the alert label matched against the instance name.
+func (m *Monitor) Label() string {
+	return m.Label_
+}
+
+// Headers returns the http headers added to each request.
+func (m *Monitor) Headers() map[string]string {
+	return m.Headers_
+}
+
+// Required returns true if a failure of this integration should fail the operation.
+func (m *Monitor) Required() bool {
+	return m.Required_
+}
+
+// Print provides a user friendly way to view a monitor configuration.
+func (m *Monitor) Print() {
+	msg.Info("Monitor Config")
+	msg.Detail("%-20s\t%s", "type", m.Type())
+	if m.Url() != "" {
+		msg.Detail("%-20s\t%s", "url", m.Url())
+	}
+	if m.Label() != "" {
+		msg.Detail("%-20s\t%s", "label", m.Label())
+	}
+	for k := range m.Headers() {
+		msg.Detail("%-20s\t%s", "header", k)
+	}
+	msg.Detail("%-20s\t%t", "required", m.Required())
+}
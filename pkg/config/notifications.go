@@ -26,10 +26,173 @@
 
 package config
 
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/cisco/arc/pkg/msg"
+)
+
+// The notification configuration. Messages are sent to the listed sinks. The
+// spark rooms are kept for existing configurations, each room receives the
+// messages of the type it is keyed by.
 type Notifications struct {
-	Spark *Spark `json:"spark"`
+	Spark  *Spark  `json:"spark"`
+	Sinks_ []*Sink `json:"sinks"`
+}
+
+// NewNotifications reads the notification configuration from the named file.
+func NewNotifications(name string) (*Notifications, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	n := &Notifications{}
+	if err := json.Unmarshal(data, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Sinks returns the configured notification sinks.
+func (n *Notifications) Sinks() []*Sink {
+	return n.Sinks_
+}
+
+// Print provides a user friendly way to view the notification configuration.
+func (n *Notifications) Print() {
+	msg.Info("Notifications Config")
+	msg.IndentInc()
+	if n.Spark != nil {
+		for k, v := range n.Spark.Rooms {
+			msg.Detail("%-20s\t%s", "spark "+k, v)
+		}
+	}
+	for _, s := range n.Sinks() {
+		s.Print()
+	}
+	msg.IndentDec()
 }
 
 type Spark struct {
 	Rooms map[string]string `json:"rooms"`
 }
+
+// The configuration of a notification sink. The type is one of spark, slack,
+// webhook, smtp or file. Messages lists the message types routed to the sink,
+// accounting, audit and warning, an empty list routes every type. The url is
+// the slack incoming webhook or generic webhook endpoint, the room the spark
+// room, and the server, from, to, username and password configure smtp email.
+// Warnings addressed to a user are also emailed to the user at the user domain
+// if it is set. The path is the file messages are appended to. Header values,
+// the url, the smtp password and the path are expanded from the environment.
+type Sink struct {
+	Type_       string            `json:"type"`
+	Messages_   []string          `json:"messages"`
+	Url_        string            `json:"url"`
+	Headers_    map[string]string `json:"headers"`
+	Room_       string            `json:"room"`
+	Server_     string            `json:"server"`
+	From_       string            `json:"from"`
+	To_         []string          `json:"to"`
+	Username_   string            `json:"username"`
+	Password_   string            `json:"password"`
+	Path_       string            `json:"path"`
+	UserDomain_ string            `json:"user_domain"`
+}
+
+// Type returns the kind of notification sink.
+func (s *Sink) Type() string {
+	return s.Type_
+}
+
+// Messages returns the message types routed to the sink.
+func (s *Sink) Messages() []string {
+	return s.Messages_
+}
+
+// Url returns the endpoint of the slack or webhook sink.
+func (s *Sink) Url() string {
+	return s.Url_
+}
+
+// Headers returns the http headers added to each webhook request.
+func (s *Sink) Headers() map[string]string {
+	return s.Headers_
+}
+
+// Room returns the spark room messages are sent to.
+func (s *Sink) Room() string {
+	return s.Room_
+}
+
+// Server returns the smtp server address in the form host:port.
+func (s *Sink) Server() string {
+	return s.Server_
+}
+
+// From returns the sender of the smtp email.
+func (s *Sink) From() string {
+	return s.From_
+}
+
+// To returns the recipients of the smtp email.
+func (s *Sink) To() []string {
+	return s.To_
+}
+
+// Username returns the smtp user name, if the server requires authentication.
+func (s *Sink) Username() string {
+	return s.Username_
+}
+
+// Password returns the smtp password.
+func (s *Sink) Password() string {
+	return s.Password_
+}
+
+// Path returns the name of the file messages are appended to.
+func (s *Sink) Path() string {
+	return s.Path_
+}
+
+// UserDomain returns the email domain of the users warnings are addressed to.
+func (s *Sink) UserDomain() string {
+	return s.UserDomain_
+}
+
+// Print provides a user friendly way to view a sink configuration.
+func (s *Sink) Print() {
+	msg.Info("Sink Config")
+	msg.Detail("%-20s\t%s", "type", s.Type())
+	for _, m := range s.Messages() {
+		msg.Detail("%-20s\t%s", "messages", m)
+	}
+	if s.Url() != "" {
+		msg.Detail("%-20s\t%s", "url", s.Url())
+	}
+	for k := range s.Headers() {
+		msg.Detail("%-20s\t%s", "header", k)
+	}
+	if s.Room() != "" {
+		msg.Detail("%-20s\t%s", "room", s.Room())
+	}
+	if s.Server() != "" {
+		msg.Detail("%-20s\t%s", "server", s.Server())
+	}
+	if s.From() != "" {
+		msg.Detail("%-20s\t%s", "from", s.From())
+	}
+	for _, t := range s.To() {
+		msg.Detail("%-20s\t%s", "to", t)
+	}
+	if s.Username() != "" {
+		msg.Detail("%-20s\t%s", "username", s.Username())
+	}
+	if s.Path() != "" {
+		msg.Detail("%-20s\t%s", "path", s.Path())
+	}
+	if s.UserDomain() != "" {
+		msg.Detail("%-20s\t%s", "user domain", s.UserDomain())
+	}
+}
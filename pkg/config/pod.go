@@ -52,17 +52,18 @@ func (p *Pods) Print() {
 // subnet group, the associated security groups, the count being the number of instances
 // created, and the list of volume templates to use for each instance.
 type Pod struct {
-	Name_           string   `json:"pod"`
-	ServerType_     string   `json:"servertype"`
-	Version_        int      `json:"version"`
-	Image_          string   `json:"image"`
-	InstanceType_   string   `json:"type"`
-	Role_           string   `json:"role"`
-	SubnetGroup_    string   `json:"subnet_group"`
-	SecurityGroups_ []string `json:"security_groups"`
-	Count_          int      `json:"count"`
-	Teams_          []string `json:"teams"`
-	Volumes         *Volumes `json:"volumes"`
+	Name_           string     `json:"pod"`
+	ServerType_     string     `json:"servertype"`
+	Version_        int        `json:"version"`
+	Image_          string     `json:"image"`
+	InstanceType_   string     `json:"type"`
+	Role_           string     `json:"role"`
+	SubnetGroup_    string     `json:"subnet_group"`
+	SecurityGroups_ []string   `json:"security_groups"`
+	Count_          int        `json:"count"`
+	Teams_          []string   `json:"teams"`
+	Monitoring_     Monitoring `json:"monitoring"`
+	Volumes         *Volumes   `json:"volumes"`
 	Instances       *Instances
 }
 
@@ -141,6 +142,12 @@ func (p *Pod) Teams() []string {
 	return p.Teams_
 }
 
+// Monitoring satisfies the resource.StaticPod interface. When set it overrides the
+// monitoring integrations configured for the datacenter.
+func (p *Pod) Monitoring() Monitoring {
+	return p.Monitoring_
+}
+
 // PrintLocal provides a user friendly way to view the configuration local to the pod object.
 func (p *Pod) PrintLocal() {
 	msg.Detail("%-20s\t%s", "name", p.Name())
@@ -169,6 +176,9 @@ func (p *Pod) Print() {
 	msg.Detail("%-20s\t%s", "teams", teams)
 	msg.Detail("%-20s\t%d", "count", p.Count())
 	msg.IndentInc()
+	if p.Monitoring_ != nil {
+		p.Monitoring_.Print()
+	}
 	if p.Volumes != nil {
 		p.Volumes.Print()
 	}
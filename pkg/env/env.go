@@ -108,7 +108,7 @@ func Init(appname, version string) error {
 	env[strings.ToUpper(appname)] = appDir
 	env["ROOT"] = root
 	env["VERSION"] = version
-	env["SPARK_TOKEN"] = initSpark()
+	env["SPARK_TOKEN"] = SparkToken(root)
 	env["USER"] = u.Username
 	env["SSH_USER"] = u.Username
 	if sshUser := os.Getenv("SSH_USER"); sshUser != "" {
@@ -154,12 +154,14 @@ func cleanup(dir string) error {
 	return nil
 }
 
-func initSpark() string {
+// SparkToken returns the spark token from the SPARK_TOKEN environment variable,
+// or decrypted from etc/arc/spark.gpg under the given repo root.
+func SparkToken(root string) string {
 	if token := os.Getenv("SPARK_TOKEN"); token != "" {
 		return token
 	}
-	spark := env["ROOT"] + "/etc/arc/spark.gpg"
-	cmd := env["ROOT"] + "/usr/local/bin/decrypt_file"
+	spark := root + "/etc/arc/spark.gpg"
+	cmd := root + "/usr/local/bin/decrypt_file"
 	token, err := exec.Command(cmd, spark).CombinedOutput()
 	if err != nil {
 		return ""
@@ -28,41 +28,138 @@ package log
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/syslog"
 	"os"
 	"path"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cisco/arc/pkg/env"
 	"github.com/cisco/arc/pkg/route"
+	"github.com/cisco/arc/pkg/trace"
 )
 
+// Level is the severity of a log entry. Entries below the configured level are dropped.
+type Level int
+
+const (
+	LevelVerbose Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"verbose", "debug", "info", "warn", "error"}
+var levelPrefixes = []string{"Verbose| ", "Debug  | ", "Info   | ", "Warn   | ", "Error  | "}
+
+func (lv Level) String() string {
+	return levelNames[lv]
+}
+
+// ParseLevel returns the level with the given name.
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.ToLower(s) == name {
+			return Level(i), nil
+		}
+	}
+	return LevelVerbose, fmt.Errorf("Unknown log level %q, expected one of %s", s, strings.Join(levelNames, ", "))
+}
+
 type logger struct {
-	lock          *sync.Mutex
-	file          *os.File
-	writer        *bufio.Writer
-	enableVerbose bool
-	verbose       *log.Logger
-	enableDebug   bool
-	debug         *log.Logger
-	enableInfo    bool
-	info          *log.Logger
-	enableWarn    bool
-	warn          *log.Logger
-	enableError   bool
-	err           *log.Logger
-}
-
-var l *logger
+	lock    *sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	enabled [LevelError + 1]bool
+	json    bool
+	syslog  *syslog.Writer
+
+	// fallback is set for the logger used before Init and after Fini.
+	fallback bool
+
+	appname    string
+	user       string
+	datacenter string
+}
+
+// The fallback logger writes warnings and errors to stderr so logging before
+// Init, or after Fini, is safe.
+var fallback = newFallback()
+
+var l = fallback
+
+func newFallback() *logger {
+	lg := &logger{
+		lock:     &sync.Mutex{},
+		writer:   bufio.NewWriter(os.Stderr),
+		fallback: true,
+	}
+	lg.setLevel(LevelWarn)
+	return lg
+}
+
+// The settings given by ParseFlags, which take precedence over the environment.
+var flags struct {
+	level  string
+	format string
+	syslog bool
+}
+
+// ParseFlags removes the logging flags from args, returning the remaining args.
+// The flags are applied by Init and are:
+//
+//	--log-level=verbose|debug|info|warn|error
+//	--log-format=text|json
+//	--log-syslog
+//
+// The environment variables log_level, log_format and log_syslog=yes can be used
+// instead. For compatibility debug=no and verbose=no still disable those levels.
+func ParseFlags(args []string) []string {
+	remaining := []string{}
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--log-level="):
+			flags.level = strings.TrimPrefix(arg, "--log-level=")
+		case strings.HasPrefix(arg, "--log-format="):
+			flags.format = strings.TrimPrefix(arg, "--log-format=")
+		case arg == "--log-syslog":
+			flags.syslog = true
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+	return remaining
+}
+
+func setting(flag, name string) string {
+	if flag != "" {
+		return flag
+	}
+	return os.Getenv(name)
+}
 
 func Init(appname string) error {
-	if l != nil {
+	if !l.fallback {
 		return nil
 	}
-	fileName := fmt.Sprintf(env.Lookup(strings.ToUpper(appname)) + "/" + appname + ".log")
+	level := LevelVerbose
+	if s := setting(flags.level, "log_level"); s != "" {
+		var err error
+		if level, err = ParseLevel(s); err != nil {
+			return err
+		}
+	}
+	format := setting(flags.format, "log_format")
+	if format != "" && format != "text" && format != "json" {
+		return fmt.Errorf("Unknown log format %q, expected text or json", format)
+	}
+
+	fileName := env.Lookup(strings.ToUpper(appname)) + "/" + appname + ".log"
 	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
@@ -70,90 +167,186 @@ func Init(appname string) error {
 	if err := os.Chmod(fileName, 0644); err != nil {
 		return err
 	}
-	writer := bufio.NewWriter(file)
-	l = &logger{
-		lock:          &sync.Mutex{},
-		file:          file,
-		writer:        writer,
-		enableVerbose: true,
-		verbose:       log.New(writer, "Verbose| ", log.Ldate|log.Ltime),
-		enableDebug:   true,
-		debug:         log.New(writer, "Debug  | ", log.Ldate|log.Ltime),
-		enableInfo:    true,
-		info:          log.New(writer, "Info   | ", log.Ldate|log.Ltime),
-		enableWarn:    true,
-		warn:          log.New(writer, "Warn   | ", log.Ldate|log.Ltime),
-		enableError:   true,
-		err:           log.New(writer, "Error  | ", log.Ldate|log.Ltime),
+	lg := &logger{
+		lock:    &sync.Mutex{},
+		file:    file,
+		writer:  bufio.NewWriter(file),
+		json:    format == "json",
+		appname: appname,
+		user:    env.Lookup("SSH_USER"),
 	}
+	lg.setLevel(level)
 	if os.Getenv("debug") == "no" {
-		l.enableDebug = false
+		lg.enabled[LevelDebug] = false
 	}
 	if os.Getenv("verbose") == "no" {
-		l.enableVerbose = false
+		lg.enabled[LevelVerbose] = false
 	}
+	var syslogErr error
+	if flags.syslog || os.Getenv("log_syslog") == "yes" {
+		// journald collects the syslog socket so this also covers journald.
+		lg.syslog, syslogErr = syslog.New(syslog.LOG_INFO|syslog.LOG_USER, appname)
+	}
+	l = lg
 	Info("%s %s", appname, env.Lookup("VERSION"))
+	if syslogErr != nil {
+		Warn("Unable to log to syslog: %s", syslogErr.Error())
+	}
 	return nil
 }
 
 func Fini() {
-	if l == nil {
+	if l.fallback {
 		return
 	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.writer.Flush()
 	l.file.Close()
-	l = nil
+	if l.syslog != nil {
+		l.syslog.Close()
+	}
+	l = fallback
 }
 
-func Verbose(format string, a ...interface{}) {
-	if l.enableVerbose == true {
-		logMsg(l.verbose, format, a...)
+// SetLevel changes the level below which entries are dropped.
+func SetLevel(level Level) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.setLevel(level)
+}
+
+func (lg *logger) setLevel(level Level) {
+	for i := range lg.enabled {
+		lg.enabled[i] = Level(i) >= level
 	}
 }
 
+// SetDataCenter sets the datacenter included in json log entries.
+func SetDataCenter(name string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.datacenter = name
+}
+
+func Verbose(format string, a ...interface{}) {
+	logMsg(LevelVerbose, nil, format, a...)
+}
+
 func Debug(format string, a ...interface{}) {
-	if l.enableDebug == true {
-		logMsg(l.debug, format, a...)
-	}
+	logMsg(LevelDebug, nil, format, a...)
 }
 
 func Info(format string, a ...interface{}) {
-	if l.enableInfo == true {
-		logMsg(l.info, format, a...)
-	}
+	logMsg(LevelInfo, nil, format, a...)
 }
 
 func Warn(format string, a ...interface{}) {
-	if l.enableWarn == true {
-		logMsg(l.warn, format, a...)
-	}
+	logMsg(LevelWarn, nil, format, a...)
 }
 
 func Error(format string, a ...interface{}) {
-	if l.enableError == true {
-		logMsg(l.err, format, a...)
+	logMsg(LevelError, nil, format, a...)
+}
+
+// Route logs the routing of the request and starts a timing span for it,
+// which the caller ends once the request has been handled.
+func Route(req *route.Request, format string, a ...interface{}) *trace.Span {
+	name := fmt.Sprintf(format, a...)
+	logMsg(LevelDebug, req, "%s routing request: %q", name, req)
+	return trace.Start("route", fmt.Sprintf("%s %s", name, req.Command()))
+}
+
+// Timing ends the timing spans of the run and logs where the time went.
+func Timing() {
+	summary, err := trace.Fini()
+	if summary != "" {
+		Info("Timing:\n%s", summary)
+	}
+	if err != nil {
+		Warn("Unable to write the trace: %s", err.Error())
 	}
 }
 
-func logMsg(lg *log.Logger, format string, a ...interface{}) {
+type entry struct {
+	Time       string `json:"time"`
+	Level      string `json:"level"`
+	App        string `json:"app,omitempty"`
+	User       string `json:"user,omitempty"`
+	DataCenter string `json:"datacenter,omitempty"`
+	Request    string `json:"request,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Msg        string `json:"msg"`
+}
+
+func logMsg(level Level, req *route.Request, format string, a ...interface{}) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
+	if !l.enabled[level] {
+		return
+	}
+
 	// Add caller's module short name and line number to log message
 	_, fn, line, _ := runtime.Caller(2)
 	fileName := path.Base(fn)
 	if fileName == "msg.go" {
+		// The msg package has already shown the message to the user.
+		if l.fallback {
+			return
+		}
 		_, fn, line, _ = runtime.Caller(3)
 		fileName = path.Base(fn)
 	}
-	args := []interface{}{fileName, line}
-	args = append(args, a...)
-	lg.Println(fmt.Sprintf("| %30s +%-3d | "+format, args...))
-	l.writer.Flush()
+	s := fmt.Sprintf(format, a...)
+
+	var out string
+	if l.json {
+		e := entry{
+			Time:       time.Now().Format(time.RFC3339),
+			Level:      level.String(),
+			App:        l.appname,
+			User:       l.user,
+			DataCenter: l.datacenter,
+			File:       fileName,
+			Line:       line,
+			Msg:        s,
+		}
+		if req != nil {
+			e.User = req.UserId()
+			e.DataCenter = req.DataCenter()
+			e.Request = req.String()
+		}
+		b, _ := json.Marshal(e)
+		out = string(b)
+	} else {
+		out = fmt.Sprintf("| %30s +%-3d | %s", fileName, line, s)
+	}
+	l.write(level, out)
 }
 
-func Route(req *route.Request, format string, a ...interface{}) {
-	name := fmt.Sprintf(format, a...)
-	if l.enableDebug == true {
-		logMsg(l.debug, "%s routing request: %q", name, req)
+func (lg *logger) write(level Level, line string) {
+	if lg.json {
+		fmt.Fprintln(lg.writer, line)
+	} else {
+		fmt.Fprintf(lg.writer, "%s%s %s\n", levelPrefixes[level], time.Now().Format("2006/01/02 15:04:05"), line)
+	}
+	lg.writer.Flush()
+
+	if lg.syslog == nil {
+		return
+	}
+	var w func(string) error
+	switch level {
+	case LevelVerbose, LevelDebug:
+		w = lg.syslog.Debug
+	case LevelInfo:
+		w = lg.syslog.Info
+	case LevelWarn:
+		w = lg.syslog.Warning
+	default:
+		w = lg.syslog.Err
 	}
+	w(line)
 }
@@ -0,0 +1,116 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package log
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/route"
+)
+
+func TestBeforeInit(t *testing.T) {
+	// Logging before Init must not panic.
+	Verbose("verbose")
+	Debug("debug")
+	Info("info")
+	Route(route.NewRequest("dc", "user", "now"), "test")
+}
+
+func TestParseLevel(t *testing.T) {
+	for i, name := range []string{"verbose", "debug", "Info", "WARN", "error"} {
+		level, err := ParseLevel(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if level != Level(i) {
+			t.Errorf("Expected %s to be level %d, got %d", name, i, level)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	defer func() { flags.level, flags.format, flags.syslog = "", "", false }()
+
+	args := ParseFlags([]string{"arc", "--log-level=warn", "dc", "--log-format=json", "--log-syslog", "show"})
+	if !reflect.DeepEqual(args, []string{"arc", "dc", "show"}) {
+		t.Errorf("Unexpected args %v", args)
+	}
+	if flags.level != "warn" || flags.format != "json" || !flags.syslog {
+		t.Errorf("Unexpected flags %+v", flags)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	env.Set("LOGTEST", dir)
+	defer func() { flags.level, flags.format = "", "" }()
+	ParseFlags([]string{"--log-level=debug", "--log-format=json"})
+
+	if err := Init("logtest"); err != nil {
+		t.Fatal(err)
+	}
+	SetDataCenter("dc1")
+	Verbose("dropped")
+	Warn("warning %d", 1)
+	Route(route.NewRequest("dc2", "user2", "now"), "pod")
+	Fini()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "logtest.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 entries, got %d:\n%s", len(lines), data)
+	}
+	entries := make([]entry, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &entries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e := entries[1]; e.Level != "warn" || e.Msg != "warning 1" || e.DataCenter != "dc1" || e.App != "logtest" || e.File != "log_test.go" {
+		t.Errorf("Unexpected entry %+v", e)
+	}
+	if e := entries[2]; e.Level != "debug" || e.DataCenter != "dc2" || e.User != "user2" || e.Request == "" {
+		t.Errorf("Unexpected route entry %+v", e)
+	}
+}
@@ -0,0 +1,226 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+// Package metrics writes Prometheus textfile-collector metrics for a run.
+//
+// When a metrics directory is given the gauges set during the run, along with
+// the run's duration and result, are written at the end of the run to a .prom
+// file in that directory for node_exporter's textfile collector to pick up.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Label is a metric label.
+type Label struct {
+	Name  string
+	Value string
+}
+
+type gauge struct {
+	help    string
+	samples map[string]float64
+}
+
+var lock sync.Mutex
+var appname string
+var dir string
+var start time.Time
+var labels []Label
+var gauges map[string]*gauge
+
+// ParseFlags removes the metrics flag, --metrics-dir=dir, from args returning the
+// remaining args. The environment variable metrics_dir can be used instead.
+func ParseFlags(args []string) []string {
+	remaining := []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--metrics-dir=") {
+			dir = strings.TrimPrefix(arg, "--metrics-dir=")
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return remaining
+}
+
+// Init starts the run. The metrics are named with the appname as a prefix.
+func Init(name string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if dir == "" {
+		dir = os.Getenv("metrics_dir")
+	}
+	appname = name
+	start = time.Now()
+	labels = nil
+	gauges = map[string]*gauge{}
+}
+
+// Enabled returns true when the metrics are written at the end of the run.
+func Enabled() bool {
+	lock.Lock()
+	defer lock.Unlock()
+	return dir != "" && gauges != nil
+}
+
+// SetLabel sets a label added to every metric of the run, such as the datacenter.
+func SetLabel(name, value string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	for i, l := range labels {
+		if l.Name == name {
+			labels[i].Value = value
+			return
+		}
+	}
+	labels = append(labels, Label{name, value})
+}
+
+// Set sets the gauge with the given name, which is prefixed by the app name, and labels.
+func Set(name, help string, value float64, l ...Label) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if g := get(name, help); g != nil {
+		g.samples[format(l)] = value
+	}
+}
+
+// Add adds the value to the gauge with the given name and labels.
+func Add(name, help string, value float64, l ...Label) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if g := get(name, help); g != nil {
+		g.samples[format(l)] += value
+	}
+}
+
+func get(name, help string) *gauge {
+	if gauges == nil {
+		return nil
+	}
+	g := gauges[name]
+	if g == nil {
+		g = &gauge{help: help, samples: map[string]float64{}}
+		gauges[name] = g
+	}
+	return g
+}
+
+// Fini adds the run's duration, result and time and writes the metrics. The file
+// is named after the app and the run's labels so runs against different datacenters
+// don't overwrite each other.
+func Fini(result int) error {
+	if !Enabled() {
+		return nil
+	}
+	Set("run_duration_seconds", "Duration of the run in seconds.", time.Since(start).Seconds())
+	Set("run_result", "Exit status of the run, 0 on success.", float64(result))
+	Set("run_timestamp_seconds", "Unix time the run finished.", float64(time.Now().Unix()))
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	names := []string{appname}
+	for _, l := range labels {
+		names = append(names, l.Value)
+	}
+	name := filepath.Join(dir, sanitize(strings.Join(names, "_"))+".prom")
+	data := text()
+	gauges = nil
+
+	// Write to a temporary file first so the collector never reads a partial file.
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(data), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// text returns the metrics in the Prometheus text format.
+func text() string {
+	names := []string{}
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for _, name := range names {
+		g := gauges[name]
+		metric := appname + "_" + name
+		fmt.Fprintf(&b, "# HELP %s %s\n", metric, g.help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", metric)
+		samples := []string{}
+		for s := range g.samples {
+			samples = append(samples, s)
+		}
+		sort.Strings(samples)
+		for _, s := range samples {
+			l := format(labels)
+			switch {
+			case l == "":
+				l = s
+			case s != "":
+				l = l[:len(l)-1] + "," + s[1:]
+			}
+			fmt.Fprintf(&b, "%s%s %g\n", metric, l, g.samples[s])
+		}
+	}
+	return b.String()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func format(l []Label) string {
+	if len(l) == 0 {
+		return ""
+	}
+	s := []string{}
+	for _, v := range l {
+		s = append(s, fmt.Sprintf("%s=\"%s\"", v.Name, escaper.Replace(v.Value)))
+	}
+	return "{" + strings.Join(s, ",") + "}"
+}
+
+var unsafe = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+func sanitize(s string) string {
+	return unsafe.ReplaceAllString(s, "_")
+}
@@ -0,0 +1,100 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package metrics
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDisabled(t *testing.T) {
+	dir = ""
+	os.Unsetenv("metrics_dir")
+	Init("test")
+	if Enabled() {
+		t.Fatal("Expected metrics to be disabled without a directory")
+	}
+	Set("gauge", "A gauge.", 1)
+	if err := Fini(0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFini(t *testing.T) {
+	d, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+	defer func() { dir = "" }()
+
+	args := ParseFlags([]string{"arc", "--metrics-dir=" + d, "dc1", "audit"})
+	if strings.Join(args, " ") != "arc dc1 audit" {
+		t.Errorf("Unexpected args %v", args)
+	}
+	Init("arc")
+	if !Enabled() {
+		t.Fatal("Expected metrics to be enabled")
+	}
+	SetLabel("datacenter", "dc1")
+	SetLabel("command", "audit")
+	Set("pod_instances", "Number of deployed instances of the pod.", 2, Label{"pod", "web"})
+	Add("resources", "Number of resources by type and state.", 1, Label{"state", "running"})
+	Add("resources", "Number of resources by type and state.", 1, Label{"state", "running"})
+	Add("resources", "Number of resources by type and state.", 1, Label{"state", `a "b"`})
+	if err := Fini(3); err != nil {
+		t.Fatal(err)
+	}
+	if Enabled() {
+		t.Error("Expected metrics to be disabled after Fini")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(d, "arc_dc1_audit.prom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, s := range []string{
+		"# HELP arc_pod_instances Number of deployed instances of the pod.\n# TYPE arc_pod_instances gauge\n",
+		`arc_pod_instances{datacenter="dc1",command="audit",pod="web"} 2` + "\n",
+		`arc_resources{datacenter="dc1",command="audit",state="running"} 2` + "\n",
+		`arc_resources{datacenter="dc1",command="audit",state="a \"b\""} 1` + "\n",
+		`arc_run_result{datacenter="dc1",command="audit"} 3` + "\n",
+		"# TYPE arc_run_duration_seconds gauge\n",
+		"arc_run_timestamp_seconds{",
+	} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Expected %q in:\n%s", s, text)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(d, "arc_dc1_audit.prom.tmp")); !os.IsNotExist(err) {
+		t.Error("Expected the temporary file to be renamed")
+	}
+}
@@ -0,0 +1,283 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package mirror
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/msg"
+)
+
+// defaultUrl is the mirror used when one isn't configured. It serves
+// /var/www/mirror on the mirror server over https, since the manifests are
+// pulled from the mirror along with the packages they list. Over plain http
+// the checksums only protect against corrupted downloads, not against a
+// package and its manifest entry being replaced in transit.
+const defaultUrl = "https://mirror"
+
+type mirror struct {
+	url       *url.URL
+	cache     string
+	client    *http.Client
+	manifests map[string]manifest
+}
+
+var m *mirror
+
+// Init configures the package mirror. If the configuration is nil or
+// incomplete the default mirror url is used, and packages are cached in
+// the mirror directory next to the per run arc directories.
+func Init(cfg *config.Mirror) error {
+	rawUrl, cache := "", ""
+	if cfg != nil {
+		rawUrl, cache = cfg.Url(), cfg.Cache()
+	}
+	if rawUrl == "" {
+		rawUrl = defaultUrl
+	}
+	if cache == "" {
+		cache = filepath.Join(filepath.Dir(env.Lookup("ARC")), "mirror")
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https", "file":
+	default:
+		return mirrorError{fmt.Sprintf("Unsupported mirror url scheme %q", u.Scheme)}
+	}
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		return err
+	}
+	m = &mirror{
+		url:       u,
+		cache:     cache,
+		client:    &http.Client{Timeout: 10 * time.Minute},
+		manifests: map[string]manifest{},
+	}
+	return nil
+}
+
+// ServerType pulls the named servertype package from the mirror. It returns
+// the path to the verified package in the local cache.
+func ServerType(servertype, pkgName string) (string, error) {
+	name, err := pkgPath(pkgName)
+	if err != nil {
+		return "", err
+	}
+	return fetch("servertype/"+servertype, name)
+}
+
+// Aide pulls the named aide puppet module package from the mirror. It returns
+// the path to the verified package in the local cache.
+func Aide(pkgName string) (string, error) {
+	name, err := pkgPath(pkgName)
+	if err != nil {
+		return "", err
+	}
+	return fetch("puppet-modules/puppet-aide", name)
+}
+
+// Packages pulls the packages-<version>.txt file for the given servertype and
+// every package it lists from the mirror. It returns the path to the packages
+// file and the paths to the packages, all verified in the local cache. If the
+// servertype doesn't publish a packages file for the version, the returned
+// packages file path is empty.
+func Packages(servertype, version string) (string, []string, error) {
+	dir := "servertype/" + servertype
+	name := fmt.Sprintf("packages-%s.txt", version)
+
+	mf, err := getManifest(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := mf[name]; !ok {
+		log.Debug("Mirror: %s/%s not found", dir, name)
+		return "", nil, nil
+	}
+	packagesFile, err := fetch(dir, name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	f, err := os.Open(packagesFile)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	pkgs := []string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		pkg := strings.TrimSpace(s.Text())
+		if pkg == "" {
+			continue
+		}
+		p, err := fetch(dir, pkg)
+		if err != nil {
+			return "", nil, err
+		}
+		pkgs = append(pkgs, p)
+	}
+	if err := s.Err(); err != nil {
+		return "", nil, err
+	}
+	return packagesFile, pkgs, nil
+}
+
+// pkgPath returns the location of the package relative to its mirror directory.
+func pkgPath(pkgName string) (string, error) {
+	switch path.Ext(pkgName) {
+	case ".deb":
+		return "pool/main/" + pkgName, nil
+	case ".rpm":
+		return pkgName, nil
+	}
+	return "", mirrorError{fmt.Sprintf("Unknown package type for %q", pkgName)}
+}
+
+// getManifest returns the manifest for the given mirror directory. Manifests
+// are pulled once per run so they always reflect the current mirror.
+func getManifest(dir string) (manifest, error) {
+	if m == nil {
+		return nil, mirrorError{"The mirror has not been initialized"}
+	}
+	if mf, ok := m.manifests[dir]; ok {
+		return mf, nil
+	}
+	log.Debug("Mirror: pulling manifest %s/%s", dir, manifestName)
+	r, err := m.open(dir + "/" + manifestName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	mf, err := parseManifest(r)
+	if err != nil {
+		return nil, mirrorError{fmt.Sprintf("%s/%s: %s", dir, manifestName, err.Error())}
+	}
+	m.manifests[dir] = mf
+	return mf, nil
+}
+
+// fetch returns the path of the verified file in the local cache, pulling it
+// from the mirror if it isn't cached or the cached copy fails verification.
+func fetch(dir, name string) (string, error) {
+	mf, err := getManifest(dir)
+	if err != nil {
+		return "", err
+	}
+	sum, ok := mf[name]
+	if !ok {
+		return "", mirrorError{fmt.Sprintf("%s is not listed in the %s/%s manifest", name, dir, manifestName)}
+	}
+
+	local := filepath.Join(m.cache, filepath.FromSlash(dir), filepath.FromSlash(name))
+	if _, err := os.Stat(local); err == nil {
+		if err := verify(local, sum); err == nil {
+			msg.Detail("Using cached %s", path.Base(name))
+			return local, nil
+		}
+		log.Warn("Mirror: cached %s failed verification, pulling it again", local)
+	}
+
+	msg.Detail("Pulling %s from mirror", path.Base(name))
+	if err := m.download(dir+"/"+name, local, sum); err != nil {
+		return "", err
+	}
+	return local, nil
+}
+
+// download copies the source from the mirror into the cache. The file is
+// written to a temporary file and is only moved into place once verified,
+// so concurrent runs never see a partial or corrupt package.
+func (m *mirror) download(src, dest, sum string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	r, err := m.open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(dest), "."+filepath.Base(dest))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := verify(tmp.Name(), sum); err != nil {
+		return mirrorError{fmt.Sprintf("%s failed verification: %s", src, err.Error())}
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// open returns a reader for the given path relative to the mirror url.
+func (m *mirror) open(p string) (io.ReadCloser, error) {
+	u := *m.url
+	u.Path = path.Join(u.Path, p)
+	log.Debug("Mirror: opening %s", u.String())
+
+	if u.Scheme == "file" {
+		return os.Open(filepath.FromSlash(u.Path))
+	}
+	resp, err := m.client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, mirrorError{fmt.Sprintf("Failed to pull %s: %s", u.String(), resp.Status)}
+	}
+	return resp.Body, nil
+}
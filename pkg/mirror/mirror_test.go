@@ -0,0 +1,181 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/log"
+)
+
+func sum(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func write(t *testing.T, name, data string) {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setup creates a file:// mirror with a servertype directory and returns
+// the path to the mirror root.
+func setup(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Set("MIRROR", dir)
+	if err := log.Init("mirror"); err != nil {
+		t.Fatal(err)
+	}
+
+	root := filepath.Join(dir, "root")
+	st := filepath.Join(root, "servertype", "web")
+	write(t, filepath.Join(st, "servertype-web-1.0.0-3.x86_64.rpm"), "rpm")
+	write(t, filepath.Join(st, "pool", "main", "servertype-web_1.0.0-3_amd64.deb"), "deb")
+	write(t, filepath.Join(st, "packages-3.txt"), "extra.rpm\n")
+	write(t, filepath.Join(st, "extra.rpm"), "extra")
+	write(t, filepath.Join(st, manifestName), strings.Join([]string{
+		sum("rpm") + "  servertype-web-1.0.0-3.x86_64.rpm",
+		sum("deb") + "  pool/main/servertype-web_1.0.0-3_amd64.deb",
+		sum("extra.rpm\n") + " *packages-3.txt",
+		sum("extra") + "  extra.rpm",
+	}, "\n"))
+
+	cfg := &config.Mirror{Url_: "file://" + root, Cache_: filepath.Join(dir, "cache")}
+	if err := Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestParseManifest(t *testing.T) {
+	mf, err := parseManifest(strings.NewReader("# comment\n\n" + sum("a") + "  ./a.rpm\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mf["a.rpm"] != sum("a") {
+		t.Errorf("Expected %q, got %q\n", sum("a"), mf["a.rpm"])
+	}
+	if _, err := parseManifest(strings.NewReader("abc a.rpm\n")); err == nil {
+		t.Errorf("Expected malformed checksum error\n")
+	}
+	if _, err := parseManifest(strings.NewReader(sum("a") + "\n")); err == nil {
+		t.Errorf("Expected malformed entry error\n")
+	}
+}
+
+func TestServerType(t *testing.T) {
+	dir := setup(t)
+	defer os.RemoveAll(dir)
+
+	for _, pkg := range []string{"servertype-web-1.0.0-3.x86_64.rpm", "servertype-web_1.0.0-3_amd64.deb"} {
+		p, err := ServerType("web", pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Base(p) != pkg {
+			t.Errorf("Expected %q, got %q\n", pkg, filepath.Base(p))
+		}
+		if !strings.HasPrefix(p, filepath.Join(dir, "cache")) {
+			t.Errorf("Expected %q to be in the cache\n", p)
+		}
+	}
+	if _, err := ServerType("web", "servertype-web.tgz"); err == nil {
+		t.Errorf("Expected unknown package type error\n")
+	}
+	if _, err := ServerType("web", "servertype-web-1.0.0-4.x86_64.rpm"); err == nil {
+		t.Errorf("Expected unlisted package error\n")
+	}
+}
+
+func TestPackages(t *testing.T) {
+	dir := setup(t)
+	defer os.RemoveAll(dir)
+
+	f, pkgs, err := Packages("web", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(f) != "packages-3.txt" {
+		t.Errorf("Expected %q, got %q\n", "packages-3.txt", f)
+	}
+	if len(pkgs) != 1 || filepath.Base(pkgs[0]) != "extra.rpm" {
+		t.Errorf("Expected [extra.rpm], got %q\n", pkgs)
+	}
+
+	f, pkgs, err = Packages("web", "4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != "" || pkgs != nil {
+		t.Errorf("Expected no packages, got %q, %q\n", f, pkgs)
+	}
+}
+
+func TestVerification(t *testing.T) {
+	dir := setup(t)
+	defer os.RemoveAll(dir)
+
+	pkg := "servertype-web-1.0.0-3.x86_64.rpm"
+	p, err := ServerType("web", pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A corrupted cache entry is pulled again from the mirror.
+	write(t, p, "corrupt")
+	if p, err = ServerType("web", pkg); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(p, sum("rpm")); err != nil {
+		t.Error(err)
+	}
+
+	// A corrupted package on the mirror is rejected.
+	os.Remove(p)
+	write(t, filepath.Join(dir, "root", "servertype", "web", pkg), "tampered")
+	if _, err := ServerType("web", pkg); err == nil {
+		t.Errorf("Expected checksum mismatch error\n")
+	}
+	if _, err := os.Stat(p); err == nil {
+		t.Errorf("Expected unverified package to be removed from the cache\n")
+	}
+}
@@ -42,6 +42,7 @@ type instance struct {
 	state            string
 	privateIPAddress string
 	publicIPAddress  string
+	tags             map[string]string
 }
 
 func (i *instance) Role() *resource.Role {
@@ -81,9 +82,16 @@ func (i *instance) Stopped() bool {
 }
 
 func (i *instance) SetTags(t map[string]string) error {
+	for k, v := range t {
+		i.tags[k] = v
+	}
 	return nil
 }
 
+func (i *instance) Tags() map[string]string {
+	return i.tags
+}
+
 func (i *instance) Audit(flags ...string) error {
 	return nil
 }
@@ -100,6 +108,7 @@ func newInstance(cfg *config.Instance, p *dataCenterProvider) (resource.Provider
 		state:            "available",
 		privateIPAddress: "192.168.0.1",
 		publicIPAddress:  "34.33.32.31",
+		tags:             map[string]string{},
 	}
 	return i, nil
 }
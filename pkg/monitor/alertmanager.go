@@ -0,0 +1,123 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/resource"
+)
+
+// silenceDuration is the length of a silence created without an end time.
+// Such silences are expired explicitly when paging is enabled.
+const silenceDuration = 7 * 24 * time.Hour
+
+// silenceCreator identifies the silences created by arc.
+const silenceCreator = "arc"
+
+// alertmanager is a monitor that creates and expires alertmanager silences
+// matching the instance name.
+type alertmanager struct {
+	*config.Monitor
+}
+
+type matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+type silence struct {
+	Id        string    `json:"id,omitempty"`
+	Matchers  []matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+	Status    *struct {
+		State string `json:"state"`
+	} `json:"status,omitempty"`
+}
+
+func newAlertmanager(cfg *config.Monitor) *alertmanager {
+	return &alertmanager{cfg}
+}
+
+func (a *alertmanager) Name() string {
+	return "alertmanager"
+}
+
+func (a *alertmanager) label() string {
+	if a.Label() == "" {
+		return "instance"
+	}
+	return a.Label()
+}
+
+func (a *alertmanager) Enable(i resource.Instance) error {
+	filter := url.Values{}
+	filter.Set("filter", fmt.Sprintf("%s=%q", a.label(), i.Name()))
+	body, err := request(a.Monitor, "GET", "/api/v2/silences?"+filter.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	silences := []silence{}
+	if err := json.Unmarshal(body, &silences); err != nil {
+		return fmt.Errorf("Unable to parse alertmanager silences: %s", err.Error())
+	}
+	for _, s := range silences {
+		if s.CreatedBy != silenceCreator || s.Status == nil || s.Status.State == "expired" {
+			continue
+		}
+		if _, err := request(a.Monitor, "DELETE", "/api/v2/silence/"+url.PathEscape(s.Id), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *alertmanager) Disable(i resource.Instance, until time.Time) error {
+	now := time.Now().UTC()
+	if until.IsZero() {
+		until = now.Add(silenceDuration)
+	}
+	s := silence{
+		Matchers:  []matcher{{Name: a.label(), Value: i.Name(), IsEqual: true}},
+		StartsAt:  now,
+		EndsAt:    until.UTC(),
+		CreatedBy: silenceCreator,
+		Comment:   fmt.Sprintf("Paging disabled by %s", env.Lookup("USER")),
+	}
+	_, err := request(a.Monitor, "POST", "/api/v2/silences", s)
+	return err
+}
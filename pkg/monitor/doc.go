@@ -0,0 +1,51 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+// Package monitor provides the integrations used to enable and disable
+// paging for an instance.
+//
+// Each integration satisfies the Monitor interface. The consul and sensu
+// integrations run the scripts in /usr/lib/arc/paging on the instance, the
+// alertmanager integration creates and expires silences via the alertmanager
+// v2 api, and the webhook integration posts a json event to a url.
+//
+// The integrations are configured per datacenter and may be overridden per
+// pod, for example
+//
+//	"monitoring": [
+//	  { "type": "consul" },
+//	  { "type": "alertmanager", "url": "https://alertmanager.example.com", "required": true },
+//	  { "type": "webhook", "url": "https://hooks.example.com/arc", "headers": { "Authorization": "Bearer $HOOK_TOKEN" } }
+//	]
+//
+// Header values are expanded from the environment. When no integrations are
+// configured consul and sensu are used.
+//
+// A maintenance window disables paging until a given time. Alertmanager and
+// sensu expire the silence themselves. Consul and the legacy sensu stashes
+// have no expiry, so their paging scripts schedule the re-enable on the
+// instance with a systemd timer, or with at where systemd isn't available.
+package monitor
@@ -0,0 +1,96 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/resource"
+)
+
+// Monitor is the interface satisfied by each monitoring integration.
+type Monitor interface {
+	// Name returns the name of the integration.
+	Name() string
+
+	// Required returns true if a failure of the integration should fail
+	// the operation rather than being reported as a warning.
+	Required() bool
+
+	// Enable re-enables paging for the instance.
+	Enable(i resource.Instance) error
+
+	// Disable disables paging for the instance. If until is non-zero
+	// the integration should re-enable paging at that time when it is
+	// able to do so.
+	Disable(i resource.Instance, until time.Time) error
+}
+
+// defaults are the integrations used when none are configured.
+var defaults = config.Monitoring{
+	{Type_: "consul"},
+	{Type_: "sensu"},
+}
+
+// New returns the monitors for the given configuration. If the configuration
+// is empty the default consul and sensu monitors are returned.
+func New(cfg config.Monitoring) ([]Monitor, error) {
+	if len(cfg) == 0 {
+		cfg = defaults
+	}
+	monitors := []Monitor{}
+	for _, c := range cfg {
+		m, err := newMonitor(c)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, m)
+	}
+	return monitors, nil
+}
+
+func newMonitor(cfg *config.Monitor) (Monitor, error) {
+	switch cfg.Type() {
+	case "consul":
+		return newConsul(cfg), nil
+	case "sensu":
+		return newSensu(cfg), nil
+	case "alertmanager":
+		if cfg.Url() == "" {
+			return nil, monitorError{"The alertmanager monitor requires a url"}
+		}
+		return newAlertmanager(cfg), nil
+	case "webhook":
+		if cfg.Url() == "" {
+			return nil, monitorError{"The webhook monitor requires a url"}
+		}
+		return newWebhook(cfg), nil
+	}
+	return nil, monitorError{fmt.Sprintf("Unknown monitor type %q", cfg.Type())}
+}
@@ -0,0 +1,263 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cisco/arc/pkg/command"
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/resource"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "monitor")
+	if err != nil {
+		panic(err)
+	}
+	env.Set("MONITOR", dir)
+	env.Set("USER", "alice")
+	if err := log.Init("monitor"); err != nil {
+		panic(err)
+	}
+	result := m.Run()
+	log.Fini()
+	os.RemoveAll(dir)
+	os.Exit(result)
+}
+
+// testPod and testInstance stand in for the pod and instance being paged,
+// only providing the names used by the monitors.
+type testPod struct {
+	resource.Pod
+}
+
+func (p *testPod) Name() string { return "web" }
+
+type testInstance struct {
+	resource.Instance
+}
+
+func (i *testInstance) Name() string        { return "web-01" }
+func (i *testInstance) PrivateFQDN() string { return "web-01.dc.example.com" }
+func (i *testInstance) Pod() resource.Pod   { return &testPod{} }
+
+// received is a request received by the http stand-in.
+type received struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// serve starts an http stand-in recording each request and responding with
+// the given body.
+func serve(t *testing.T, response string) (*httptest.Server, *[]received) {
+	requests := &[]received{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		*requests = append(*requests, received{r.Method, r.URL.RequestURI(), r.Header, b})
+		w.Write([]byte(response))
+	}))
+	return s, requests
+}
+
+func TestNew(t *testing.T) {
+	monitors, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monitors) != 2 || monitors[0].Name() != "consul" || monitors[1].Name() != "sensu" {
+		t.Errorf("Expected the consul and sensu defaults, got %v", monitors)
+	}
+	for _, typ := range []string{"alertmanager", "webhook", "nagios"} {
+		if _, err := New(config.Monitoring{{Type_: typ}}); err == nil {
+			t.Errorf("Expected an error for %s without a url", typ)
+		}
+	}
+}
+
+func TestAlertmanager(t *testing.T) {
+	s, requests := serve(t, `[
+  {"id": "s1", "createdBy": "arc", "status": {"state": "active"}},
+  {"id": "s2", "createdBy": "arc", "status": {"state": "expired"}},
+  {"id": "s3", "createdBy": "bob", "status": {"state": "active"}}
+]`)
+	defer s.Close()
+
+	os.Setenv("MONITOR_TOKEN", "secret")
+	defer os.Unsetenv("MONITOR_TOKEN")
+	a, err := newMonitor(&config.Monitor{Type_: "alertmanager", Url_: s.URL + "/", Label_: "host", Headers_: map[string]string{"Authorization": "Bearer $MONITOR_TOKEN"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	until := time.Now().Add(time.Hour)
+	if err := a.Disable(&testInstance{}, until); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("Expected a single request, got %d", len(*requests))
+	}
+	r := (*requests)[0]
+	if r.method != "POST" || r.path != "/api/v2/silences" || r.header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Unexpected request %s %s %v", r.method, r.path, r.header)
+	}
+	sl := silence{}
+	if err := json.Unmarshal(r.body, &sl); err != nil {
+		t.Fatal(err)
+	}
+	if len(sl.Matchers) != 1 || sl.Matchers[0] != (matcher{Name: "host", Value: "web-01", IsEqual: true}) {
+		t.Errorf("Unexpected matchers %+v", sl.Matchers)
+	}
+	if !sl.EndsAt.Equal(until.UTC().Round(0)) || sl.CreatedBy != "arc" || sl.Comment != "Paging disabled by alice" {
+		t.Errorf("Unexpected silence %+v", sl)
+	}
+
+	// Enabling expires only the active silences created by arc.
+	*requests = nil
+	if err := a.Enable(&testInstance{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(*requests))
+	}
+	if r := (*requests)[0]; r.method != "GET" || r.path != "/api/v2/silences?filter=host%3D%22web-01%22" {
+		t.Errorf("Unexpected request %s %s", r.method, r.path)
+	}
+	if r := (*requests)[1]; r.method != "DELETE" || r.path != "/api/v2/silence/s1" {
+		t.Errorf("Unexpected request %s %s", r.method, r.path)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	s, requests := serve(t, "")
+	defer s.Close()
+
+	w, err := newMonitor(&config.Monitor{Type_: "webhook", Url_: s.URL + "/paging"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	until := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := w.Disable(&testInstance{}, until); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Enable(&testInstance{}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []event{
+		{Action: "disable", Instance: "web-01", Fqdn: "web-01.dc.example.com", Pod: "web", User: "alice", Until: "2018-06-01T12:00:00Z"},
+		{Action: "enable", Instance: "web-01", Fqdn: "web-01.dc.example.com", Pod: "web", User: "alice"},
+	}
+	if len(*requests) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d", len(expected), len(*requests))
+	}
+	for n, r := range *requests {
+		e := event{}
+		if err := json.Unmarshal(r.body, &e); err != nil {
+			t.Fatal(err)
+		}
+		if r.method != "POST" || r.path != "/paging" || r.header.Get("Content-Type") != "application/json" || e != expected[n] {
+			t.Errorf("Unexpected request %s %s %+v", r.method, r.path, e)
+		}
+	}
+}
+
+func TestHttpFailure(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such receiver", http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	w, err := newMonitor(&config.Monitor{Type_: "webhook", Url_: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Enable(&testInstance{})
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "no such receiver") {
+		t.Errorf("Expected the response in the error, got %v", err)
+	}
+}
+
+func TestScript(t *testing.T) {
+	defer func(r, c func(command.Command) ([]byte, error)) { runRemote, copyTo = r, c }(runRemote, copyTo)
+	runs, copies := []command.Command{}, []command.Command{}
+	var failure error
+	runRemote = func(c command.Command) ([]byte, error) {
+		runs = append(runs, c)
+		return []byte("output"), failure
+	}
+	copyTo = func(c command.Command) ([]byte, error) {
+		copies = append(copies, c)
+		return nil, nil
+	}
+
+	monitors, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consul, sensu := monitors[0], monitors[1]
+	if err := consul.Disable(&testInstance{}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sensu.Enable(&testInstance{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].Src != "/usr/lib/arc/paging/consul_paging" || len(runs[0].Args) != 3 || runs[0].Args[0] != "disable" || runs[0].Args[1] != "web-01" {
+		t.Errorf("Unexpected consul run %s %v", runs[0].Src, runs[0].Args)
+	}
+	if secs, err := strconv.Atoi(runs[0].Args[2]); err != nil || secs < 3590 || secs > 3600 {
+		t.Errorf("Expected the silence to expire in an hour, got %q", runs[0].Args[2])
+	}
+	if runs[1].Src != "/usr/lib/arc/paging/sensu_paging" || strings.Join(runs[1].Args, " ") != "enable web-01" {
+		t.Errorf("Unexpected sensu run %s %v", runs[1].Src, runs[1].Args)
+	}
+	if len(copies) != 1 || copies[0].Src != "/usr/lib/arc/tools/check_monit_services" {
+		t.Errorf("Expected sensu to copy check_monit_services, got %v", copies)
+	}
+
+	failure = errors.New("exit status 1")
+	if err := consul.Enable(&testInstance{}); err == nil || !strings.Contains(err.Error(), "Unable to enable consul paging") {
+		t.Errorf("Expected the script failure, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cisco/arc/pkg/command"
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/log"
+	"github.com/cisco/arc/pkg/resource"
+)
+
+// runRemote and copyTo run commands on the instance, and are replaced by the
+// tests.
+var (
+	runRemote = command.RunRemoteWithOutput
+	copyTo    = command.CopyToWithOutput
+)
+
+// script is a monitor driven by a paging script run on the instance.
+// The script takes the arguments "(enable|disable) server [expire]"
+// where expire is the number of seconds the silence should last.
+type script struct {
+	*config.Monitor
+	prepare func(resource.Instance) error
+}
+
+func newConsul(cfg *config.Monitor) *script {
+	return &script{Monitor: cfg}
+}
+
+func newSensu(cfg *config.Monitor) *script {
+	return &script{
+		Monitor: cfg,
+		prepare: func(i resource.Instance) error {
+			_, err := copyTo(command.Command{
+				Instance: i,
+				Desc:     "Copy check_monit_services",
+				Src:      "/usr/lib/arc/tools/check_monit_services",
+			})
+			return err
+		},
+	}
+}
+
+func (s *script) Name() string {
+	return s.Type()
+}
+
+func (s *script) Enable(i resource.Instance) error {
+	if s.prepare != nil {
+		if err := s.prepare(i); err != nil {
+			return err
+		}
+	}
+	return s.run(i, "enable")
+}
+
+func (s *script) Disable(i resource.Instance, until time.Time) error {
+	args := []string{}
+	if !until.IsZero() {
+		args = append(args, strconv.Itoa(int(time.Until(until).Seconds())))
+	}
+	return s.run(i, "disable", args...)
+}
+
+func (s *script) run(i resource.Instance, action string, args ...string) error {
+	output, err := runRemote(command.Command{
+		Instance: i,
+		Desc:     action + " " + s.Name() + " paging",
+		Src:      "/usr/lib/arc/paging/" + s.Name() + "_paging",
+		Args:     append([]string{action, i.Name()}, args...),
+	})
+	if err != nil {
+		log.Warn("%s", output)
+		return fmt.Errorf("Unable to %s %s paging: %s", action, s.Name(), err.Error())
+	}
+	return nil
+}
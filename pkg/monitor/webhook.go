@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package monitor
+
+import (
+	"time"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/resource"
+)
+
+// webhook is a monitor that posts a json event to a url each time paging
+// is enabled or disabled.
+type webhook struct {
+	*config.Monitor
+}
+
+// event is the body posted by the webhook monitor. Until is only set
+// when paging is disabled for a fixed duration.
+type event struct {
+	Action   string `json:"action"`
+	Instance string `json:"instance"`
+	Fqdn     string `json:"fqdn"`
+	Pod      string `json:"pod"`
+	User     string `json:"user"`
+	Until    string `json:"until,omitempty"`
+}
+
+func newWebhook(cfg *config.Monitor) *webhook {
+	return &webhook{cfg}
+}
+
+func (w *webhook) Name() string {
+	return "webhook"
+}
+
+func (w *webhook) Enable(i resource.Instance) error {
+	return w.post(i, "enable", time.Time{})
+}
+
+func (w *webhook) Disable(i resource.Instance, until time.Time) error {
+	return w.post(i, "disable", until)
+}
+
+func (w *webhook) post(i resource.Instance, action string, until time.Time) error {
+	e := event{
+		Action:   action,
+		Instance: i.Name(),
+		Fqdn:     i.PrivateFQDN(),
+		Pod:      i.Pod().Name(),
+		User:     env.Lookup("USER"),
+	}
+	if !until.IsZero() {
+		e.Until = until.UTC().Format(time.RFC3339)
+	}
+	_, err := request(w.Monitor, "POST", "", e)
+	return err
+}
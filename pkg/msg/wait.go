@@ -28,6 +28,8 @@ package msg
 
 import (
 	"time"
+
+	"github.com/cisco/arc/pkg/trace"
 )
 
 func Wait(title, err string, duration int, test, load func() bool) bool {
@@ -35,6 +37,7 @@ func Wait(title, err string, duration int, test, load func() bool) bool {
 	if duration < 0 {
 		return false
 	}
+	defer trace.Start("wait", title).End()
 	count, max := 0, duration
 	for ; count < max; count++ {
 		if count == 2 {
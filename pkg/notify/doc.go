@@ -0,0 +1,50 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+// Package notify provides the sinks accounting and audit messages are sent to.
+//
+// Each sink satisfies the Sink interface and renders messages in its own
+// format. The spark sink posts markdown to a Webex Spark room, the slack sink
+// posts mrkdwn to a Slack compatible incoming webhook, the webhook sink posts
+// the message as json, the smtp sink sends a plain text email and the file
+// sink appends the message as a line of json.
+//
+// The sinks are configured per datacenter, for example
+//
+//	"notifications": {
+//	  "sinks": [
+//	    { "type": "spark", "room": "44a5ce00-...", "messages": [ "accounting" ] },
+//	    { "type": "slack", "url": "$SLACK_WEBHOOK_URL" },
+//	    { "type": "webhook", "url": "https://hooks.example.com/arc", "headers": { "Authorization": "Bearer $HOOK_TOKEN" } },
+//	    { "type": "smtp", "server": "smtp.example.com:25", "from": "arc@example.com", "to": [ "ops@example.com" ], "messages": [ "audit" ] },
+//	    { "type": "file", "path": "/var/log/arc/notifications.log" }
+//	  ]
+//	}
+//
+// Messages lists the message types routed to a sink, an empty list routes
+// every type. The spark rooms of older configurations are converted to spark
+// sinks receiving the message type the room is keyed by.
+package notify
@@ -0,0 +1,116 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/log"
+)
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// post sends body as json to the url, returning an error if the request
+// fails or the response status isn't 2xx. The url and header values are
+// expanded from the environment.
+func post(url string, headers map[string]string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	url = os.ExpandEnv(url)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+	log.Debug("POST %s", req.URL.Host)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("POST %s: %s %s", req.URL.Host, resp.Status, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// slackLimit keeps slack messages below the incoming webhook text limit,
+// longer messages are sent in chunks.
+const slackLimit = 35000
+
+// slack posts mrkdwn to a Slack compatible incoming webhook.
+type slack struct {
+	*config.Sink
+}
+
+func newSlack(cfg *config.Sink) *slack {
+	return &slack{cfg}
+}
+
+func (s *slack) Name() string {
+	return "slack"
+}
+
+func (s *slack) Send(m *Message) error {
+	for _, c := range chunk(markdown(m, "*"), slackLimit) {
+		if err := post(s.Url(), s.Headers(), map[string]string{"text": c}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webhook posts the message as json.
+type webhook struct {
+	*config.Sink
+}
+
+func newWebhook(cfg *config.Sink) *webhook {
+	return &webhook{cfg}
+}
+
+func (w *webhook) Name() string {
+	return "webhook"
+}
+
+func (w *webhook) Send(m *Message) error {
+	return post(w.Url(), w.Headers(), m)
+}
@@ -0,0 +1,173 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cisco/arc/pkg/config"
+)
+
+// MessageType is the kind of message, used to route messages to sinks.
+type MessageType string
+
+const (
+	Accounting MessageType = "accounting"
+	Audit      MessageType = "audit"
+	Warning    MessageType = "warning"
+)
+
+// Message is a notification. The subject describes who ran what, and the
+// sections hold the body. The result is set for accounting messages. The user
+// is set for warnings addressed to a user.
+type Message struct {
+	Type     MessageType `json:"type"`
+	Title    string      `json:"title"`
+	Subject  string      `json:"subject"`
+	User     string      `json:"user,omitempty"`
+	Sections []*Section  `json:"sections"`
+	Result   string      `json:"result,omitempty"`
+	Time     time.Time   `json:"time"`
+}
+
+// Section is a titled list of lines in the body of a message.
+type Section struct {
+	Title string   `json:"title,omitempty"`
+	Lines []string `json:"lines"`
+}
+
+// Sink is the interface satisfied by each notification sink.
+type Sink interface {
+	// Name returns the name of the sink.
+	Name() string
+
+	// Send renders and delivers the message.
+	Send(m *Message) error
+}
+
+// Notifier sends messages to the sinks they are routed to.
+type Notifier struct {
+	sinks  []Sink
+	routes [][]string
+}
+
+// New returns a notifier for the given configuration.
+func New(cfg *config.Notifications) (*Notifier, error) {
+	n := &Notifier{}
+	if cfg == nil {
+		return n, nil
+	}
+	sinks := []*config.Sink{}
+	if cfg.Spark != nil {
+		// Sorted so the sinks are created in a stable order.
+		types := []string{}
+		for t := range cfg.Spark.Rooms {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			sinks = append(sinks, &config.Sink{Type_: "spark", Room_: cfg.Spark.Rooms[t], Messages_: []string{t}})
+		}
+	}
+	for _, c := range append(sinks, cfg.Sinks()...) {
+		s, err := newSink(c)
+		if err != nil {
+			return nil, err
+		}
+		n.sinks = append(n.sinks, s)
+		n.routes = append(n.routes, c.Messages())
+	}
+	return n, nil
+}
+
+func newSink(cfg *config.Sink) (Sink, error) {
+	switch cfg.Type() {
+	case "spark":
+		if cfg.Room() == "" {
+			return nil, notifyError{"The spark sink requires a room"}
+		}
+		return newSpark(cfg), nil
+	case "slack":
+		if cfg.Url() == "" {
+			return nil, notifyError{"The slack sink requires a url"}
+		}
+		return newSlack(cfg), nil
+	case "webhook":
+		if cfg.Url() == "" {
+			return nil, notifyError{"The webhook sink requires a url"}
+		}
+		return newWebhook(cfg), nil
+	case "smtp":
+		if cfg.Server() == "" || cfg.From() == "" || (len(cfg.To()) == 0 && cfg.UserDomain() == "") {
+			return nil, notifyError{"The smtp sink requires a server, from and to or user_domain"}
+		}
+		return newSmtp(cfg), nil
+	case "file":
+		if cfg.Path() == "" {
+			return nil, notifyError{"The file sink requires a path"}
+		}
+		return newFile(cfg), nil
+	}
+	return nil, notifyError{fmt.Sprintf("Unknown notification sink type %q", cfg.Type())}
+}
+
+// Send sends the message to each sink it is routed to. Every sink is tried,
+// the returned error describes the sinks that failed.
+func (n *Notifier) Send(m *Message) error {
+	if m.Time.IsZero() {
+		m.Time = time.Now().UTC()
+	}
+	errs := []string{}
+	for i, s := range n.sinks {
+		if !routed(n.routes[i], m.Type) {
+			continue
+		}
+		if err := s.Send(m); err != nil {
+			errs = append(errs, s.Name()+": "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return notifyError{"Unable to send " + string(m.Type) + " notification, " + strings.Join(errs, ", ")}
+	}
+	return nil
+}
+
+// routed returns true if the message type is in the route, or the route is empty.
+func routed(route []string, t MessageType) bool {
+	if len(route) == 0 {
+		return true
+	}
+	for _, r := range route {
+		if r == string(t) {
+			return true
+		}
+	}
+	return false
+}
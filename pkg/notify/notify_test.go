@@ -0,0 +1,342 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/log"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "notify")
+	if err != nil {
+		panic(err)
+	}
+	env.Set("NOTIFY", dir)
+	if err := log.Init("notify"); err != nil {
+		panic(err)
+	}
+	result := m.Run()
+	log.Fini()
+	os.RemoveAll(dir)
+	os.Exit(result)
+}
+
+func testMessage(t MessageType) *Message {
+	return &Message{
+		Type:     t,
+		Title:    "Accounting",
+		Subject:  "alice | 1.0 | arc dc cluster web destroy",
+		Sections: []*Section{{Lines: []string{"Instance destroyed: web-01, i-1"}}},
+		Result:   "Success",
+	}
+}
+
+// capture starts an http stand-in recording the body of each request.
+func capture(t *testing.T) (*httptest.Server, *[]map[string]interface{}) {
+	bodies := &[]map[string]interface{}{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		if r.Header.Get("Authorization") != "" {
+			body["authorization"] = r.Header.Get("Authorization")
+		}
+		*bodies = append(*bodies, body)
+	}))
+	return s, bodies
+}
+
+func TestHttpSinks(t *testing.T) {
+	s, bodies := capture(t)
+	defer s.Close()
+	os.Setenv("NOTIFY_TEST_TOKEN", "secret")
+
+	n, err := New(&config.Notifications{Sinks_: []*config.Sink{
+		{Type_: "slack", Url_: s.URL, Messages_: []string{"accounting"}},
+		{Type_: "webhook", Url_: s.URL, Headers_: map[string]string{"Authorization": "Bearer $NOTIFY_TEST_TOKEN"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(testMessage(Accounting)); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(testMessage(Audit)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The audit message isn't routed to slack.
+	if len(*bodies) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(*bodies))
+	}
+	text, _ := (*bodies)[0]["text"].(string)
+	if !strings.HasPrefix(text, "*Accounting*: alice") || !strings.Contains(text, "> Instance destroyed: web-01, i-1") {
+		t.Errorf("unexpected slack text %q", text)
+	}
+	if (*bodies)[1]["type"] != "accounting" || (*bodies)[1]["authorization"] != "Bearer secret" {
+		t.Errorf("unexpected webhook body %v", (*bodies)[1])
+	}
+	if (*bodies)[2]["type"] != "audit" {
+		t.Errorf("unexpected webhook body %v", (*bodies)[2])
+	}
+}
+
+func TestHttpSinkFailure(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	n, err := New(&config.Notifications{Sinks_: []*config.Sink{{Type_: "webhook", Url_: s.URL}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Send(testMessage(Accounting))
+	if err == nil || !strings.Contains(err.Error(), "no such hook") {
+		t.Errorf("expected failure, got %v", err)
+	}
+}
+
+// smtpStandIn accepts a single smtp session and returns the data sent.
+func smtpStandIn(t *testing.T) (string, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make(chan string, 1)
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			data <- ""
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost stand-in")
+		body := ""
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				data <- body
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					body += l
+				}
+				reply("250 ok")
+			case cmd == "QUIT":
+				reply("221 bye")
+				data <- body
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return l.Addr().String(), data
+}
+
+func TestSmtpSink(t *testing.T) {
+	addr, data := smtpStandIn(t)
+	n, err := New(&config.Notifications{Sinks_: []*config.Sink{
+		{Type_: "smtp", Server_: addr, From_: "arc@example.com", To_: []string{"ops@example.com"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(testMessage(Accounting)); err != nil {
+		t.Fatal(err)
+	}
+	body := <-data
+	for _, s := range []string{
+		"Subject: [arc] Accounting: alice | 1.0 | arc dc cluster web destroy Success",
+		"  Instance destroyed: web-01, i-1",
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("email doesn't contain %q:\n%s", s, body)
+		}
+	}
+}
+
+func TestSmtpSinkUserWarning(t *testing.T) {
+	addr, data := smtpStandIn(t)
+	n, err := New(&config.Notifications{Sinks_: []*config.Sink{
+		{Type_: "smtp", Server_: addr, From_: "arc@example.com", UserDomain_: "example.com", Messages_: []string{"warning"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Message{
+		Type:     Warning,
+		Title:    "Warning",
+		Subject:  "ssh keys of alice expire soon, please rotate them",
+		User:     "alice",
+		Sections: []*Section{{Lines: []string{"ssh key SHA256:abc of alice expires on 2026-11-01"}}},
+	}
+	if err := n.Send(m); err != nil {
+		t.Fatal(err)
+	}
+	body := <-data
+	for _, s := range []string{
+		"To: alice@example.com",
+		"Subject: [arc] Warning: ssh keys of alice expire soon",
+		"  ssh key SHA256:abc of alice expires on 2026-11-01",
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("email doesn't contain %q:\n%s", s, body)
+		}
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "notifications.log")
+
+	n, err := New(&config.Notifications{Sinks_: []*config.Sink{{Type_: "file", Path_: name, Messages_: []string{"audit"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []*Message{testMessage(Audit), testMessage(Accounting), testMessage(Audit)} {
+		if err := n.Send(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	m := &Message{}
+	if err := json.Unmarshal([]byte(lines[0]), m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != Audit || m.Subject != testMessage(Audit).Subject || m.Time.IsZero() {
+		t.Errorf("unexpected message %+v", m)
+	}
+}
+
+func TestNew(t *testing.T) {
+	n, err := New(&config.Notifications{Spark: &config.Spark{Rooms: map[string]string{"audit": "a", "accounting": "b"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.sinks) != 2 || n.routes[0][0] != "accounting" || n.routes[1][0] != "audit" {
+		t.Errorf("unexpected spark sinks %v", n.routes)
+	}
+	for _, s := range []*config.Sink{
+		{Type_: "pager"},
+		{Type_: "slack"},
+		{Type_: "smtp", Server_: "localhost:25"},
+		{Type_: "file"},
+	} {
+		if _, err := New(&config.Notifications{Sinks_: []*config.Sink{s}}); err == nil {
+			t.Errorf("expected an error for %+v", s)
+		}
+	}
+}
+
+func TestChunk(t *testing.T) {
+	lines := []string{}
+	for i := 0; i < 100; i++ {
+		lines = append(lines, fmt.Sprintf("> line %03d of the report", i))
+	}
+	s := strings.Join(lines, "\n")
+	if c := chunk(s, len(s)); len(c) != 1 || c[0] != s {
+		t.Errorf("short message was chunked")
+	}
+
+	chunks := chunk(s, 300)
+	joined := ""
+	for i, c := range chunks {
+		if len(c) > 300 {
+			t.Errorf("chunk %d is %d bytes", i, len(c))
+		}
+		prefix := fmt.Sprintf("(%d/%d)\n", i+1, len(chunks))
+		if !strings.HasPrefix(c, prefix) {
+			t.Errorf("chunk %d isn't numbered: %q", i, c[:10])
+		}
+		joined += strings.TrimPrefix(c, prefix)
+	}
+	if joined != s {
+		t.Error("chunks don't rejoin to the message")
+	}
+
+	// Lines longer than the limit are split.
+	long := strings.Repeat("x", 1000)
+	chunks = chunk(long, 300)
+	if len(chunks) != 4 {
+		t.Errorf("expected 4 chunks, got %d", len(chunks))
+	}
+
+	// Long lines are split between characters.
+	long = strings.Repeat("é", 500)
+	chunks = chunk(long, 301)
+	joined = ""
+	for i, c := range chunks {
+		if len(c) > 301 || !utf8.ValidString(c) {
+			t.Errorf("chunk %d is %d bytes, valid utf-8 %v", i, len(c), utf8.ValidString(c))
+		}
+		joined += c[strings.Index(c, "\n")+1:]
+	}
+	if joined != long {
+		t.Error("chunks don't rejoin to the message")
+	}
+}
@@ -0,0 +1,112 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// text renders the message as plain text.
+func text(m *Message) string {
+	s := m.Subject + "\n"
+	for _, sec := range m.Sections {
+		s += "\n"
+		if sec.Title != "" {
+			s += sec.Title + "\n"
+		}
+		for _, l := range sec.Lines {
+			s += "  " + l + "\n"
+		}
+	}
+	if m.Result != "" {
+		s += "\n" + m.Result + "\n"
+	}
+	return s
+}
+
+// markdown renders the message as markdown with the given bold delimiter,
+// "**" for spark and "*" for slack. The body is quoted.
+func markdown(m *Message, bold string) string {
+	s := bold + m.Title + bold + ": " + m.Subject + "\n"
+	for _, sec := range m.Sections {
+		if sec.Title != "" {
+			s += "> " + bold + "`" + sec.Title + "`" + bold + "\n"
+		}
+		for _, l := range sec.Lines {
+			s += "> " + strings.Replace(l, "\n", "\n> ", -1) + "\n"
+		}
+	}
+	if m.Result != "" {
+		s += "\n" + bold + m.Result + bold
+	}
+	return s
+}
+
+// chunk splits s into ordered chunks of at most limit bytes, breaking at line
+// boundaries where possible and never within a character. When there is more
+// than one chunk each is numbered, the numbering is included in the limit.
+func chunk(s string, limit int) []string {
+	const numbering = 16
+	if len(s) <= limit {
+		return []string{s}
+	}
+	limit -= numbering
+	chunks := []string{}
+	c := ""
+	for _, l := range strings.SplitAfter(s, "\n") {
+		for len(l) > limit {
+			if c != "" {
+				chunks = append(chunks, c)
+				c = ""
+			}
+			// Split at a rune boundary so characters aren't cut in two.
+			n := limit
+			for n > 0 && !utf8.RuneStart(l[n]) {
+				n--
+			}
+			if n == 0 {
+				n = limit
+			}
+			chunks = append(chunks, l[:n])
+			l = l[n:]
+		}
+		if len(c)+len(l) > limit {
+			chunks = append(chunks, c)
+			c = ""
+		}
+		c += l
+	}
+	if c != "" {
+		chunks = append(chunks, c)
+	}
+	for i := range chunks {
+		chunks[i] = fmt.Sprintf("(%d/%d)\n", i+1, len(chunks)) + chunks[i]
+	}
+	return chunks
+}
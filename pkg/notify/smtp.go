@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package notify
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"github.com/cisco/arc/pkg/config"
+)
+
+// smtpSink sends the message as a plain text email. Messages addressed to a
+// user are also sent to the user at the user domain, if it is configured.
+type smtpSink struct {
+	*config.Sink
+}
+
+func newSmtp(cfg *config.Sink) *smtpSink {
+	return &smtpSink{cfg}
+}
+
+func (s *smtpSink) Name() string {
+	return "smtp"
+}
+
+func (s *smtpSink) Send(m *Message) error {
+	var auth smtp.Auth
+	if s.Username() != "" {
+		host, _, err := net.SplitHostPort(s.Server())
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.Username(), os.ExpandEnv(s.Password()), host)
+	}
+	subject := fmt.Sprintf("[arc] %s: %s", m.Title, m.Subject)
+	if m.Result != "" {
+		subject += " " + m.Result
+	}
+	to := s.To()
+	if m.User != "" && s.UserDomain() != "" {
+		to = append([]string{m.User + "@" + s.UserDomain()}, to...)
+	}
+	if len(to) == 0 {
+		return nil
+	}
+	body := "From: " + s.From() + "\r\n" +
+		"To: " + strings.Join(to, ", ") + "\r\n" +
+		"Subject: " + strings.Replace(subject, "\n", " ", -1) + "\r\n" +
+		"Date: " + m.Time.Format("Mon, 02 Jan 2006 15:04:05 -0700") + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		strings.Replace(text(m), "\n", "\r\n", -1)
+	return smtp.SendMail(s.Server(), auth, s.From(), to, []byte(body))
+}
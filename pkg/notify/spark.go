@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package notify
+
+import (
+	"fmt"
+
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/env"
+	"github.com/cisco/arc/pkg/spark"
+)
+
+// sparkLimit keeps spark messages below the spark message size limit, longer
+// messages are sent in chunks.
+const sparkLimit = 7000
+
+// sparkSink posts markdown to a Webex Spark room using SPARK_TOKEN.
+type sparkSink struct {
+	*config.Sink
+}
+
+func newSpark(cfg *config.Sink) *sparkSink {
+	return &sparkSink{cfg}
+}
+
+func (s *sparkSink) Name() string {
+	return "spark"
+}
+
+func (s *sparkSink) Send(m *Message) error {
+	token := env.Lookup("SPARK_TOKEN")
+	if token == "" {
+		return notifyError{"No spark token available"}
+	}
+	client, err := spark.New(token, s.Room(), spark.Html)
+	if err != nil {
+		return err
+	}
+	for _, c := range chunk(markdown(m, "**"), sparkLimit) {
+		if _, err := fmt.Fprint(client, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
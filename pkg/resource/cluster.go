@@ -38,6 +38,7 @@ type StaticCluster interface {
 	Name() string
 	SecurityTags() config.SecurityTags
 	AuditIgnore() bool
+	PatchBatch() int
 }
 
 // Cluster provides the resource interface used for the common cluster
@@ -98,6 +99,11 @@ type Cluster interface {
 	Replace(req *route.Request) route.Response
 	PostReplace(req *route.Request) route.Response
 
+	// Patcher
+	PrePatch(req *route.Request) route.Response
+	Patch(req *route.Request) route.Response
+	PostPatch(req *route.Request) route.Response
+
 	// Auditor
 	AuditOverride
 }
@@ -30,6 +30,8 @@ import "github.com/cisco/arc/pkg/config"
 
 type StaticDataCenter interface {
 	SecurityTags() config.SecurityTags
+	Monitoring() config.Monitoring
+	SshCa() bool
 }
 
 // DataCenter provides the resource interface used for the common datacenter
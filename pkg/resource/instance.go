@@ -74,6 +74,9 @@ type DynamicInstance interface {
 	// that modified the instance.
 	SetTags(map[string]string) error
 
+	// Tags returns the tags currently set on the instance.
+	Tags() map[string]string
+
 	Auditor
 }
 
@@ -139,6 +142,11 @@ type Instance interface {
 	// ProviderRole returns the role of the instance.
 	ProviderRoleIdentifier() ProviderRoleIdentifier
 
+	// VersionDrift returns a description of each version recorded by the last
+	// successful provision that differs from the configured version. It returns
+	// nil if the instance is at the desired versions.
+	VersionDrift() []string
+
 	// Creator
 	PreCreate(req *route.Request) route.Response
 	Create(req *route.Request) route.Response
@@ -174,6 +182,11 @@ type Instance interface {
 	Replace(req *route.Request) route.Response
 	PostReplace(req *route.Request) route.Response
 
+	// Patcher
+	PrePatch(req *route.Request) route.Response
+	Patch(req *route.Request) route.Response
+	PostPatch(req *route.Request) route.Response
+
 	AuditOverride
 }
 
@@ -26,7 +26,10 @@
 
 package resource
 
-import "github.com/cisco/arc/pkg/route"
+import (
+	"github.com/cisco/arc/pkg/config"
+	"github.com/cisco/arc/pkg/route"
+)
 
 // StaticPod provides the interface to the static portion of the
 // pod. This information is provided via config file and is implemented
@@ -43,6 +46,7 @@ type StaticPod interface {
 	SecurityGroups() []string
 	Teams() []string
 	Count() int
+	Monitoring() config.Monitoring
 }
 
 // Pod provides the resource interface used for the common pod
@@ -124,6 +128,11 @@ type Pod interface {
 	Replace(req *route.Request) route.Response
 	PostReplace(req *route.Request) route.Response
 
+	// Patcher
+	PrePatch(req *route.Request) route.Response
+	Patch(req *route.Request) route.Response
+	PostPatch(req *route.Request) route.Response
+
 	// Auditor
 	AuditOverride
 }
@@ -42,45 +42,63 @@ const (
 	Replace
 	Destroy
 	Audit
+	Maintenance
+	Patch
+	Compliance
+	Grant
+	RevokeExpired
+	Issue
 )
 
 var c2s = map[Command][]string{
-	Load:      {"load"},
-	Help:      {"help"},
-	Config:    {"config"},
-	Info:      {"info", "show", "list"},
-	Create:    {"create"},
-	Provision: {"provision", "refresh", "update"},
-	Start:     {"start"},
-	Stop:      {"stop"},
-	Restart:   {"restart", "reboot"},
-	Replace:   {"replace", "upgrade"},
-	Destroy:   {"destroy", "delete", "nuke"},
-	Audit:     {"audit"},
+	Load:          {"load"},
+	Help:          {"help"},
+	Config:        {"config"},
+	Info:          {"info", "show", "list"},
+	Create:        {"create"},
+	Provision:     {"provision", "refresh", "update"},
+	Start:         {"start"},
+	Stop:          {"stop"},
+	Restart:       {"restart", "reboot"},
+	Replace:       {"replace", "upgrade"},
+	Destroy:       {"destroy", "delete", "nuke"},
+	Audit:         {"audit"},
+	Maintenance:   {"maintenance"},
+	Patch:         {"patch"},
+	Compliance:    {"compliance"},
+	Grant:         {"grant"},
+	RevokeExpired: {"revoke-expired"},
+	Issue:         {"issue"},
 }
 
 var s2c = map[string]Command{
-	"":          None,
-	"load":      Load,
-	"help":      Help,
-	"config":    Config,
-	"info":      Info,
-	"show":      Info,
-	"list":      Info,
-	"create":    Create,
-	"provision": Provision,
-	"refresh":   Provision,
-	"update":    Provision,
-	"start":     Start,
-	"stop":      Stop,
-	"restart":   Restart,
-	"reboot":    Restart,
-	"replace":   Replace,
-	"upgrade":   Replace,
-	"destroy":   Destroy,
-	"delete":    Destroy,
-	"nuke":      Destroy,
-	"audit":     Audit,
+	"":               None,
+	"load":           Load,
+	"help":           Help,
+	"config":         Config,
+	"info":           Info,
+	"show":           Info,
+	"list":           Info,
+	"create":         Create,
+	"provision":      Provision,
+	"refresh":        Provision,
+	"update":         Provision,
+	"start":          Start,
+	"stop":           Stop,
+	"restart":        Restart,
+	"reboot":         Restart,
+	"replace":        Replace,
+	"upgrade":        Replace,
+	"destroy":        Destroy,
+	"delete":         Destroy,
+	"nuke":           Destroy,
+	"audit":          Audit,
+	"maintenance":    Maintenance,
+	"patch":          Patch,
+	"compliance":     Compliance,
+	"grant":          Grant,
+	"revoke-expired": RevokeExpired,
+	"issue":          Issue,
 }
 
 func (c Command) String() string {
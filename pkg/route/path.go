@@ -36,6 +36,10 @@ func NewPath() *Path {
 	}
 }
 
+func (p *Path) Get() []string {
+	return p.path
+}
+
 func (p *Path) Top() string {
 	s := ""
 	if len(p.path) > 0 {
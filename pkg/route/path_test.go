@@ -118,3 +118,20 @@ func TestPathAppend(t *testing.T) {
 		t.Errorf("Expected top to be %q, got %q\n", "one", s)
 	}
 }
+
+func TestPathGet(t *testing.T) {
+	p := NewPath()
+	if p == nil {
+		t.Fatalf("Failed to create new Path\n")
+	}
+
+	if len(p.Get()) != 0 {
+		t.Errorf("Expected empty path, got %q\n", p.Get())
+	}
+
+	p.Append("one").Append("two")
+	s := p.Get()
+	if len(s) != 2 || s[0] != "one" || s[1] != "two" {
+		t.Errorf("Expected path [one two], got %q\n", s)
+	}
+}
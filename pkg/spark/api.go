@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package spark
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/jbogarin/go-cisco-spark/ciscospark"
+)
+
+// apiURL is the spark REST API. Threaded replies and file uploads are posted
+// to it directly since the spark library doesn't support them.
+var apiURL = "https://api.ciscospark.com/v1/"
+
+// Room is a spark room the token has access to.
+type Room struct {
+	Id    string
+	Title string
+	Type  string
+}
+
+// Rooms returns the rooms the token has access to.
+func Rooms(token string) ([]*Room, error) {
+	if token == "" {
+		return nil, sparkError{"No token available for use"}
+	}
+	sparkClient := ciscospark.NewClient(&http.Client{Timeout: 30 * time.Second})
+	sparkClient.Authorization = "Bearer " + token
+	rooms, _, err := sparkClient.Rooms.Get(&ciscospark.RoomQueryParams{Max: 1000})
+	if err != nil {
+		return nil, err
+	}
+	r := []*Room{}
+	for _, v := range rooms {
+		r = append(r, &Room{Id: v.ID, Title: v.Title, Type: v.Type})
+	}
+	return r, nil
+}
+
+// Post posts the message to the room, in the thread of the parent message
+// unless parent is empty. It returns the id of the posted message, which
+// replies use as their parent.
+func (c *Client) Post(text, parent string) (string, error) {
+	if text == "" {
+		return "", sparkError{"Nothing to send to spark"}
+	}
+	m := map[string]string{"roomId": c.room}
+	if parent != "" {
+		m["parentId"] = parent
+	}
+	if c.messageType == Text {
+		m["text"] = text
+	} else {
+		m["markdown"] = text
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return c.post("application/json", b)
+}
+
+// Attach posts the content read from r as a file attachment with the given
+// name, with an optional message and in the thread of the parent message
+// unless parent is empty. It returns the id of the posted message.
+func (c *Client) Attach(name string, r io.Reader, text, parent string) (string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fields := map[string]string{"roomId": c.room, "parentId": parent}
+	if c.messageType == Text {
+		fields["text"] = text
+	} else {
+		fields["markdown"] = text
+	}
+	for k, v := range fields {
+		if v == "" {
+			continue
+		}
+		if err := w.WriteField(k, v); err != nil {
+			return "", err
+		}
+	}
+	f, err := w.CreateFormFile("files", name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return c.post(w.FormDataContentType(), body.Bytes())
+}
+
+// post posts the body to the messages endpoint, retrying transient failures,
+// and returns the id of the posted message. Once a successful response is
+// received the post isn't retried, even if the response can't be read, as
+// that would post the message again.
+func (c *Client) post(contentType string, body []byte) (string, error) {
+	var posted struct {
+		Id string `json:"id"`
+	}
+	req, err := http.NewRequest("POST", apiURL+"messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", contentType)
+	err = retry(func() (*ciscospark.Response, error) {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		r := &ciscospark.Response{Response: resp}
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return r, err
+		}
+		if resp.StatusCode/100 != 2 {
+			return r, fmt.Errorf("Spark post failed: %s %s", resp.Status, bytes.TrimSpace(b))
+		}
+		return r, json.Unmarshal(b, &posted)
+	})
+	if err != nil {
+		return "", err
+	}
+	return posted.Id, nil
+}
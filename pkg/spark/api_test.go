@@ -0,0 +1,172 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package spark
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPost(t *testing.T) {
+	var got map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(`{"id": "msg1"}`))
+	}))
+	defer ts.Close()
+	defer func(u string) { apiURL = u }(apiURL)
+	apiURL = ts.URL + "/"
+
+	c, err := New("token", "room1", Text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := c.Post("hello", "parent1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "msg1" {
+		t.Errorf("Expected id msg1, got %q", id)
+	}
+	if got["roomId"] != "room1" || got["parentId"] != "parent1" || got["text"] != "hello" {
+		t.Errorf("Unexpected message %v", got)
+	}
+	if _, err := c.Post("", ""); err == nil {
+		t.Error("Expected an error posting an empty message")
+	}
+}
+
+func TestAttach(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("roomId") != "room1" || r.FormValue("markdown") != "report" {
+			t.Errorf("Unexpected form %v", r.MultipartForm)
+		}
+		if r.FormValue("parentId") != "" {
+			t.Error("Unexpected parentId")
+		}
+		f, h, err := r.FormFile("files")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(f)
+		if h.Filename != "report.txt" || string(b) != "contents" {
+			t.Errorf("Unexpected file %s: %q", h.Filename, b)
+		}
+		w.Write([]byte(`{"id": "msg2"}`))
+	}))
+	defer ts.Close()
+	defer func(u string) { apiURL = u }(apiURL)
+	apiURL = ts.URL + "/"
+
+	c, err := New("token", "room1", Html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := c.Attach("report.txt", strings.NewReader("contents"), "report", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "msg2" {
+		t.Errorf("Expected id msg2, got %q", id)
+	}
+}
+
+func TestPostFailure(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "bad room", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+	defer func(u string) { apiURL = u }(apiURL)
+	apiURL = ts.URL + "/"
+
+	c, err := New("token", "room1", Text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Post("hello", ""); err == nil {
+		t.Error("Expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+}
+
+func TestPostRetry(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var m map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil || m["text"] != "hello" {
+			t.Errorf("Unexpected message %v on attempt %d", m, calls)
+		}
+		switch calls {
+		case 1:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case 2:
+			w.Write([]byte(`{"id": "msg3"}`))
+		default:
+			w.Write([]byte(`not json`))
+		}
+	}))
+	defer ts.Close()
+	defer func(u string) { apiURL = u }(apiURL)
+	apiURL = ts.URL + "/"
+	defer func(d time.Duration) { backoff = d }(backoff)
+	backoff = time.Millisecond
+
+	c, err := New("token", "room1", Text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := c.Post("hello", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "msg3" || calls != 2 {
+		t.Errorf("Expected msg3 after 2 attempts, got %q after %d", id, calls)
+	}
+
+	// A successful post whose response can't be parsed isn't posted again.
+	if _, err := c.Post("hello", ""); err == nil {
+		t.Error("Expected an error")
+	}
+	if calls != 3 {
+		t.Errorf("Expected a single attempt, got %d", calls-2)
+	}
+}
@@ -27,10 +27,9 @@
 package spark
 
 import (
-	"crypto/tls"
 	"fmt"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/jbogarin/go-cisco-spark/ciscospark"
@@ -49,6 +48,8 @@ func (s sparkError) Error() string {
 type Client struct {
 	room        string
 	messageType messageType
+	token       string
+	httpClient  *http.Client
 	sparkClient *ciscospark.Client
 }
 
@@ -67,17 +68,15 @@ func New(token, room string, messageType messageType) (*Client, error) {
 	if room == "" {
 		return nil, sparkError{"No room to message"}
 	}
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
+	client := &http.Client{Timeout: 30 * time.Second}
 	sparkClient := ciscospark.NewClient(client)
 	sparkClient.Authorization = "Bearer " + token
 
 	return &Client{
 		room:        room,
 		messageType: messageType,
+		token:       token,
+		httpClient:  client,
 		sparkClient: sparkClient,
 	}, nil
 }
@@ -113,19 +112,59 @@ func (c *Client) Write(p []byte) (int, error) {
 	case Markdown, Html:
 		m.MarkDown = s
 	}
-	var err error
-	for i := 0; i < 3; i++ {
-		_, _, err = c.sparkClient.Messages.Post(m)
-		switch err {
-		case nil:
-			return len(p), nil
-		default:
-			if strings.Contains(err.Error(), " 502 ") {
-				log.Debug("Retrying the spark message")
-				time.Sleep(3 * time.Second)
-				continue
-			}
+	err := retry(func() (*ciscospark.Response, error) {
+		_, resp, err := c.sparkClient.Messages.Post(m)
+		return resp, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// retry calls post until it succeeds, fails with an error that isn't
+// transient, or has been attempted maxAttempts times.
+func retry(post func() (*ciscospark.Response, error)) error {
+	for attempt := 1; ; attempt++ {
+		resp, err := post()
+		if err == nil {
+			return nil
 		}
+		if attempt == maxAttempts || !transient(resp) {
+			return err
+		}
+		d := backoff << uint(attempt-1)
+		if ra := retryAfter(resp); ra > d {
+			d = ra
+		}
+		log.Debug("Retrying the spark message in %s: %s", d, err.Error())
+		time.Sleep(d)
+	}
+}
+
+// maxAttempts is the number of times a message is posted before giving up,
+// and backoff the delay before the first retry, doubled for each retry.
+const maxAttempts = 5
+
+var backoff = time.Second
+
+// transient returns true if the post failed without a response, or the
+// response status indicates a failure worth retrying.
+func transient(resp *ciscospark.Response) bool {
+	if resp == nil || resp.Response == nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter returns the delay requested by the Retry-After header.
+func retryAfter(resp *ciscospark.Response) time.Duration {
+	if resp == nil || resp.Response == nil {
+		return 0
+	}
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil {
+		return 0
 	}
-	return 0, err
+	return time.Duration(secs) * time.Second
 }
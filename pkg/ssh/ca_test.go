@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package ssh
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestCA(t *testing.T) {
+	b, err := NewCAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := ParseCA(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseCA(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca.PublicKey() != again.PublicKey() {
+		t.Fatal("Expected the certificate authority to be parsed to the same key")
+	}
+	if _, err := ParseCA([]byte("not a key")); err == nil {
+		t.Error("Expected a malformed key to fail")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ca.Sign(pub, "alice", nil, time.Hour); err == nil {
+		t.Error("Expected a certificate without principals to fail")
+	}
+	signed, cert, err := ca.Sign(pub, "alice", []string{"team:ops"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(signed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parsed.(*ssh.Certificate); !ok {
+		t.Fatalf("Expected a certificate, got %T", parsed)
+	}
+
+	caPub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(ca.PublicKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return string(auth.Marshal()) == string(caPub.Marshal())
+		},
+	}
+	if err := checker.CheckCert("team:ops", cert); err != nil {
+		t.Errorf("Expected the certificate to be valid for team:ops, got %s", err)
+	}
+	if err := checker.CheckCert("team:dev", cert); err == nil {
+		t.Error("Expected the certificate to be invalid for team:dev")
+	}
+	checker.Clock = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if err := checker.CheckCert("team:ops", cert); err == nil {
+		t.Error("Expected the certificate to expire")
+	}
+}
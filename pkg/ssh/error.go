@@ -26,6 +26,8 @@
 
 package ssh
 
+import "golang.org/x/crypto/ssh"
+
 type ClientError struct {
 	string
 }
@@ -33,3 +35,13 @@ type ClientError struct {
 func (e ClientError) Error() string {
 	return e.string
 }
+
+// ExitStatus returns the exit status of a remote command that exited with a
+// non-zero status. The boolean is false if err wasn't caused by the remote
+// command's exit status.
+func ExitStatus(err error) (int, bool) {
+	if e, ok := err.(*ssh.ExitError); ok {
+		return e.ExitStatus(), true
+	}
+	return 0, false
+}
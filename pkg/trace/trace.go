@@ -0,0 +1,279 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+// Package trace records timing spans for a run so the time spent routing
+// requests, calling providers, running commands and waiting on resources
+// can be summarized at the end of the run.
+//
+// Spans nest in the order they are started and ended, which matches the way
+// arc, amp and audit route requests one at a time.
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cisco/arc/pkg/env"
+)
+
+// Span is the time taken by a named piece of work.
+type Span struct {
+	Category string
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+	Children []*Span
+
+	parent *Span
+	ended  bool
+}
+
+var lock sync.Mutex
+var root *Span
+var current *Span
+
+// The settings given by ParseFlags or the environment.
+var (
+	printSummary bool
+	writeChrome  bool
+	dir          string
+)
+
+// ParseFlags removes the tracing flags from args, returning the remaining args.
+// The flags are:
+//
+//	--timing  print the timing summary at the end of the run
+//	--trace   also write a Chrome trace-event file, trace.json, into the run directory
+//
+// The environment variables timing=yes and trace=yes can be used instead.
+func ParseFlags(args []string) []string {
+	remaining := []string{}
+	for _, arg := range args {
+		switch arg {
+		case "--timing":
+			printSummary = true
+		case "--trace":
+			writeChrome = true
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+	return remaining
+}
+
+// Init starts the root span of the run. Spans started before Init are not recorded.
+func Init(appname string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if root != nil {
+		return
+	}
+	if os.Getenv("timing") == "yes" {
+		printSummary = true
+	}
+	if os.Getenv("trace") == "yes" {
+		writeChrome = true
+	}
+	dir = env.Lookup(strings.ToUpper(appname))
+	root = &Span{Category: "run", Name: strings.Join(append([]string{appname}, os.Args[1:]...), " "), Start: time.Now()}
+	current = root
+}
+
+// Start starts a span as a child of the current span. It returns nil,
+// which is safe to End, when tracing hasn't been initialized.
+func Start(category, name string) *Span {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if current == nil {
+		return nil
+	}
+	s := &Span{Category: category, Name: name, Start: time.Now(), parent: current}
+	current.Children = append(current.Children, s)
+	current = s
+	return s
+}
+
+// End ends the span. Spans that haven't been ended below it are ended with it.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	s.end(time.Now())
+}
+
+func (s *Span) end(t time.Time) {
+	if s.ended {
+		return
+	}
+	for _, c := range s.Children {
+		c.end(t)
+	}
+	s.Duration = t.Sub(s.Start)
+	s.ended = true
+	if current == s {
+		current = s.parent
+	}
+}
+
+// Fini ends the root span, writes the Chrome trace when asked to and returns
+// the timing summary. The summary is printed to stdout when asked to.
+func Fini() (string, error) {
+	lock.Lock()
+	if root == nil {
+		lock.Unlock()
+		return "", nil
+	}
+	r := root
+	r.end(time.Now())
+	root, current = nil, nil
+	lock.Unlock()
+
+	summary := Summary(r)
+	if printSummary {
+		fmt.Printf("\nTiming:\n%s", summary)
+	}
+	if writeChrome {
+		if err := WriteChrome(filepath.Join(dir, "trace.json"), r); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+//---------------------------------------------------------------------------
+
+// node is the aggregate of the sibling spans with the same category and name.
+type node struct {
+	name     string
+	count    int
+	duration time.Duration
+	children []*node
+}
+
+func aggregate(spans []*Span) []*node {
+	nodes := []*node{}
+	byName := map[string]*node{}
+	children := map[*node][]*Span{}
+	for _, s := range spans {
+		key := s.Category + " " + s.Name
+		n := byName[key]
+		if n == nil {
+			n = &node{name: key}
+			byName[key] = n
+			nodes = append(nodes, n)
+		}
+		n.count++
+		n.duration += s.Duration
+		children[n] = append(children[n], s.Children...)
+	}
+	for _, n := range nodes {
+		n.children = aggregate(children[n])
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].duration > nodes[j].duration })
+	return nodes
+}
+
+// Summary returns a tree of where the time went under the given span. Sibling
+// spans with the same name are combined and spans taking less than 1% of the
+// total time are left out.
+func Summary(s *Span) string {
+	var b bytes.Buffer
+	min := s.Duration / 100
+	var write func(n *node, depth int)
+	write = func(n *node, depth int) {
+		count := ""
+		if n.count > 1 {
+			count = fmt.Sprintf(" (x%d)", n.count)
+		}
+		fmt.Fprintf(&b, "%10s  %s%s%s\n", n.duration.Round(time.Millisecond), strings.Repeat("  ", depth), n.name, count)
+		for _, c := range n.children {
+			if c.duration >= min {
+				write(c, depth+1)
+			}
+		}
+	}
+	for _, n := range aggregate([]*Span{s}) {
+		write(n, 0)
+	}
+	return b.String()
+}
+
+// event is a Chrome trace-event complete event. Times are in microseconds.
+type event struct {
+	Name     string `json:"name"`
+	Category string `json:"cat"`
+	Phase    string `json:"ph"`
+	Time     int64  `json:"ts"`
+	Duration int64  `json:"dur"`
+	Pid      int    `json:"pid"`
+	Tid      int    `json:"tid"`
+}
+
+// WriteChrome writes the span and its children to the named file in the
+// Chrome trace-event format, which chrome://tracing and Perfetto can load.
+func WriteChrome(name string, s *Span) error {
+	data, err := json.Marshal(chrome(s))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, data, 0644)
+}
+
+func chrome(s *Span) interface{} {
+	events := []event{}
+	var add func(s *Span)
+	add = func(s *Span) {
+		events = append(events, event{
+			Name:     s.Name,
+			Category: s.Category,
+			Phase:    "X",
+			Time:     s.Start.UnixNano() / 1000,
+			Duration: int64(s.Duration / time.Microsecond),
+			Pid:      1,
+			Tid:      1,
+		})
+		for _, c := range s.Children {
+			add(c)
+		}
+	}
+	add(s)
+	return struct {
+		TraceEvents     []event `json:"traceEvents"`
+		DisplayTimeUnit string  `json:"displayTimeUnit"`
+	}{events, "ms"}
+}
@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package trace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotInitialized(t *testing.T) {
+	s := Start("route", "dc")
+	if s != nil {
+		t.Fatal("Expected no span before Init")
+	}
+	s.End()
+	if summary, err := Fini(); summary != "" || err != nil {
+		t.Errorf("Unexpected summary %q, %v", summary, err)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	Init("test")
+	r := root
+	dc := Start("route", "dc")
+	for i := 0; i < 3; i++ {
+		c := Start("aws", "ec2 DescribeInstances")
+		time.Sleep(2 * time.Millisecond)
+		c.End()
+	}
+	w := Start("wait", "instance")
+	// The unended child is ended with its parent.
+	Start("command", "puppet")
+	time.Sleep(2 * time.Millisecond)
+	w.End()
+	dc.End()
+	if current != r {
+		t.Fatal("Expected the root span to be current")
+	}
+	summary, err := Fini()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Children) != 1 || len(r.Children[0].Children) != 4 {
+		t.Fatalf("Unexpected tree %+v", r)
+	}
+	if p := r.Children[0].Children[3].Children[0]; !p.ended || p.Name != "puppet" {
+		t.Errorf("Expected the puppet span to be ended, got %+v", p)
+	}
+	for _, s := range []string{"route dc\n", "aws ec2 DescribeInstances (x3)\n", "wait instance\n", "command puppet\n"} {
+		if !strings.Contains(summary, s) {
+			t.Errorf("Expected %q in the summary:\n%s", s, summary)
+		}
+	}
+	if strings.Index(summary, "DescribeInstances") > strings.Index(summary, "wait instance") {
+		t.Errorf("Expected the longer span first:\n%s", summary)
+	}
+}
+
+func TestWriteChrome(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	Init("test")
+	r := root
+	Start("route", "dc").End()
+	Fini()
+
+	name := filepath.Join(dir, "trace.json")
+	if err := WriteChrome(name, r); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace struct {
+		TraceEvents []event
+	}
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.TraceEvents) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(trace.TraceEvents))
+	}
+	e := trace.TraceEvents[1]
+	if e.Name != "dc" || e.Category != "route" || e.Phase != "X" || e.Time < trace.TraceEvents[0].Time {
+		t.Errorf("Unexpected event %+v", e)
+	}
+}
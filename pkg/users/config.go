@@ -28,6 +28,7 @@ package users
 
 // The user management config.
 type config struct {
+	Comments          []string     `json:"comments,omitempty"`
 	UsersConfig       usersConfig  `json:"users"`
 	GroupsConfig      groupsConfig `json:"groups"`
 	TeamsConfig       teamsConfig  `json:"teams"`
@@ -39,15 +40,15 @@ type usersConfig []userConfig
 type userConfig struct {
 	Name    string   `json:"user"`
 	Groups  []string `json:"groups"`
-	SshKeys []string `json:"sshkeys"`
-	Remove  bool     `json:"remove"`
+	SshKeys SshKeys  `json:"sshkeys"`
+	Remove  bool     `json:"remove,omitempty"`
 }
 
 type groupsConfig []groupConfig
 
 type groupConfig struct {
 	Name   string `json:"group"`
-	Remove bool   `json:"remove"`
+	Remove bool   `json:"remove,omitempty"`
 }
 
 type teamsConfig []teamConfig
@@ -0,0 +1,148 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Grant is a time limited access grant of a user to a pod. Grants are kept
+// in a local grant store so they can be revoked once they expire.
+type Grant struct {
+	User      string    `json:"user"`
+	Pod       string    `json:"pod"`
+	Sudo      bool      `json:"sudo"`
+	GrantedBy string    `json:"granted_by"`
+	Granted   time.Time `json:"granted"`
+	Expires   time.Time `json:"expires"`
+}
+
+func (g *Grant) String() string {
+	access := "access"
+	if g.Sudo {
+		access = "sudo access"
+	}
+	return fmt.Sprintf("%s %s to pod %s until %s, granted by %s", g.User, access, g.Pod, g.Expires.Format(time.RFC3339), g.GrantedBy)
+}
+
+// Expired returns true if the grant has expired at time t.
+func (g *Grant) Expired(t time.Time) bool {
+	return !t.Before(g.Expires)
+}
+
+// Grants is the grant store of a datacenter.
+type Grants struct {
+	name   string
+	Grants []*Grant `json:"grants"`
+}
+
+// LoadGrants loads the named grant store. A missing store has no grants.
+func LoadGrants(name string) (*Grants, error) {
+	g := &Grants{name: name, Grants: []*Grant{}}
+	file, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(file, g); err != nil {
+		return nil, usersError{name + ": " + err.Error()}
+	}
+	return g, nil
+}
+
+// Add records the grant, replacing any grant of the same user to the same pod.
+func (g *Grants) Add(grant *Grant) {
+	g.Remove(grant)
+	g.Grants = append(g.Grants, grant)
+}
+
+// Remove removes any grant of the grant's user to the grant's pod.
+func (g *Grants) Remove(grant *Grant) {
+	grants := []*Grant{}
+	for _, v := range g.Grants {
+		if v.User != grant.User || v.Pod != grant.Pod {
+			grants = append(grants, v)
+		}
+	}
+	g.Grants = grants
+}
+
+// Active returns the grant of the user to the pod if it hasn't expired at
+// time t, or nil.
+func (g *Grants) Active(user, pod string, t time.Time) *Grant {
+	for _, v := range g.Grants {
+		if v.User == user && v.Pod == pod && !v.Expired(t) {
+			return v
+		}
+	}
+	return nil
+}
+
+// ActiveFor returns the grants to the pod which haven't expired at time t.
+func (g *Grants) ActiveFor(pod string, t time.Time) []*Grant {
+	active := []*Grant{}
+	for _, v := range g.Grants {
+		if v.Pod == pod && !v.Expired(t) {
+			active = append(active, v)
+		}
+	}
+	return active
+}
+
+// Expired returns the grants that have expired at time t.
+func (g *Grants) Expired(t time.Time) []*Grant {
+	expired := []*Grant{}
+	for _, v := range g.Grants {
+		if v.Expired(t) {
+			expired = append(expired, v)
+		}
+	}
+	return expired
+}
+
+// Save writes the grant store, replacing the previous one atomically.
+func (g *Grants) Save() error {
+	if err := os.MkdirAll(filepath.Dir(g.name), 0700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := g.name + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, g.name)
+}
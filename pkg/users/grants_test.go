@@ -0,0 +1,80 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGrants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "grants")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "grants", "dc.json")
+
+	g, err := LoadGrants(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Grants) != 0 {
+		t.Fatalf("expected a missing store to have no grants, got %d", len(g.Grants))
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	g.Add(&Grant{User: "bob", Pod: "db", Sudo: true, GrantedBy: "alice", Granted: now, Expires: now.Add(time.Hour)})
+	g.Add(&Grant{User: "bob", Pod: "db", GrantedBy: "alice", Granted: now, Expires: now.Add(-time.Minute)})
+	g.Add(&Grant{User: "carol", Pod: "web", GrantedBy: "alice", Granted: now, Expires: now.Add(time.Hour)})
+	if err := g.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err = LoadGrants(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Grants) != 2 {
+		t.Fatalf("expected a repeated grant to replace the previous one, got %d grants", len(g.Grants))
+	}
+	expired := g.Expired(now)
+	if len(expired) != 1 || expired[0].User != "bob" || expired[0].Sudo {
+		t.Fatalf("expected bob's replaced grant to have expired, got %v", expired)
+	}
+	if s := expired[0].String(); s != "bob access to pod db until "+now.Add(-time.Minute).Format(time.RFC3339)+", granted by alice" {
+		t.Errorf("unexpected grant description %q", s)
+	}
+
+	g.Remove(expired[0])
+	if len(g.Expired(now.Add(2*time.Hour))) != 1 {
+		t.Errorf("expected only carol's grant to remain, got %v", g.Grants)
+	}
+}
@@ -0,0 +1,193 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SshKey is an ssh key of a user. In users.json a key is either the key
+// itself, or an object with the key and the optional dates it was added
+// and expires, such as
+//
+//	{ "key": "ssh-ed25519 AAAA... alice@laptop", "added": "2024-01-15", "expires": "2025-01-15" }
+//
+// A date expires at the end of the day. RFC3339 times are also accepted.
+type SshKey struct {
+	Key     string `json:"key"`
+	Added   string `json:"added,omitempty"`
+	Expires string `json:"expires,omitempty"`
+}
+
+func (k *SshKey) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*k = SshKey{Key: s}
+		return nil
+	}
+	type sshKey SshKey
+	v := sshKey{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*k = SshKey(v)
+	return nil
+}
+
+// MarshalJSON writes keys without dates as plain strings.
+func (k SshKey) MarshalJSON() ([]byte, error) {
+	if k.Added == "" && k.Expires == "" {
+		return json.Marshal(k.Key)
+	}
+	type sshKey SshKey
+	return json.Marshal(sshKey(k))
+}
+
+// ExpiresAt returns the time the key expires, or the zero time if it doesn't.
+func (k *SshKey) ExpiresAt() (time.Time, error) {
+	if k.Expires == "" {
+		return time.Time{}, nil
+	}
+	return parseKeyDate(k.Expires, true)
+}
+
+// Expired returns true if the key has expired at time t. Keys with malformed
+// expiry dates are treated as expired.
+func (k *SshKey) Expired(t time.Time) bool {
+	expires, err := k.ExpiresAt()
+	if err != nil {
+		return true
+	}
+	return !expires.IsZero() && !t.Before(expires)
+}
+
+// validate checks the key's dates.
+func (k *SshKey) validate() error {
+	var added time.Time
+	if k.Added != "" {
+		var err error
+		if added, err = parseKeyDate(k.Added, false); err != nil {
+			return err
+		}
+	}
+	expires, err := k.ExpiresAt()
+	if err != nil {
+		return err
+	}
+	if !added.IsZero() && !expires.IsZero() && !added.Before(expires) {
+		return fmt.Errorf("expires %s before it was added %s", k.Expires, k.Added)
+	}
+	return nil
+}
+
+// parseKeyDate parses a date or an RFC3339 time. The end of the day is
+// returned for a date if end is true.
+func parseKeyDate(s string, end bool) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected a date such as 2006-01-02", s)
+	}
+	return t, nil
+}
+
+// SshKeys are the ssh keys of a user.
+type SshKeys []SshKey
+
+// Strings returns every key, expired or not.
+func (s SshKeys) Strings() []string {
+	keys := []string{}
+	for _, k := range s {
+		keys = append(keys, k.Key)
+	}
+	return keys
+}
+
+// Active returns the keys that haven't expired at time t.
+func (s SshKeys) Active(t time.Time) []string {
+	keys := []string{}
+	for i := range s {
+		if !s[i].Expired(t) {
+			keys = append(keys, s[i].Key)
+		}
+	}
+	return keys
+}
+
+// KeyExpiry is a user's ssh key that has expired or is about to expire.
+type KeyExpiry struct {
+	User    string
+	Key     string
+	Expires time.Time
+	Expired bool
+
+	// date is the expiry as configured.
+	date string
+}
+
+func (e *KeyExpiry) String() string {
+	if e.Expired {
+		return fmt.Sprintf("ssh key %s of %s expired %s", keyName(e.Key), e.User, e.date)
+	}
+	return fmt.Sprintf("ssh key %s of %s expires %s", keyName(e.Key), e.User, e.date)
+}
+
+// ExpiringKeys returns the keys of the users that aren't removed which have
+// expired at time t or expire within the given number of days, sorted by
+// user and expiry. Keys with malformed expiry dates are left to Lint.
+func ExpiringKeys(t time.Time, days int) []*KeyExpiry {
+	horizon := t.AddDate(0, 0, days)
+	expiring := []*KeyExpiry{}
+	for name, u := range Users {
+		if u.Remove {
+			continue
+		}
+		for i := range u.SshKeys {
+			k := &u.SshKeys[i]
+			expires, err := k.ExpiresAt()
+			if err != nil || expires.IsZero() || !expires.Before(horizon) {
+				continue
+			}
+			expiring = append(expiring, &KeyExpiry{User: name, Key: k.Key, Expires: expires, Expired: !t.Before(expires), date: k.Expires})
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		if expiring[i].User != expiring[j].User {
+			return expiring[i].User < expiring[j].User
+		}
+		return expiring[i].Expires.Before(expiring[j].Expires)
+	})
+	return expiring
+}
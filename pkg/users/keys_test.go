@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSshKeys(t *testing.T) {
+	cfg := &userConfig{}
+	if err := json.Unmarshal([]byte(`{
+  "user": "alice",
+  "sshkeys": [
+    "ssh-ed25519 AAAA1 alice@old",
+    { "key": "ssh-ed25519 AAAA2 alice@laptop", "added": "2025-01-01", "expires": "2026-10-31" },
+    { "key": "ssh-ed25519 AAAA3 alice@desktop", "expires": "2026-10-01T12:00:00Z" }
+  ]
+}`), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.SshKeys) != 3 || cfg.SshKeys[1].Added != "2025-01-01" {
+		t.Fatalf("unexpected keys %+v", cfg.SshKeys)
+	}
+
+	// A date expires at the end of the day.
+	now := time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC)
+	if active := strings.Join(cfg.SshKeys.Active(now), ","); active != "ssh-ed25519 AAAA1 alice@old,ssh-ed25519 AAAA2 alice@laptop" {
+		t.Errorf("unexpected active keys %q", active)
+	}
+	if active := cfg.SshKeys.Active(now.Add(time.Hour)); len(active) != 1 {
+		t.Errorf("expected one active key the next day, got %v", active)
+	}
+
+	b, err := json.Marshal(cfg.SshKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), `["ssh-ed25519 AAAA1 alice@old",{"key":"ssh-ed25519 AAAA2 alice@laptop","added":"2025-01-01","expires":"2026-10-31"}`) {
+		t.Errorf("expected keys without dates to be written as strings, got %s", b)
+	}
+
+	for _, k := range []SshKey{
+		{Key: "k", Expires: "31/10/2026"},
+		{Key: "k", Added: "2026-11-01", Expires: "2026-10-31"},
+	} {
+		if err := k.validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", k)
+		}
+	}
+	if !(&SshKey{Key: "k", Expires: "soon"}).Expired(now) {
+		t.Error("expected a malformed expiry to be expired")
+	}
+}
+
+func TestExpiringKeys(t *testing.T) {
+	Users = map[string]*User{
+		"alice": {userConfig{Name: "alice", SshKeys: SshKeys{
+			{Key: "a1", Expires: "2026-11-30"},
+			{Key: "a2", Expires: "2026-10-20"},
+			{Key: "a3"},
+		}}},
+		"bob":   {userConfig{Name: "bob", SshKeys: SshKeys{{Key: "b1", Expires: "2026-10-01"}}}},
+		"carol": {userConfig{Name: "carol", Remove: true, SshKeys: SshKeys{{Key: "c1", Expires: "2026-10-01"}}}},
+	}
+	defer func() { Users = nil }()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	got := []string{}
+	for _, e := range ExpiringKeys(now, 30) {
+		got = append(got, e.User+" "+e.Key+" "+e.Expires.Format("2006-01-02"))
+	}
+	if s := strings.Join(got, ","); s != "alice a2 2026-10-21,bob b1 2026-10-02" {
+		t.Errorf("unexpected expiring keys %q", s)
+	}
+	expiring := ExpiringKeys(now, 60)
+	if len(expiring) != 3 || expiring[1].Key != "a1" || expiring[1].Expired || !expiring[2].Expired {
+		t.Fatalf("unexpected expiring keys %+v", expiring)
+	}
+	if s := expiring[2].String(); s != "ssh key unreadable key of bob expired 2026-10-01" {
+		t.Errorf("unexpected description %q", s)
+	}
+}
@@ -0,0 +1,206 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+)
+
+// LDAPConfig is the configuration of the LDAP directory users.json is synced
+// from, kept in etc/arc/ldap.json. The url is an ldaps:// url, or an ldap://
+// url of a server supporting StartTLS. Users are the entries matching the user
+// filter under the user base, teams are the groups matching the group filter
+// under the group base.
+type LDAPConfig struct {
+	URL          string `json:"url"`
+	BindDN       string `json:"bind_dn"`
+	PasswordFile string `json:"password_file"`
+	UserBase     string `json:"user_base"`
+	UserFilter   string `json:"user_filter"`
+	UserAttr     string `json:"user_attr"`
+	KeyAttr      string `json:"key_attr"`
+	GroupBase    string `json:"group_base"`
+	GroupFilter  string `json:"group_filter"`
+	GroupAttr    string `json:"group_attr"`
+	MemberAttr   string `json:"member_attr"`
+
+	// Teams maps LDAP groups to team names. Only the mapped groups are synced
+	// if it is set, otherwise every group is synced to the team of the same name.
+	Teams map[string]string `json:"teams"`
+}
+
+// LoadLDAPConfig loads the named LDAP configuration, filling in the posixAccount
+// and posixGroup defaults.
+func LoadLDAPConfig(name string) (*LDAPConfig, error) {
+	file, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &LDAPConfig{}
+	if err := json.Unmarshal(file, cfg); err != nil {
+		return nil, usersError{name + ": " + err.Error()}
+	}
+	defaults := []struct {
+		v *string
+		d string
+	}{
+		{&cfg.UserFilter, "(objectClass=posixAccount)"},
+		{&cfg.UserAttr, "uid"},
+		{&cfg.KeyAttr, "sshPublicKey"},
+		{&cfg.GroupFilter, "(objectClass=posixGroup)"},
+		{&cfg.GroupAttr, "cn"},
+		{&cfg.MemberAttr, "memberUid"},
+	}
+	for _, d := range defaults {
+		if *d.v == "" {
+			*d.v = d.d
+		}
+	}
+	if cfg.UserBase == "" || cfg.GroupBase == "" {
+		return nil, usersError{name + ": user_base and group_base are required"}
+	}
+	if u := strings.ToLower(cfg.URL); !strings.HasPrefix(u, "ldap://") && !strings.HasPrefix(u, "ldaps://") {
+		return nil, usersError{name + ": url is required and must be an ldap:// or ldaps:// url"}
+	}
+	return cfg, nil
+}
+
+// Entry is an LDAP directory entry. Attribute names are lower case.
+type Entry struct {
+	DN    string
+	Attrs map[string][]string
+}
+
+// Get returns the values of the named attribute.
+func (e *Entry) Get(attr string) []string {
+	return e.Attrs[strings.ToLower(attr)]
+}
+
+// Directory is an LDAP directory that can be searched.
+type Directory interface {
+	Search(base, filter string, attrs ...string) ([]*Entry, error)
+}
+
+// ldapSearch searches an LDAP server with the OpenLDAP ldapsearch client.
+type ldapSearch struct {
+	*LDAPConfig
+}
+
+// NewLDAPDirectory returns the directory of the configured LDAP server.
+func NewLDAPDirectory(cfg *LDAPConfig) Directory {
+	return &ldapSearch{cfg}
+}
+
+func (l *ldapSearch) Search(base, filter string, attrs ...string) ([]*Entry, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("ldapsearch", l.args(base, filter, attrs)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ldapsearch %s failed: %s %s", filter, err.Error(), strings.TrimSpace(stderr.String()))
+	}
+	return parseLDIF(bytes.NewReader(out))
+}
+
+// args returns the ldapsearch arguments of a search. Simple binds send the
+// password in the clear, so ldap urls require StartTLS to succeed, with -ZZ,
+// and ldaps urls are already encrypted.
+func (l *ldapSearch) args(base, filter string, attrs []string) []string {
+	args := []string{"-LLL", "-x", "-H", l.URL}
+	if strings.HasPrefix(strings.ToLower(l.URL), "ldap://") {
+		args = append(args, "-ZZ")
+	}
+	args = append(args, "-o", "ldif-wrap=no", "-b", base)
+	if l.BindDN != "" {
+		args = append(args, "-D", l.BindDN, "-y", l.PasswordFile)
+	}
+	args = append(args, filter)
+	return append(args, attrs...)
+}
+
+// parseLDIF parses the entries of an LDIF stream, as output by ldapsearch.
+func parseLDIF(r io.Reader) ([]*Entry, error) {
+	entries := []*Entry{}
+	lines := []string{}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		l := s.Text()
+		if strings.HasPrefix(l, " ") && len(lines) > 0 {
+			// A continuation of the previous line.
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		lines = append(lines, l)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	var e *Entry
+	for _, l := range lines {
+		if strings.HasPrefix(l, "#") || strings.HasPrefix(l, "version:") {
+			continue
+		}
+		if strings.TrimSpace(l) == "" {
+			e = nil
+			continue
+		}
+		i := strings.Index(l, ":")
+		if i < 1 {
+			return nil, fmt.Errorf("Malformed LDIF line %q", l)
+		}
+		attr, value := strings.ToLower(l[:i]), l[i+1:]
+		if strings.HasPrefix(value, ":") {
+			b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+			if err != nil {
+				return nil, fmt.Errorf("Malformed LDIF value of %s: %s", attr, err.Error())
+			}
+			value = string(b)
+		} else {
+			value = strings.TrimSpace(value)
+		}
+		if attr == "dn" {
+			e = &Entry{DN: value, Attrs: map[string][]string{}}
+			entries = append(entries, e)
+			continue
+		}
+		if e == nil {
+			return nil, fmt.Errorf("LDIF attribute %s is outside of an entry", attr)
+		}
+		e.Attrs[attr] = append(e.Attrs[attr], value)
+	}
+	return entries, nil
+}
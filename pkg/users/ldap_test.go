@@ -0,0 +1,219 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLDAPSearchArgs(t *testing.T) {
+	tests := []struct {
+		cfg  LDAPConfig
+		args []string
+	}{
+		{
+			LDAPConfig{URL: "ldap://ldap.example.com"},
+			[]string{"-LLL", "-x", "-H", "ldap://ldap.example.com", "-ZZ", "-o", "ldif-wrap=no", "-b", "ou=people,dc=example,dc=com", "(objectClass=posixAccount)", "uid", "sshPublicKey"},
+		},
+		{
+			LDAPConfig{URL: "ldaps://ldap.example.com", BindDN: "cn=arc,dc=example,dc=com", PasswordFile: "/etc/arc/ldap.pw"},
+			[]string{"-LLL", "-x", "-H", "ldaps://ldap.example.com", "-o", "ldif-wrap=no", "-b", "ou=people,dc=example,dc=com", "-D", "cn=arc,dc=example,dc=com", "-y", "/etc/arc/ldap.pw", "(objectClass=posixAccount)", "uid", "sshPublicKey"},
+		},
+	}
+	for _, test := range tests {
+		l := &ldapSearch{&test.cfg}
+		args := l.args("ou=people,dc=example,dc=com", "(objectClass=posixAccount)", []string{"uid", "sshPublicKey"})
+		if !reflect.DeepEqual(args, test.args) {
+			t.Errorf("expected %q, got %q", test.args, args)
+		}
+	}
+}
+
+func TestLoadLDAPConfigURL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ldap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "ldap.json")
+	for url, ok := range map[string]bool{
+		"":                         false,
+		"http://ldap.example.com":  false,
+		"ldap://ldap.example.com":  true,
+		"LDAPS://ldap.example.com": true,
+	} {
+		if err := ioutil.WriteFile(name, []byte(fmt.Sprintf(`{"url": %q, "user_base": "ou=people", "group_base": "ou=groups"}`, url)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadLDAPConfig(name); (err == nil) != ok {
+			t.Errorf("url %q: expected ok %v, got %v", url, ok, err)
+		}
+	}
+}
+
+// ldifDirectory is a directory read from an LDIF file, standing in for an LDAP
+// server when testing a sync.
+type ldifDirectory struct {
+	entries []*Entry
+}
+
+// newLDIFDirectory returns the directory of the entries in the named LDIF file.
+func newLDIFDirectory(name string) (Directory, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := parseLDIF(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err.Error())
+	}
+	return &ldifDirectory{entries}, nil
+}
+
+func (l *ldifDirectory) Search(base, filter string, attrs ...string) ([]*Entry, error) {
+	f, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	found := []*Entry{}
+	for _, e := range l.entries {
+		if inBase(e.DN, base) && f.match(e) {
+			found = append(found, e)
+		}
+	}
+	return found, nil
+}
+
+// inBase returns true if the dn is the base or is under it.
+func inBase(dn, base string) bool {
+	dn, base = strings.ToLower(dn), strings.ToLower(base)
+	return base == "" || dn == base || strings.HasSuffix(dn, ","+base)
+}
+
+// filter is a parsed LDAP search filter. Equality, presence and substring
+// matches combined with and, or and not are supported.
+type filter struct {
+	op      byte
+	attr    string
+	value   string
+	filters []*filter
+}
+
+func parseFilter(s string) (*filter, error) {
+	f, rest, err := parseFilterAt(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("Malformed LDAP filter %q", s)
+	}
+	return f, nil
+}
+
+func parseFilterAt(s string) (*filter, string, error) {
+	if !strings.HasPrefix(s, "(") || len(s) < 3 {
+		return nil, "", fmt.Errorf("Malformed LDAP filter %q", s)
+	}
+	switch s[1] {
+	case '&', '|', '!':
+		f := &filter{op: s[1]}
+		rest := s[2:]
+		for strings.HasPrefix(rest, "(") {
+			sub, r, err := parseFilterAt(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			f.filters = append(f.filters, sub)
+			rest = r
+		}
+		if !strings.HasPrefix(rest, ")") || len(f.filters) == 0 || (f.op == '!' && len(f.filters) != 1) {
+			return nil, "", fmt.Errorf("Malformed LDAP filter %q", s)
+		}
+		return f, rest[1:], nil
+	}
+	end := strings.Index(s, ")")
+	eq := strings.Index(s, "=")
+	if end < 0 || eq < 2 || eq > end {
+		return nil, "", fmt.Errorf("Malformed LDAP filter %q", s)
+	}
+	return &filter{op: '=', attr: strings.ToLower(s[1:eq]), value: s[eq+1 : end]}, s[end+1:], nil
+}
+
+func (f *filter) match(e *Entry) bool {
+	switch f.op {
+	case '&':
+		for _, s := range f.filters {
+			if !s.match(e) {
+				return false
+			}
+		}
+		return true
+	case '|':
+		for _, s := range f.filters {
+			if s.match(e) {
+				return true
+			}
+		}
+		return false
+	case '!':
+		return !f.filters[0].match(e)
+	}
+	for _, v := range e.Attrs[f.attr] {
+		if wildcardMatch(strings.ToLower(f.value), strings.ToLower(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches the value against a pattern where * matches any
+// sequence of characters.
+func wildcardMatch(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, p)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(p):]
+	}
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
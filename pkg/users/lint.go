@@ -0,0 +1,225 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Minimum key strengths accepted by Lint, in bits.
+const (
+	minRSABits   = 2048
+	minECDSABits = 256
+)
+
+// Lint validates the users configuration file, returning the problems found
+// in it. Unlike Init, the configuration is checked as written so every
+// problem is reported rather than just the first.
+func Lint(name string) ([]string, error) {
+	file, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config{}
+	if err := json.Unmarshal(file, cfg); err != nil {
+		return nil, usersError{name + ": " + err.Error()}
+	}
+	return lint(cfg), nil
+}
+
+func lint(cfg *config) []string {
+	problems := []string{}
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	groups := map[string]bool{}
+	for _, g := range cfg.GroupsConfig {
+		if groups[g.Name] {
+			add("group %s is defined more than once", g.Name)
+		}
+		groups[g.Name] = true
+	}
+
+	users := map[string]bool{}
+	keys := map[string][]string{}
+	for _, u := range cfg.UsersConfig {
+		if users[u.Name] {
+			add("user %s is defined more than once", u.Name)
+		}
+		users[u.Name] = true
+		for _, g := range u.Groups {
+			if !groups[g] {
+				add("user %s references undefined group %s", u.Name, g)
+			}
+		}
+		for i, key := range u.SshKeys {
+			if err := key.validate(); err != nil {
+				add("user %s ssh key %d %s", u.Name, i+1, err.Error())
+			}
+			pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.Key))
+			if err != nil {
+				add("user %s ssh key %d is malformed: %s", u.Name, i+1, err.Error())
+				continue
+			}
+			if weak := weakKey(pub); weak != "" {
+				add("user %s ssh key %s is too weak: %s", u.Name, ssh.FingerprintSHA256(pub), weak)
+			}
+			fp := ssh.FingerprintSHA256(pub)
+			if !contains(keys[fp], u.Name) {
+				keys[fp] = append(keys[fp], u.Name)
+			}
+		}
+	}
+	fingerprints := []string{}
+	for fp, owners := range keys {
+		if len(owners) > 1 {
+			fingerprints = append(fingerprints, fp)
+		}
+	}
+	sort.Strings(fingerprints)
+	for _, fp := range fingerprints {
+		add("ssh key %s is shared by users %s", fp, strings.Join(keys[fp], ", "))
+	}
+
+	teams := map[string][]string{}
+	for _, t := range cfg.TeamsConfig {
+		if _, ok := teams[t.Name]; ok {
+			add("team %s is defined more than once", t.Name)
+		}
+		if teams[t.Name] == nil {
+			teams[t.Name] = []string{}
+		}
+		for _, n := range t.Users {
+			if s := strings.Split(n, ":"); len(s) == 2 && s[0] == "team" {
+				teams[t.Name] = append(teams[t.Name], s[1])
+				continue
+			}
+			if !users[n] {
+				add("team %s references undefined user %s", t.Name, n)
+			}
+		}
+	}
+	for _, t := range cfg.TeamsConfig {
+		for _, s := range teams[t.Name] {
+			if _, ok := teams[s]; !ok {
+				add("team %s references undefined team %s", t.Name, s)
+			}
+		}
+	}
+	for _, cycle := range teamCycles(teams) {
+		add("teams are nested in a circle: %s", strings.Join(cycle, " -> "))
+	}
+	return problems
+}
+
+// weakKey returns why the public key is too weak, or "" if it is strong enough.
+func weakKey(pub ssh.PublicKey) string {
+	c, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return ""
+	}
+	switch k := c.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		if n := k.N.BitLen(); n < minRSABits {
+			return fmt.Sprintf("%d bit RSA, at least %d bits are required", n, minRSABits)
+		}
+	case *ecdsa.PublicKey:
+		if n := k.Curve.Params().BitSize; n < minECDSABits {
+			return fmt.Sprintf("%d bit ECDSA, at least %d bits are required", n, minECDSABits)
+		}
+	case *dsa.PublicKey:
+		return "DSA keys are not accepted"
+	}
+	return ""
+}
+
+// teamCycles returns the circles found in the team:name nesting of the
+// teams, each starting and ending with the same team.
+func teamCycles(teams map[string][]string) [][]string {
+	names := []string{}
+	for n := range teams {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	cycles := [][]string{}
+	path := []string{}
+
+	var visit func(string)
+	visit = func(n string) {
+		state[n] = visiting
+		path = append(path, n)
+		for _, s := range teams[n] {
+			switch state[s] {
+			case visiting:
+				for i, p := range path {
+					if p == s {
+						cycle := append([]string{}, path[i:]...)
+						cycles = append(cycles, append(cycle, s))
+						break
+					}
+				}
+			case 0:
+				if _, ok := teams[s]; ok {
+					visit(s)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = visited
+	}
+	for _, n := range names {
+		if state[n] == 0 {
+			visit(n)
+		}
+	}
+	return cycles
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
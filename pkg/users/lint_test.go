@@ -0,0 +1,107 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestLint(t *testing.T) {
+	good, shared := newKey(t, "alice@laptop"), newKey(t, "shared@laptop")
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(&weak.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+
+	cfg := `{
+  "users": [
+    { "user": "alice", "groups": [ "admin" ], "sshkeys": [ "` + good + `", "` + shared + `" ] },
+    { "user": "bob", "groups": [ "admin", "ops" ], "sshkeys": [ "` + shared + `", "` + weakKey + `" ] },
+    { "user": "carol", "sshkeys": [ "ssh-rsa AAAAB3Nbogus carol@laptop" ] },
+    { "user": "alice" }
+  ],
+  "groups": [ { "group": "admin" } ],
+  "teams": [
+    { "team": "a", "users": [ "alice", "team:b" ] },
+    { "team": "b", "users": [ "bob", "team:c" ] },
+    { "team": "c", "users": [ "dave", "team:a", "team:d" ] }
+  ]
+}`
+	dir, err := ioutil.TempDir("", "lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "users.json")
+	if err := ioutil.WriteFile(name, []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := Lint(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"user bob references undefined group ops",
+		"user bob ssh key SHA256:",
+		"user carol ssh key 1 is malformed",
+		"user alice is defined more than once",
+		"is shared by users alice, bob",
+		"team c references undefined user dave",
+		"team c references undefined team d",
+		"teams are nested in a circle: a -> b -> c -> a",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, e := range expected {
+		if !strings.Contains(problems[i], e) {
+			t.Errorf("expected problem %q, got %q", e, problems[i])
+		}
+	}
+
+	if err := ioutil.WriteFile(name, []byte(`{ "users": [ { "user": "alice", "sshkeys": [ "`+good+`" ] } ] }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if problems, err := Lint(name); err != nil || len(problems) != 0 {
+		t.Errorf("expected no problems, got %v, %v", problems, err)
+	}
+}
@@ -0,0 +1,264 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Host is the account state read from an instance by the read_accounts script.
+type Host struct {
+	Name string
+
+	// Accounts are the login accounts of the host, indexed by name.
+	Accounts map[string]*Account
+
+	// Sudoers are the sudoers.d files granting sudo, indexed by user.
+	Sudoers map[string][]string
+
+	// Keys are the authorized keys, indexed by user.
+	Keys map[string][]string
+}
+
+// Account is a login account of a host.
+type Account struct {
+	Name  string
+	Uid   int
+	Home  string
+	Shell string
+}
+
+// LiveFinding is a difference between the accounts of a host and the users
+// of the teams of its pod.
+type LiveFinding struct {
+	Kind   string
+	User   string
+	Detail string
+}
+
+// The kinds of live findings.
+const (
+	RogueAccount = "rogue account"
+	MissingUser  = "missing user"
+	StaleKey     = "stale key"
+	WrongSudo    = "wrong sudo"
+)
+
+// ParseHost parses the output of the read_accounts script run on the named host.
+func ParseHost(name string, output []byte) (*Host, error) {
+	h := &Host{
+		Name:     name,
+		Accounts: map[string]*Account{},
+		Sudoers:  map[string][]string{},
+		Keys:     map[string][]string{},
+	}
+	section, arg := "", ""
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "### ") {
+			f := strings.Fields(strings.TrimPrefix(line, "### "))
+			section, arg = f[0], ""
+			if len(f) > 1 {
+				arg = f[1]
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch section {
+		case "passwd":
+			f := strings.Split(line, ":")
+			if len(f) != 7 {
+				return nil, fmt.Errorf("Malformed passwd entry on %s: %q", name, line)
+			}
+			uid, err := strconv.Atoi(f[2])
+			if err != nil {
+				return nil, fmt.Errorf("Malformed passwd entry on %s: %q", name, line)
+			}
+			h.Accounts[f[0]] = &Account{Name: f[0], Uid: uid, Home: f[5], Shell: f[6]}
+		case "sudoers":
+			// Only user grants are checked, group grants start with %.
+			f := strings.Fields(line)
+			if strings.HasPrefix(f[0], "%") || strings.HasPrefix(f[0], "Defaults") || strings.Contains(f[0], "_Alias") {
+				continue
+			}
+			h.Sudoers[f[0]] = append(h.Sudoers[f[0]], arg)
+		case "authorized_keys":
+			h.Keys[arg] = append(h.Keys[arg], line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(h.Accounts) == 0 {
+		return nil, fmt.Errorf("No accounts read from %s", name)
+	}
+	return h, nil
+}
+
+// login returns true if the account belongs to a person rather than the system.
+func (a *Account) login() bool {
+	if a.Uid < 1000 || a.Uid == 65534 {
+		return false
+	}
+	return !strings.HasSuffix(a.Shell, "nologin") && !strings.HasSuffix(a.Shell, "false")
+}
+
+// expected returns the users defined by the teams or granted access, and
+// whether a team or grant gives them sudo. Removed users aren't expected on
+// the hosts.
+func expected(teams []string, grants []*Grant) (map[string]*User, map[string]bool, error) {
+	users, sudo := map[string]*User{}, map[string]bool{}
+	for _, name := range teams {
+		t := Teams[name]
+		if t == nil {
+			return nil, nil, usersError{"Team " + name + " is not defined."}
+		}
+		for _, u := range t.Users {
+			if u.Remove {
+				continue
+			}
+			users[u.Name] = u
+			if t.Sudo {
+				sudo[u.Name] = true
+			}
+		}
+	}
+	for _, g := range grants {
+		u := Users[g.User]
+		if u == nil || u.Remove {
+			continue
+		}
+		users[u.Name] = u
+		if g.Sudo {
+			sudo[u.Name] = true
+		}
+	}
+	return users, sudo, nil
+}
+
+// AuditHost compares the accounts of the host with the users of the given
+// teams and the users with an active grant to the host's pod. The root user
+// of the host's image isn't a team member, so it is skipped.
+func AuditHost(teams []string, grants []*Grant, rootUser string, h *Host) ([]*LiveFinding, error) {
+	users, sudo, err := expected(teams, grants)
+	if err != nil {
+		return nil, err
+	}
+	findings := []*LiveFinding{}
+	add := func(kind, user, format string, a ...interface{}) {
+		findings = append(findings, &LiveFinding{Kind: kind, User: user, Detail: fmt.Sprintf(format, a...)})
+	}
+
+	for _, name := range sortedAccounts(h.Accounts) {
+		a := h.Accounts[name]
+		if !a.login() || name == rootUser || users[name] != nil {
+			continue
+		}
+		if u := Users[name]; u != nil && u.Remove {
+			add(RogueAccount, name, "removed user %s still has an account on %s", name, h.Name)
+			continue
+		}
+		add(RogueAccount, name, "account %s on %s isn't in the teams or grants of the pod", name, h.Name)
+	}
+
+	names := []string{}
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if h.Accounts[name] == nil {
+			add(MissingUser, name, "user %s has no account on %s", name, h.Name)
+			continue
+		}
+		for _, k := range staleKeys(users[name].SshKeys.Active(time.Now()), h.Keys[name]) {
+			add(StaleKey, name, "user %s on %s has a key that isn't in users.json: %s", name, h.Name, k)
+		}
+		if sudo[name] && len(h.Sudoers[name]) == 0 {
+			add(WrongSudo, name, "user %s has no sudo grant on %s", name, h.Name)
+		}
+	}
+
+	for _, name := range sortedGrants(h.Sudoers) {
+		if name == rootUser || name == "root" || sudo[name] {
+			continue
+		}
+		add(WrongSudo, name, "user %s is granted sudo on %s by %s", name, h.Name, strings.Join(h.Sudoers[name], ", "))
+	}
+	return findings, nil
+}
+
+// staleKeys returns the authorized keys of a host that aren't configured keys.
+func staleKeys(configured, authorized []string) []string {
+	known := map[string]bool{}
+	for _, k := range configured {
+		if pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k)); err == nil {
+			known[string(pub.Marshal())] = true
+		}
+	}
+	stale := []string{}
+	for _, k := range authorized {
+		pub, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(k))
+		if err != nil {
+			stale = append(stale, "unreadable key")
+			continue
+		}
+		if !known[string(pub.Marshal())] {
+			stale = append(stale, strings.TrimSpace(ssh.FingerprintSHA256(pub)+" "+comment))
+		}
+	}
+	return stale
+}
+
+func sortedAccounts(m map[string]*Account) []string {
+	l := []string{}
+	for k := range m {
+		l = append(l, k)
+	}
+	sort.Strings(l)
+	return l
+}
+
+func sortedGrants(m map[string][]string) []string {
+	l := []string{}
+	for k := range m {
+		l = append(l, k)
+	}
+	sort.Strings(l)
+	return l
+}
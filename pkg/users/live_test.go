@@ -0,0 +1,152 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newKey(t *testing.T, comment string) string {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(&k.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))) + " " + comment
+}
+
+func TestAuditHost(t *testing.T) {
+	aliceKey, bobKey, oldKey := newKey(t, "alice@laptop"), newKey(t, "bob@laptop"), newKey(t, "alice@old")
+	Users = map[string]*User{
+		"alice": {userConfig{Name: "alice", SshKeys: SshKeys{{Key: aliceKey}}}},
+		"bob":   {userConfig{Name: "bob", SshKeys: SshKeys{{Key: bobKey}}}},
+		"carol": {userConfig{Name: "carol"}},
+		"dave":  {userConfig{Name: "dave", Remove: true}},
+	}
+	Teams = map[string]*Team{
+		"ops": {Name: "ops", Sudo: true, Users: []*User{Users["alice"], Users["dave"]}},
+		"dev": {Name: "dev", Users: []*User{Users["bob"], Users["carol"]}},
+	}
+	defer func() { Users, Teams = nil, nil }()
+
+	output := `### passwd
+root:x:0:0:root:/root:/bin/bash
+sshd:x:74:74:Privilege-separated SSH:/var/empty/sshd:/sbin/nologin
+centos:x:1000:1000:Cloud User:/home/centos:/bin/bash
+alice:x:1001:1001::/home/alice:/bin/bash
+bob:x:1002:1002::/home/bob:/bin/bash
+dave:x:1003:1003::/home/dave:/bin/bash
+mallory:x:1004:1004::/home/mallory:/bin/bash
+nfsnobody:x:65534:65534:Anonymous NFS User:/var/lib/nfs:/sbin/nologin
+### group
+wheel:x:10:centos
+### sudoers /etc/sudoers.d/90-cloud-init-users
+# Created by cloud-init
+centos ALL=(ALL) NOPASSWD:ALL
+### sudoers /etc/sudoers.d/bob
+bob ALL=(ALL)    NOPASSWD: ALL
+### authorized_keys alice
+` + aliceKey + `
+` + oldKey + `
+### authorized_keys bob
+` + bobKey + `
+`
+	h, err := ParseHost("web-01", []byte(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Accounts) != 8 || len(h.Sudoers["bob"]) != 1 || len(h.Keys["alice"]) != 2 {
+		t.Fatalf("unexpected host %+v", h)
+	}
+
+	findings, err := AuditHost([]string{"ops", "dev"}, nil, "centos", h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, f := range findings {
+		got = append(got, f.Kind+" "+f.User)
+	}
+	expected := []string{
+		"rogue account dave",
+		"rogue account mallory",
+		"stale key alice",
+		"wrong sudo alice",
+		"missing user carol",
+		"wrong sudo bob",
+	}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected findings %v, got %v", expected, got)
+	}
+	if !strings.Contains(findings[2].Detail, "alice@old") {
+		t.Errorf("stale key isn't identified, got %q", findings[2].Detail)
+	}
+
+	// Active grants to the pod are expected, expired ones aren't.
+	Users["mallory"] = &User{userConfig{Name: "mallory"}}
+	now := time.Now()
+	grants := &Grants{Grants: []*Grant{
+		{User: "mallory", Pod: "web", Expires: now.Add(time.Hour)},
+		{User: "bob", Pod: "web", Sudo: true, Expires: now.Add(time.Hour)},
+		{User: "carol", Pod: "web", Sudo: true, Expires: now.Add(-time.Hour)},
+		{User: "dave", Pod: "db", Expires: now.Add(time.Hour)},
+	}}
+	findings, err = AuditHost([]string{"ops", "dev"}, grants.ActiveFor("web", now), "centos", h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = []string{}
+	for _, f := range findings {
+		got = append(got, f.Kind+" "+f.User)
+	}
+	expected = []string{
+		"rogue account dave",
+		"stale key alice",
+		"wrong sudo alice",
+		"missing user carol",
+	}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected findings with grants %v, got %v", expected, got)
+	}
+
+	if _, err := AuditHost([]string{"qa"}, nil, "centos", h); err == nil {
+		t.Error("expected an undefined team to fail")
+	}
+	if _, err := ParseHost("web-02", []byte("### passwd\nroot:x:0\n")); err == nil {
+		t.Error("expected a malformed passwd entry to fail")
+	}
+}
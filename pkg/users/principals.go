@@ -0,0 +1,66 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"sort"
+	"time"
+)
+
+// TeamPrincipal returns the ssh certificate principal of the named team.
+func TeamPrincipal(team string) string {
+	return "team:" + team
+}
+
+// GrantPrincipal returns the ssh certificate principal of a grant to the
+// named pod.
+func GrantPrincipal(pod string) string {
+	return "grant:" + pod
+}
+
+// Principals returns the ssh certificate principals of the named user at
+// time t: the teams the user belongs to and the pods the user has an active
+// grant to.
+func Principals(name string, grants *Grants, t time.Time) []string {
+	principals := []string{}
+	for _, team := range TeamsOf(name) {
+		principals = append(principals, TeamPrincipal(team))
+	}
+	if grants != nil {
+		pods := []string{}
+		for _, g := range grants.Grants {
+			if g.User == name && !g.Expired(t) {
+				pods = append(pods, g.Pod)
+			}
+		}
+		sort.Strings(pods)
+		for _, pod := range pods {
+			principals = append(principals, GrantPrincipal(pod))
+		}
+	}
+	return principals
+}
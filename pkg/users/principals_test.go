@@ -0,0 +1,62 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrincipals(t *testing.T) {
+	Users = map[string]*User{
+		"alice": {userConfig{Name: "alice"}},
+		"bob":   {userConfig{Name: "bob"}},
+	}
+	Teams = map[string]*Team{
+		"ops": {Name: "ops", Users: []*User{Users["alice"]}},
+		"dev": {Name: "dev", Users: []*User{Users["alice"], Users["bob"]}},
+	}
+	defer func() { Users, Teams = nil, nil }()
+
+	now := time.Now()
+	grants := &Grants{Grants: []*Grant{
+		{User: "bob", Pod: "web", Expires: now.Add(time.Hour)},
+		{User: "bob", Pod: "db", Expires: now.Add(time.Hour)},
+		{User: "bob", Pod: "cache", Expires: now.Add(-time.Hour)},
+	}}
+
+	if p := strings.Join(Principals("alice", grants, now), ","); p != "team:dev,team:ops" {
+		t.Errorf("Expected alice's team principals, got %q", p)
+	}
+	if p := strings.Join(Principals("bob", grants, now), ","); p != "team:dev,grant:db,grant:web" {
+		t.Errorf("Expected bob's team and active grant principals, got %q", p)
+	}
+	if p := Principals("carol", nil, now); len(p) != 0 {
+		t.Errorf("Expected no principals for carol, got %v", p)
+	}
+}
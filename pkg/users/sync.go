@@ -0,0 +1,277 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Proposal is the users.json proposed by a sync, with the changes it makes to
+// the current users.json and the warnings raised while syncing.
+type Proposal struct {
+	Data     []byte
+	Changes  []string
+	Warnings []string
+}
+
+// SyncLDAP proposes the named users.json updated from the LDAP directory.
+// LDAP users that aren't in users.json are added, users that are no longer in
+// LDAP are marked removed and the users' ssh keys are replaced by the LDAP
+// keys. The members of the synced teams are replaced by the LDAP group
+// members, keeping the team's sudo setting and nested teams. The groups of
+// the users and the teams that aren't in LDAP are left as they are.
+func SyncLDAP(name string, cfg *LDAPConfig, d Directory) (*Proposal, error) {
+	file, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	proposed := &config{}
+	if err := json.Unmarshal(file, proposed); err != nil {
+		return nil, usersError{name + ": " + err.Error()}
+	}
+	ldapUsers, err := searchUsers(cfg, d)
+	if err != nil {
+		return nil, err
+	}
+	ldapTeams, err := searchTeams(cfg, d)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Proposal{}
+	change := func(format string, args ...interface{}) {
+		p.Changes = append(p.Changes, fmt.Sprintf(format, args...))
+	}
+	warn := func(format string, args ...interface{}) {
+		p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	// Users
+	known := map[string]bool{}
+	for i := range proposed.UsersConfig {
+		u := &proposed.UsersConfig[i]
+		known[u.Name] = true
+		keys, ok := ldapUsers[u.Name]
+		if !ok {
+			if !u.Remove {
+				u.Remove = true
+				change("- user %s, not in LDAP, marked removed", u.Name)
+			}
+			continue
+		}
+		if u.Remove {
+			u.Remove = false
+			change("+ user %s, back in LDAP, no longer removed", u.Name)
+		}
+		added, removed := diffStrings(u.SshKeys.Strings(), keys)
+		for _, k := range added {
+			change("~ user %s, key added %s", u.Name, keyName(k))
+		}
+		for _, k := range removed {
+			change("~ user %s, key removed %s", u.Name, keyName(k))
+		}
+		u.SshKeys = syncKeys(u.SshKeys, keys)
+	}
+	for _, n := range sortedKeys(ldapUsers) {
+		if known[n] {
+			continue
+		}
+		if len(ldapUsers[n]) == 0 {
+			warn("user %s has no ssh keys in LDAP", n)
+		}
+		proposed.UsersConfig = append(proposed.UsersConfig, userConfig{Name: n, Groups: []string{}, SshKeys: syncKeys(nil, ldapUsers[n])})
+		change("+ user %s", n)
+	}
+
+	// Teams
+	synced := map[string]bool{}
+	for _, n := range sortedKeys(ldapTeams) {
+		synced[n] = true
+		members := []string{}
+		for _, m := range ldapTeams[n] {
+			if _, ok := ldapUsers[m]; !ok {
+				warn("team %s member %s isn't an LDAP user, skipped", n, m)
+				continue
+			}
+			members = append(members, m)
+		}
+
+		var t *teamConfig
+		for i := range proposed.TeamsConfig {
+			if proposed.TeamsConfig[i].Name == n {
+				t = &proposed.TeamsConfig[i]
+			}
+		}
+		if t == nil {
+			proposed.TeamsConfig = append(proposed.TeamsConfig, teamConfig{Name: n, Users: members})
+			change("+ team %s, users %s", n, strings.Join(members, ", "))
+			continue
+		}
+		current, nested := []string{}, []string{}
+		for _, m := range t.Users {
+			if strings.HasPrefix(m, "team:") {
+				nested = append(nested, m)
+			} else {
+				current = append(current, m)
+			}
+		}
+		added, removed := diffStrings(current, members)
+		if len(added) > 0 {
+			change("~ team %s, users added %s", n, strings.Join(added, ", "))
+		}
+		if len(removed) > 0 {
+			change("~ team %s, users removed %s", n, strings.Join(removed, ", "))
+		}
+		t.Users = append(members, nested...)
+	}
+	for _, t := range proposed.TeamsConfig {
+		if !synced[t.Name] {
+			warn("team %s isn't in LDAP, left unchanged", t.Name)
+		}
+	}
+
+	b, err := json.MarshalIndent(proposed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	p.Data = append(b, '\n')
+	return p, nil
+}
+
+// syncKeys returns the LDAP keys, keeping the dates of the current keys.
+func syncKeys(current SshKeys, keys []string) SshKeys {
+	synced := SshKeys{}
+	for _, k := range keys {
+		key := SshKey{Key: k}
+		for _, c := range current {
+			if c.Key == k {
+				key = c
+			}
+		}
+		synced = append(synced, key)
+	}
+	return synced
+}
+
+// searchUsers returns the ssh keys of the LDAP users, indexed by user name.
+func searchUsers(cfg *LDAPConfig, d Directory) (map[string][]string, error) {
+	entries, err := d.Search(cfg.UserBase, cfg.UserFilter, cfg.UserAttr, cfg.KeyAttr)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string][]string{}
+	for _, e := range entries {
+		names := e.Get(cfg.UserAttr)
+		if len(names) == 0 {
+			continue
+		}
+		keys := []string{}
+		for _, k := range e.Get(cfg.KeyAttr) {
+			keys = append(keys, strings.TrimSpace(k))
+		}
+		m[names[0]] = keys
+	}
+	return m, nil
+}
+
+// searchTeams returns the sorted members of the LDAP groups synced to teams,
+// indexed by team name.
+func searchTeams(cfg *LDAPConfig, d Directory) (map[string][]string, error) {
+	entries, err := d.Search(cfg.GroupBase, cfg.GroupFilter, cfg.GroupAttr, cfg.MemberAttr)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string][]string{}
+	for _, e := range entries {
+		names := e.Get(cfg.GroupAttr)
+		if len(names) == 0 {
+			continue
+		}
+		team := names[0]
+		if len(cfg.Teams) > 0 {
+			if team = cfg.Teams[names[0]]; team == "" {
+				continue
+			}
+		}
+		members := []string{}
+		for _, v := range e.Get(cfg.MemberAttr) {
+			members = append(members, memberName(v))
+		}
+		sort.Strings(members)
+		m[team] = members
+	}
+	return m, nil
+}
+
+// memberName returns the user name of a group member, which is either a
+// name as in memberUid or the DN of the user as in member.
+func memberName(v string) string {
+	if i := strings.Index(v, "="); i > 0 && strings.Contains(v, ",") {
+		return v[i+1 : strings.Index(v, ",")]
+	}
+	return v
+}
+
+// keyName identifies an ssh key by its fingerprint and comment.
+func keyName(k string) string {
+	pub, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(k))
+	if err != nil {
+		return "unreadable key"
+	}
+	return strings.TrimSpace(ssh.FingerprintSHA256(pub) + " " + comment)
+}
+
+// diffStrings returns the strings of b that aren't in a and the strings of a
+// that aren't in b.
+func diffStrings(a, b []string) (added, removed []string) {
+	for _, s := range b {
+		if !contains(a, s) {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if !contains(b, s) {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,222 @@
+//
+// Copyright (c) 2018, Cisco Systems
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without modification,
+// are permitted provided that the following conditions are met:
+//
+// * Redistributions of source code must retain the above copyright notice, this
+//   list of conditions and the following disclaimer.
+//
+// * Redistributions in binary form must reproduce the above copyright notice, this
+//   list of conditions and the following disclaimer in the documentation and/or
+//   other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+// ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+// WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+// DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+// ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+// (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON
+// ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+// SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package users
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLDAPFilter(t *testing.T) {
+	e := &Entry{DN: "uid=alice,ou=people,dc=example,dc=com", Attrs: map[string][]string{
+		"objectclass": {"top", "posixAccount"},
+		"uid":         {"alice"},
+		"mail":        {"alice@example.com"},
+	}}
+	tests := []struct {
+		filter string
+		match  bool
+	}{
+		{"(objectClass=posixAccount)", true},
+		{"(objectclass=POSIXACCOUNT)", true},
+		{"(objectClass=posixGroup)", false},
+		{"(mail=*)", true},
+		{"(mail=*@example.com)", true},
+		{"(mail=a*e@*.com)", true},
+		{"(mail=bob*)", false},
+		{"(&(objectClass=posixAccount)(uid=alice))", true},
+		{"(&(objectClass=posixAccount)(uid=bob))", false},
+		{"(|(uid=bob)(uid=alice))", true},
+		{"(!(uid=alice))", false},
+	}
+	for _, test := range tests {
+		f, err := parseFilter(test.filter)
+		if err != nil {
+			t.Errorf("%s: %s", test.filter, err)
+			continue
+		}
+		if f.match(e) != test.match {
+			t.Errorf("%s: expected match %t", test.filter, test.match)
+		}
+	}
+	for _, bad := range []string{"", "uid=alice", "(uid)", "(&)", "(!(uid=a)(uid=b))", "(uid=alice)x"} {
+		if _, err := parseFilter(bad); err == nil {
+			t.Errorf("%q: expected a malformed filter", bad)
+		}
+	}
+}
+
+func TestSyncLDAP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ldap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	aliceKey, aliceNewKey, bobKey, carolKey := newKey(t, "alice@laptop"), newKey(t, "alice@new"), newKey(t, "bob@laptop"), newKey(t, "carol@laptop")
+	write := func(name, data string) string {
+		name = filepath.Join(dir, name)
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+
+	usersFile := write("users.json", `{
+  "comments": [ "Synced from LDAP" ],
+  "datacenters": [],
+  "users": [
+    { "user": "alice", "groups": [ "admin" ], "sshkeys": [ "`+aliceKey+`" ] },
+    { "user": "bob", "groups": [ "admin" ], "sshkeys": [ "`+bobKey+`" ] },
+    { "user": "dave", "groups": [], "sshkeys": [] }
+  ],
+  "groups": [ { "group": "admin" } ],
+  "teams": [
+    { "team": "ops", "sudo": true, "users": [ "alice", "dave", "team:dev" ] },
+    { "team": "dev", "users": [ "bob" ] },
+    { "team": "local", "users": [ "alice" ] }
+  ]
+}`)
+	ldapFile := write("ldap.json", `{
+  "url": "ldaps://ldap.example.com",
+  "user_base": "ou=people,dc=example,dc=com",
+  "group_base": "ou=groups,dc=example,dc=com",
+  "teams": { "operations": "ops", "developers": "dev", "qa": "qa" }
+}`)
+	ldif := write("directory.ldif", `# A stand-in for the LDAP server
+version: 1
+
+dn: uid=alice,ou=people,dc=example,dc=com
+objectClass: posixAccount
+uid: alice
+sshPublicKey: `+aliceKey+`
+sshPublicKey: `+aliceNewKey+`
+
+dn: uid=bob,ou=people,dc=example,dc=com
+objectClass: posixAccount
+uid: bob
+sshPublicKey: `+bobKey+`
+
+dn: uid=carol,ou=people,dc=example,dc=com
+objectClass: posixAccount
+uid: carol
+sshPublicKey: `+carolKey[:30]+`
+ `+carolKey[30:]+`
+
+dn: uid=svc,ou=services,dc=example,dc=com
+objectClass: posixAccount
+uid: svc
+
+dn: cn=operations,ou=groups,dc=example,dc=com
+objectClass: posixGroup
+cn: operations
+memberUid: alice
+memberUid: carol
+
+dn: cn=developers,ou=groups,dc=example,dc=com
+objectClass: posixGroup
+cn: developers
+memberUid: bob
+memberUid: svc
+
+dn: cn=qa,ou=groups,dc=example,dc=com
+objectClass: posixGroup
+cn: qa
+memberUid: carol
+
+dn: cn=other,ou=groups,dc=example,dc=com
+objectClass: posixGroup
+cn: other
+memberUid: alice
+`)
+
+	cfg, err := LoadLDAPConfig(ldapFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := newLDIFDirectory(ldif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := SyncLDAP(usersFile, cfg, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"~ user alice, key added SHA256:",
+		"- user dave, not in LDAP, marked removed",
+		"+ user carol",
+		"~ team ops, users added carol",
+		"~ team ops, users removed dave",
+		"+ team qa, users carol",
+	}
+	if len(p.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %v", len(expected), len(p.Changes), p.Changes)
+	}
+	for i, e := range expected {
+		if !strings.HasPrefix(p.Changes[i], e) {
+			t.Errorf("expected change %q, got %q", e, p.Changes[i])
+		}
+	}
+	warnings := strings.Join(p.Warnings, "\n")
+	if !strings.Contains(warnings, "team dev member svc isn't an LDAP user") || !strings.Contains(warnings, "team local isn't in LDAP") {
+		t.Errorf("unexpected warnings %v", p.Warnings)
+	}
+
+	proposed := &config{}
+	if err := json.Unmarshal(p.Data, proposed); err != nil {
+		t.Fatal(err)
+	}
+	if len(proposed.Comments) != 1 || len(proposed.UsersConfig) != 4 {
+		t.Fatalf("unexpected proposal %s", p.Data)
+	}
+	alice, carol := proposed.UsersConfig[0], proposed.UsersConfig[3]
+	if len(alice.SshKeys) != 2 || alice.Groups[0] != "admin" {
+		t.Errorf("expected alice's keys to be synced and groups kept, got %+v", alice)
+	}
+	if carol.Name != "carol" || len(carol.SshKeys) != 1 || carol.SshKeys[0].Key != carolKey {
+		t.Errorf("expected carol's folded key to be read, got %+v", carol)
+	}
+	ops := proposed.TeamsConfig[0]
+	if !ops.Sudo || strings.Join(ops.Users, ",") != "alice,carol,team:dev" {
+		t.Errorf("expected ops to keep sudo and nested teams, got %+v", ops)
+	}
+
+	// Syncing the proposal again changes nothing.
+	write("users.json", string(p.Data))
+	if p, err = SyncLDAP(usersFile, cfg, d); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Changes) != 0 {
+		t.Errorf("expected no changes, got %v", p.Changes)
+	}
+}
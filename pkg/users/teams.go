@@ -27,6 +27,7 @@
 package users
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -75,6 +76,19 @@ func newTeams(cfg teamsConfig) (map[string]*Team, error) {
 	return m, nil
 }
 
+// TeamsOf returns the sorted names of the teams the named user belongs to,
+// including the teams the user belongs to through nested teams.
+func TeamsOf(name string) []string {
+	teams := []string{}
+	for _, t := range Teams {
+		if t.FindUser(name) != nil {
+			teams = append(teams, t.Name)
+		}
+	}
+	sort.Strings(teams)
+	return teams
+}
+
 func (t *Team) FindUser(name string) *User {
 	for _, u := range t.Users {
 		if u.Name == name {
@@ -26,6 +26,13 @@
 
 package users
 
+import (
+	"time"
+
+	"github.com/cisco/arc/pkg/aaa"
+	"github.com/cisco/arc/pkg/log"
+)
+
 type User struct {
 	userConfig
 }
@@ -37,3 +44,26 @@ func newUsers(cfg usersConfig) map[string]*User {
 	}
 	return m
 }
+
+// Authenticate authenticates the operator as the named user with the ssh keys
+// listed for the user. Authentication is skipped if no users are configured.
+func Authenticate(name string) error {
+	if Users == nil {
+		log.Warn("No users configured, %s is not authenticated", name)
+		return nil
+	}
+	u := Users[name]
+	if u == nil || u.Remove {
+		return usersError{"User " + name + " is not defined."}
+	}
+	return aaa.Authenticate(name, u.SshKeys.Active(time.Now()))
+}
+
+// KeysOf returns the ssh keys listed for the named user that haven't expired.
+func KeysOf(name string) []string {
+	u := Users[name]
+	if u == nil || u.Remove {
+		return nil
+	}
+	return u.SshKeys.Active(time.Now())
+}